@@ -0,0 +1,89 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/spf13/pflag"
+	"github.com/wangn-tech/bookstore-go/internal/app/container"
+	"github.com/wangn-tech/bookstore-go/internal/catalog"
+)
+
+var catalogFormat = pflag.String("format", "", "Catalog file format: csv, jsonl or onix, inferred from the file extension by default (import-books, export-books)")
+
+var importBooksCmd = &command{
+	name:  "import-books",
+	usage: "Import books from a CSV, JSON Lines or ONIX 3.0 file, upserting by ISBN: import-books <file>",
+	run:   runImportBooks,
+}
+
+var exportBooksCmd = &command{
+	name:  "export-books",
+	usage: "Export all books to a CSV, JSON Lines or ONIX 3.0 file: export-books <file>",
+	run:   runExportBooks,
+}
+
+// catalogFileArg 取出文件参数并确定文件格式
+func catalogFileArg(args []string) (string, string, error) {
+	if len(args) != 1 {
+		return "", "", errors.New("expected exactly one file argument")
+	}
+	format, err := catalog.ParseFormat(*catalogFormat, args[0])
+	if err != nil {
+		return "", "", err
+	}
+	return args[0], format, nil
+}
+
+// runImportBooks 导入目录文件, 输出失败记录和汇总
+func runImportBooks(ctx context.Context, c *container.Container, args []string) error {
+	path, format, err := catalogFileArg(args)
+	if err != nil {
+		return err
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	report, err := c.BookService.ImportBooks(ctx, format, file, *dryRun)
+	if err != nil {
+		return err
+	}
+	for _, rowErr := range report.Errors {
+		fmt.Printf("line %d (isbn %q): %s\n", rowErr.Line, rowErr.ISBN, rowErr.Message)
+	}
+	if omitted := report.Failed - len(report.Errors); omitted > 0 {
+		fmt.Printf("... %d more errors omitted\n", omitted)
+	}
+	action := "imported"
+	if report.DryRun {
+		action = "validated (dry run, nothing written)"
+	}
+	fmt.Printf("%s %d records: %d created, %d updated, %d failed\n", action, report.Total, report.Created, report.Updated, report.Failed)
+	return nil
+}
+
+// runExportBooks 导出全部图书到目录文件
+func runExportBooks(ctx context.Context, c *container.Container, args []string) error {
+	path, format, err := catalogFileArg(args)
+	if err != nil {
+		return err
+	}
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	count, err := c.BookService.ExportBooks(ctx, format, file)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Printf("exported %d books to %s (%s)\n", count, path, format)
+	return nil
+}
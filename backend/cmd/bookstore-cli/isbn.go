@@ -0,0 +1,31 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/wangn-tech/bookstore-go/internal/app/container"
+)
+
+var checkISBNsCmd = &command{
+	name:  "check-isbns",
+	usage: "Normalize book ISBNs, backfill the ISBN-13 column and list invalid or duplicate ISBNs",
+	run:   runCheckISBNs,
+}
+
+// runCheckISBNs 规范化存量图书的 ISBN, 输出无效和重复的 ISBN
+func runCheckISBNs(ctx context.Context, c *container.Container, _ []string) error {
+	report, err := c.BookService.CheckISBNs(ctx, *dryRun)
+	if err != nil {
+		return err
+	}
+	for _, invalid := range report.Invalid {
+		fmt.Printf("book %d (%s): isbn=%q %s\n", invalid.ID, invalid.Title, invalid.ISBN, invalid.Reason)
+	}
+	action := "normalized"
+	if report.DryRun {
+		action = "to normalize (dry run, nothing written)"
+	}
+	fmt.Printf("checked %d books, %d invalid, %d %s\n", report.Checked, len(report.Invalid), report.Normalized, action)
+	return nil
+}
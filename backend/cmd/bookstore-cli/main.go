@@ -0,0 +1,92 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/spf13/pflag"
+	"github.com/wangn-tech/bookstore-go/internal/app/config"
+	"github.com/wangn-tech/bookstore-go/internal/app/container"
+	"github.com/wangn-tech/bookstore-go/internal/app/initializer/database"
+	"github.com/wangn-tech/bookstore-go/internal/app/initializer/redis"
+	"github.com/wangn-tech/bookstore-go/pkg/logger"
+)
+
+// command 运维子命令
+type command struct {
+	name  string
+	usage string
+	run   func(ctx context.Context, c *container.Container, args []string) error
+}
+
+// commands 全部子命令, 各命令的参数需在 config.Init 解析命令行之前注册到 pflag
+var commands = []*command{
+	reconcileCategoryCountsCmd,
+	reindexSearchCmd,
+	backfillSearchKeysCmd,
+	rebuildSuggestCmd,
+	rebuildSimilaritiesCmd,
+	rebuildRankingsCmd,
+	importBooksCmd,
+	exportBooksCmd,
+	checkISBNsCmd,
+	grantRoleCmd,
+}
+
+// dryRun 只检查不写入, 由支持试运行的子命令共用
+var dryRun = pflag.Bool("dry-run", false, "Only report, do not write anything (reconcile-category-counts, import-books, check-isbns)")
+
+// 用法: bookstore-cli [--env dev] <command> [flags]
+func main() {
+	pflag.Usage = usage
+	// 配置文件初始化 ./config/config-{env}.yaml, 同时解析命令行参数
+	config.Init()
+
+	args := pflag.Args()
+	if len(args) == 0 {
+		usage()
+		os.Exit(2)
+	}
+	cmd := findCommand(args[0])
+	if cmd == nil {
+		fmt.Fprintf(os.Stderr, "unknown command: %s\n", args[0])
+		usage()
+		os.Exit(2)
+	}
+
+	// 初始化 Logger
+	logger.InitLogger()
+	// 初始化数据库
+	database.InitDB()
+	// 初始化 Redis
+	redis.InitRedis()
+	// 构造依赖容器
+	c := container.NewContainer(database.DB)
+	defer c.SearchEngine.Close()
+
+	if err := cmd.run(context.Background(), c, args[1:]); err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %v\n", cmd.name, err)
+		c.SearchEngine.Close()
+		os.Exit(1)
+	}
+}
+
+func findCommand(name string) *command {
+	for _, cmd := range commands {
+		if cmd.name == name {
+			return cmd
+		}
+	}
+	return nil
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "Usage: bookstore-cli [--env dev] <command> [flags]")
+	fmt.Fprintln(os.Stderr, "\nCommands:")
+	for _, cmd := range commands {
+		fmt.Fprintf(os.Stderr, "  %-28s %s\n", cmd.name, cmd.usage)
+	}
+	fmt.Fprintln(os.Stderr, "\nFlags:")
+	pflag.PrintDefaults()
+}
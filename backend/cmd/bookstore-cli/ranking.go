@@ -0,0 +1,24 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/wangn-tech/bookstore-go/internal/app/container"
+)
+
+var rebuildRankingsCmd = &command{
+	name:  "rebuild-rankings",
+	usage: "Rebuild the daily bestseller rankings in Redis from paid orders of the last 31 days",
+	run:   runRebuildRankings,
+}
+
+// runRebuildRankings 根据近 31 天的已支付订单重建畅销榜日榜
+func runRebuildRankings(ctx context.Context, c *container.Container, _ []string) error {
+	count, err := c.RankingService.RebuildRankings(ctx)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("rebuilt bestseller rankings from %d daily book sales\n", count)
+	return nil
+}
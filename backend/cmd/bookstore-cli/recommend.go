@@ -0,0 +1,24 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/wangn-tech/bookstore-go/internal/app/container"
+)
+
+var rebuildSimilaritiesCmd = &command{
+	name:  "rebuild-similarities",
+	usage: "Rebuild the \"customers also bought\" book similarity table from paid orders",
+	run:   runRebuildSimilarities,
+}
+
+// runRebuildSimilarities 根据已支付订单全量重建图书共同购买相似度
+func runRebuildSimilarities(ctx context.Context, c *container.Container, _ []string) error {
+	count, err := c.RecommendService.RebuildSimilarities(ctx)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("wrote %d book similarities\n", count)
+	return nil
+}
@@ -0,0 +1,31 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/wangn-tech/bookstore-go/internal/app/container"
+)
+
+var reconcileCategoryCountsCmd = &command{
+	name:  "reconcile-category-counts",
+	usage: "Recompute Category.BookCount from on-shelf books and report drift",
+	run:   runReconcileCategoryCounts,
+}
+
+// runReconcileCategoryCounts 重新统计各分类的上架图书数量, 输出存在偏差的分类
+func runReconcileCategoryCounts(ctx context.Context, c *container.Container, _ []string) error {
+	report, err := c.CategoryService.ReconcileBookCounts(ctx, *dryRun)
+	if err != nil {
+		return err
+	}
+	for _, drift := range report.Drifts {
+		fmt.Printf("category %d (%s): stored=%d actual=%d\n", drift.ID, drift.Name, drift.Stored, drift.Actual)
+	}
+	action := "fixed"
+	if report.DryRun {
+		action = "found (dry run, nothing written)"
+	}
+	fmt.Printf("checked %d categories, %d drifted, %s\n", report.Checked, len(report.Drifts), action)
+	return nil
+}
@@ -0,0 +1,28 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/wangn-tech/bookstore-go/internal/app/container"
+)
+
+var grantRoleCmd = &command{
+	name:  "grant-role",
+	usage: "Grant a role to a user and revoke their sessions, e.g. the first admin: grant-role <username> admin",
+	run:   runGrantRole,
+}
+
+// runGrantRole 为用户追加角色, 用户需重新登录后生效
+func runGrantRole(ctx context.Context, c *container.Container, args []string) error {
+	if len(args) != 2 {
+		return errors.New("expected <username> <role>")
+	}
+	username, roleName := args[0], args[1]
+	if err := c.RoleService.GrantRole(ctx, username, roleName); err != nil {
+		return err
+	}
+	fmt.Printf("granted %s to %s, existing sessions revoked, log in again to use it\n", roleName, username)
+	return nil
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/wangn-tech/bookstore-go/internal/app/container"
+)
+
+var reindexSearchCmd = &command{
+	name:  "reindex-search",
+	usage: "Rebuild the book search index from the database",
+	run:   runReindexSearch,
+}
+
+// runReindexSearch 从数据库全量重建检索索引, MySQL 引擎下为空操作
+func runReindexSearch(ctx context.Context, c *container.Container, _ []string) error {
+	count, err := c.BookService.RebuildSearchIndex(ctx)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("reindexed %d books\n", count)
+	return nil
+}
+
+var backfillSearchKeysCmd = &command{
+	name:  "backfill-search-keys",
+	usage: "Regenerate pinyin and simplified Chinese search keys for all books",
+	run:   runBackfillSearchKeys,
+}
+
+// runBackfillSearchKeys 为存量图书生成检索键, 并同步写入检索索引
+func runBackfillSearchKeys(ctx context.Context, c *container.Container, _ []string) error {
+	count, err := c.BookService.BackfillSearchKeys(ctx)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("updated search keys of %d books\n", count)
+	return nil
+}
+
+var rebuildSuggestCmd = &command{
+	name:  "rebuild-suggest",
+	usage: "Rebuild the search autocomplete index in Redis from the database",
+	run:   runRebuildSuggest,
+}
+
+// runRebuildSuggest 从数据库全量重建搜索联想索引, 保留热门搜索词
+func runRebuildSuggest(ctx context.Context, c *container.Container, _ []string) error {
+	count, err := c.SuggestService.RebuildIndex(ctx)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("rebuilt suggest index from %d books\n", count)
+	return nil
+}
@@ -1,13 +1,17 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"github.com/gin-gonic/gin"
 	"github.com/wangn-tech/bookstore-go/internal/app/config"
+	"github.com/wangn-tech/bookstore-go/internal/app/container"
 	"github.com/wangn-tech/bookstore-go/internal/app/initializer/database"
 	"github.com/wangn-tech/bookstore-go/internal/app/initializer/redis"
+	"github.com/wangn-tech/bookstore-go/internal/job"
 	"github.com/wangn-tech/bookstore-go/internal/router"
 	"github.com/wangn-tech/bookstore-go/pkg/logger"
+	"go.uber.org/zap"
 )
 
 func main() {
@@ -21,11 +25,66 @@ func main() {
 	// 初始化 Redis
 	redis.InitRedis()
 
+	// 构造依赖容器
+	c := container.NewContainer(database.DB)
+
+	// 启动后台任务: 自动取消超时未支付订单
+	job.NewOrderTimeoutJob(c.OrderService, config.AppConf.Order.PendingTTL, config.AppConf.Order.CancelInterval).
+		Start(context.Background())
+	// 启动后台任务: 定期重建图书共同购买相似度
+	job.NewBookSimilarityJob(c.RecommendService, config.AppConf.Recommend.RebuildInterval).
+		Start(context.Background())
+
+	// 检索索引为新建时从数据库全量构建
+	if c.SearchEngine.NeedsReindex() {
+		go func() {
+			count, err := c.BookService.RebuildSearchIndex(context.Background())
+			if err != nil {
+				logger.Log.Error("构建检索索引失败", zap.Error(err))
+				return
+			}
+			logger.Log.Info(fmt.Sprintf("检索索引构建完成, 共 %d 本图书", count))
+		}()
+	}
+	defer c.SearchEngine.Close()
+
+	// 联想索引不存在时 (首次部署或 Redis 数据丢失) 从数据库全量构建
+	go func() {
+		ctx := context.Background()
+		exists, err := c.SuggestService.IndexExists(ctx)
+		if err != nil {
+			logger.Log.Error("检查联想索引失败", zap.Error(err))
+			return
+		}
+		if exists {
+			return
+		}
+		if _, err := c.SuggestService.RebuildIndex(ctx); err != nil {
+			logger.Log.Error("构建联想索引失败", zap.Error(err))
+		}
+	}()
+
+	// 畅销榜不存在时 (首次部署或 Redis 数据丢失) 从订单重建
+	go func() {
+		ctx := context.Background()
+		exists, err := c.RankingService.RankingExists(ctx)
+		if err != nil {
+			logger.Log.Error("检查畅销榜失败", zap.Error(err))
+			return
+		}
+		if exists {
+			return
+		}
+		if _, err := c.RankingService.RebuildRankings(ctx); err != nil {
+			logger.Log.Error("重建畅销榜失败", zap.Error(err))
+		}
+	}()
+
 	// 初始化 *gin.Engine
 	gin.SetMode(config.AppConf.Server.Mode)
 	r := gin.Default()
 	// 注册路由
-	router.InitRouter(r)
+	router.InitRouter(r, c)
 
 	// 启动服务
 	port := fmt.Sprintf(":%d", config.AppConf.Server.Port)
@@ -0,0 +1,273 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/wangn-tech/bookstore-go/common/result"
+	"github.com/wangn-tech/bookstore-go/internal/api/request"
+	"github.com/wangn-tech/bookstore-go/internal/api/response"
+	"github.com/wangn-tech/bookstore-go/internal/catalog"
+	"github.com/wangn-tech/bookstore-go/internal/service"
+	"github.com/wangn-tech/bookstore-go/pkg/isbn"
+	"github.com/wangn-tech/bookstore-go/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// AdminBookHandler 管理员图书管理
+type AdminBookHandler struct {
+	bookService service.IBookService
+}
+
+// NewAdminBookHandler 构造函数
+func NewAdminBookHandler(bookService service.IBookService) *AdminBookHandler {
+	return &AdminBookHandler{
+		bookService: bookService,
+	}
+}
+
+// GetBookList 获取图书列表（包含下架图书）
+func (a *AdminBookHandler) GetBookList(ctx *gin.Context) {
+	var pageReq request.BooksPageDTO
+	if err := ctx.ShouldBindQuery(&pageReq); err != nil {
+		logger.Log.Warn("AdminGetBookList: 查询参数绑定失败，使用默认值", zap.Error(err))
+		pageReq.Page = 1
+		pageReq.PageSize = 10
+	}
+	result.PageVerify(&pageReq.Page, &pageReq.PageSize)
+
+	pageResult, err := a.bookService.GetBooksByPageForAdmin(ctx.Request.Context(), &pageReq)
+	if err != nil {
+		logger.Log.Error("AdminGetBookList: 获取图书列表失败", zap.Error(err))
+		result.Fail(ctx, http.StatusInternalServerError, "获取图书列表失败")
+		return
+	}
+
+	result.Success(ctx, "获取图书列表成功", &response.BooksPageVO{
+		Books:     pageResult.Records,
+		Total:     pageResult.Total,
+		Page:      pageReq.Page,
+		PageSize:  pageReq.PageSize,
+		TotalPage: (pageResult.Total + int64(pageReq.PageSize) - 1) / int64(pageReq.PageSize),
+	})
+}
+
+// GetBookDetail 获取图书详情（不过滤上下架状态）
+func (a *AdminBookHandler) GetBookDetail(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		logger.Log.Warn("AdminGetBookDetail: 图书ID无效", zap.String("id", ctx.Param("id")))
+		result.Fail(ctx, http.StatusBadRequest, "图书ID无效")
+		return
+	}
+
+	book, err := a.bookService.GetBookByIDForAdmin(ctx.Request.Context(), id)
+	if err != nil {
+		a.failWithBookError(ctx, "AdminGetBookDetail", "获取图书详情失败", id, err)
+		return
+	}
+	result.Success(ctx, "获取图书详情成功", book)
+}
+
+// CreateBook 创建图书
+func (a *AdminBookHandler) CreateBook(ctx *gin.Context) {
+	var req request.AdminBookDTO
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		logger.Log.Warn("CreateBook: 请求参数错误", zap.Error(err))
+		result.Fail(ctx, http.StatusBadRequest, "请求参数错误")
+		return
+	}
+	if _, err := isbn.To13(req.ISBN); err != nil {
+		logger.Log.Warn("CreateBook: ISBN 无效", zap.String("isbn", req.ISBN), zap.Error(err))
+		result.Fail(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	book, err := a.bookService.CreateBook(ctx.Request.Context(), &req)
+	if err != nil {
+		if errors.Is(err, service.ErrISBNExists) {
+			logger.Log.Warn("CreateBook: ISBN 重复", zap.String("isbn", req.ISBN), zap.Error(err))
+			result.Fail(ctx, http.StatusConflict, err.Error())
+			return
+		}
+		logger.Log.Error("CreateBook: 创建图书失败", zap.Error(err))
+		result.Fail(ctx, http.StatusInternalServerError, "创建图书失败")
+		return
+	}
+	result.Success(ctx, "创建图书成功", book)
+}
+
+// UpdateBook 更新图书
+func (a *AdminBookHandler) UpdateBook(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		logger.Log.Warn("UpdateBook: 图书ID无效", zap.String("id", ctx.Param("id")))
+		result.Fail(ctx, http.StatusBadRequest, "图书ID无效")
+		return
+	}
+	var req request.AdminBookDTO
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		logger.Log.Warn("UpdateBook: 请求参数错误", zap.Error(err))
+		result.Fail(ctx, http.StatusBadRequest, "请求参数错误")
+		return
+	}
+	if _, err := isbn.To13(req.ISBN); err != nil {
+		logger.Log.Warn("UpdateBook: ISBN 无效", zap.String("isbn", req.ISBN), zap.Error(err))
+		result.Fail(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	book, err := a.bookService.UpdateBook(ctx.Request.Context(), id, &req)
+	if err != nil {
+		a.failWithBookError(ctx, "UpdateBook", "更新图书失败", id, err)
+		return
+	}
+	result.Success(ctx, "更新图书成功", book)
+}
+
+// UpdateBookStatus 图书上架/下架
+func (a *AdminBookHandler) UpdateBookStatus(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		logger.Log.Warn("UpdateBookStatus: 图书ID无效", zap.String("id", ctx.Param("id")))
+		result.Fail(ctx, http.StatusBadRequest, "图书ID无效")
+		return
+	}
+	var req request.BookStatusDTO
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		logger.Log.Warn("UpdateBookStatus: 请求参数错误", zap.Error(err))
+		result.Fail(ctx, http.StatusBadRequest, "请求参数错误")
+		return
+	}
+
+	book, err := a.bookService.UpdateBookStatus(ctx.Request.Context(), id, *req.Status)
+	if err != nil {
+		a.failWithBookError(ctx, "UpdateBookStatus", "更新图书状态失败", id, err)
+		return
+	}
+	result.Success(ctx, "更新图书状态成功", book)
+}
+
+// DeleteBook 删除图书
+func (a *AdminBookHandler) DeleteBook(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		logger.Log.Warn("DeleteBook: 图书ID无效", zap.String("id", ctx.Param("id")))
+		result.Fail(ctx, http.StatusBadRequest, "图书ID无效")
+		return
+	}
+
+	if err := a.bookService.DeleteBook(ctx.Request.Context(), id); err != nil {
+		a.failWithBookError(ctx, "DeleteBook", "删除图书失败", id, err)
+		return
+	}
+	result.Success(ctx, "删除图书成功", nil)
+}
+
+// importMaxBytes 导入文件的大小上限
+const importMaxBytes = 50 << 20
+
+// ImportBooks 从 CSV、JSON Lines 或 ONIX 文件批量导入图书
+func (a *AdminBookHandler) ImportBooks(ctx *gin.Context) {
+	var req request.BookImportDTO
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		logger.Log.Warn("ImportBooks: 请求参数错误", zap.Error(err))
+		result.Fail(ctx, http.StatusBadRequest, "请求参数错误")
+		return
+	}
+	ctx.Request.Body = http.MaxBytesReader(ctx.Writer, ctx.Request.Body, importMaxBytes)
+	fileHeader, err := ctx.FormFile("file")
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			logger.Log.Warn("ImportBooks: 文件过大", zap.Error(err))
+			result.Fail(ctx, http.StatusRequestEntityTooLarge, "文件不能超过 50MB")
+			return
+		}
+		logger.Log.Warn("ImportBooks: 未上传文件", zap.Error(err))
+		result.Fail(ctx, http.StatusBadRequest, "请上传目录文件")
+		return
+	}
+	format, err := catalog.ParseFormat(req.Format, fileHeader.Filename)
+	if err != nil {
+		logger.Log.Warn("ImportBooks: 无法识别文件格式", zap.String("filename", fileHeader.Filename))
+		result.Fail(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+	file, err := fileHeader.Open()
+	if err != nil {
+		logger.Log.Error("ImportBooks: 打开上传文件失败", zap.Error(err))
+		result.Fail(ctx, http.StatusInternalServerError, "导入图书失败")
+		return
+	}
+	defer file.Close()
+
+	report, err := a.bookService.ImportBooks(ctx.Request.Context(), format, file, req.DryRun)
+	if err != nil {
+		if errors.Is(err, service.ErrCatalogFile) {
+			logger.Log.Warn("ImportBooks: 目录文件格式错误", zap.String("filename", fileHeader.Filename), zap.Error(err))
+			result.Fail(ctx, http.StatusBadRequest, err.Error())
+			return
+		}
+		logger.Log.Error("ImportBooks: 导入图书失败", zap.Error(err))
+		result.Fail(ctx, http.StatusInternalServerError, "导入图书失败")
+		return
+	}
+	if report.DryRun {
+		result.Success(ctx, "校验完成", report)
+		return
+	}
+	result.Success(ctx, "导入完成", report)
+}
+
+// ExportBooks 导出全部图书（含下架图书）, 以附件形式流式写出
+func (a *AdminBookHandler) ExportBooks(ctx *gin.Context) {
+	var req request.BookExportDTO
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		logger.Log.Warn("ExportBooks: 请求参数错误", zap.Error(err))
+		result.Fail(ctx, http.StatusBadRequest, "请求参数错误")
+		return
+	}
+	format := req.Format
+	if format == "" {
+		format = catalog.FormatCSV
+	}
+
+	filename := "books-" + time.Now().Format("20060102") + catalog.FileExt(format)
+	ctx.Header("Content-Type", catalog.ContentType(format))
+	ctx.Header("Content-Disposition", `attachment; filename="`+filename+`"`)
+	count, err := a.bookService.ExportBooks(ctx.Request.Context(), format, ctx.Writer)
+	if err != nil {
+		logger.Log.Error("ExportBooks: 导出图书失败", zap.String("format", format), zap.Int("count", count), zap.Error(err))
+		// 已开始写出时响应头已发送, 只能中断输出
+		if !ctx.Writer.Written() {
+			ctx.Header("Content-Type", "")
+			ctx.Header("Content-Disposition", "")
+			result.Fail(ctx, http.StatusInternalServerError, "导出图书失败")
+		}
+	}
+}
+
+// failWithBookError 根据 service 层错误类型返回对应的 HTTP 状态码
+func (a *AdminBookHandler) failWithBookError(ctx *gin.Context, op, msg string, id uint64, err error) {
+	switch {
+	case errors.Is(err, service.ErrBookNotFound):
+		logger.Log.Warn(op+": 图书不存在", zap.Uint64("id", id))
+		result.Fail(ctx, http.StatusNotFound, err.Error())
+	case errors.Is(err, service.ErrBookHasOrders):
+		logger.Log.Warn(op+": 图书已存在订单记录", zap.Uint64("id", id))
+		result.Fail(ctx, http.StatusConflict, err.Error())
+	case errors.Is(err, service.ErrISBNExists):
+		logger.Log.Warn(op+": ISBN 重复", zap.Uint64("id", id), zap.Error(err))
+		result.Fail(ctx, http.StatusConflict, err.Error())
+	case errors.Is(err, service.ErrStockBelowReserved):
+		logger.Log.Warn(op+": 库存小于已预占数量", zap.Uint64("id", id), zap.Error(err))
+		result.Fail(ctx, http.StatusConflict, err.Error())
+	default:
+		logger.Log.Error(op+": "+msg, zap.Uint64("id", id), zap.Error(err))
+		result.Fail(ctx, http.StatusInternalServerError, msg)
+	}
+}
@@ -0,0 +1,137 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/wangn-tech/bookstore-go/common/result"
+	"github.com/wangn-tech/bookstore-go/internal/api/request"
+	"github.com/wangn-tech/bookstore-go/internal/service"
+	"github.com/wangn-tech/bookstore-go/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// AdminCarouselHandler 管理员轮播图管理
+type AdminCarouselHandler struct {
+	carouselService service.ICarouselService
+}
+
+// NewAdminCarouselHandler 构造函数
+func NewAdminCarouselHandler(carouselService service.ICarouselService) *AdminCarouselHandler {
+	return &AdminCarouselHandler{
+		carouselService: carouselService,
+	}
+}
+
+// GetCarousels 获取全部轮播图（包含未激活和排期中的）
+func (a *AdminCarouselHandler) GetCarousels(ctx *gin.Context) {
+	carousels, err := a.carouselService.GetAllCarousels(ctx.Request.Context())
+	if err != nil {
+		logger.Log.Error("AdminGetCarousels: 获取轮播图列表失败", zap.Error(err))
+		result.Fail(ctx, http.StatusInternalServerError, "获取轮播图列表失败")
+		return
+	}
+	result.Success(ctx, "获取轮播图列表成功", carousels)
+}
+
+// GetCarousel 获取轮播图详情
+func (a *AdminCarouselHandler) GetCarousel(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		logger.Log.Warn("AdminGetCarousel: 轮播图ID无效", zap.String("id", ctx.Param("id")))
+		result.Fail(ctx, http.StatusBadRequest, "轮播图ID无效")
+		return
+	}
+	carousel, err := a.carouselService.GetCarouselByID(ctx.Request.Context(), id)
+	if err != nil {
+		a.failWithCarouselError(ctx, "AdminGetCarousel", "获取轮播图失败", id, err)
+		return
+	}
+	result.Success(ctx, "获取轮播图成功", carousel)
+}
+
+// CreateCarousel 创建轮播图
+func (a *AdminCarouselHandler) CreateCarousel(ctx *gin.Context) {
+	var req request.CarouselDTO
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		logger.Log.Warn("CreateCarousel: 请求参数错误", zap.Error(err))
+		result.Fail(ctx, http.StatusBadRequest, "请求参数错误")
+		return
+	}
+	carousel, err := a.carouselService.CreateCarousel(ctx.Request.Context(), &req)
+	if err != nil {
+		a.failWithCarouselError(ctx, "CreateCarousel", "创建轮播图失败", 0, err)
+		return
+	}
+	result.Success(ctx, "创建轮播图成功", carousel)
+}
+
+// UpdateCarousel 更新轮播图
+func (a *AdminCarouselHandler) UpdateCarousel(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		logger.Log.Warn("UpdateCarousel: 轮播图ID无效", zap.String("id", ctx.Param("id")))
+		result.Fail(ctx, http.StatusBadRequest, "轮播图ID无效")
+		return
+	}
+	var req request.CarouselDTO
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		logger.Log.Warn("UpdateCarousel: 请求参数错误", zap.Error(err))
+		result.Fail(ctx, http.StatusBadRequest, "请求参数错误")
+		return
+	}
+	carousel, err := a.carouselService.UpdateCarousel(ctx.Request.Context(), id, &req)
+	if err != nil {
+		a.failWithCarouselError(ctx, "UpdateCarousel", "更新轮播图失败", id, err)
+		return
+	}
+	result.Success(ctx, "更新轮播图成功", carousel)
+}
+
+// DeleteCarousel 删除轮播图
+func (a *AdminCarouselHandler) DeleteCarousel(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		logger.Log.Warn("DeleteCarousel: 轮播图ID无效", zap.String("id", ctx.Param("id")))
+		result.Fail(ctx, http.StatusBadRequest, "轮播图ID无效")
+		return
+	}
+	if err := a.carouselService.DeleteCarousel(ctx.Request.Context(), id); err != nil {
+		a.failWithCarouselError(ctx, "DeleteCarousel", "删除轮播图失败", id, err)
+		return
+	}
+	result.Success(ctx, "删除轮播图成功", nil)
+}
+
+// ReorderCarousels 调整轮播图顺序
+func (a *AdminCarouselHandler) ReorderCarousels(ctx *gin.Context) {
+	var req request.CarouselReorderDTO
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		logger.Log.Warn("ReorderCarousels: 请求参数错误", zap.Error(err))
+		result.Fail(ctx, http.StatusBadRequest, "请求参数错误")
+		return
+	}
+	carousels, err := a.carouselService.ReorderCarousels(ctx.Request.Context(), req.IDs)
+	if err != nil {
+		a.failWithCarouselError(ctx, "ReorderCarousels", "调整轮播图顺序失败", 0, err)
+		return
+	}
+	result.Success(ctx, "调整轮播图顺序成功", carousels)
+}
+
+// failWithCarouselError 根据 service 层错误类型返回对应的 HTTP 状态码
+func (a *AdminCarouselHandler) failWithCarouselError(ctx *gin.Context, op, msg string, id uint64, err error) {
+	switch {
+	case errors.Is(err, service.ErrCarouselNotFound):
+		logger.Log.Warn(op+": 轮播图不存在", zap.Uint64("id", id))
+		result.Fail(ctx, http.StatusNotFound, err.Error())
+	case errors.Is(err, service.ErrInvalidCarouselWindow), errors.Is(err, service.ErrInvalidCarouselOrder):
+		logger.Log.Warn(op+": 请求参数错误", zap.Uint64("id", id), zap.Error(err))
+		result.Fail(ctx, http.StatusBadRequest, err.Error())
+	default:
+		logger.Log.Error(op+": "+msg, zap.Uint64("id", id), zap.Error(err))
+		result.Fail(ctx, http.StatusInternalServerError, msg)
+	}
+}
@@ -0,0 +1,111 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/wangn-tech/bookstore-go/common/result"
+	"github.com/wangn-tech/bookstore-go/internal/api/request"
+	"github.com/wangn-tech/bookstore-go/internal/api/response"
+	"github.com/wangn-tech/bookstore-go/internal/app/constants"
+	"github.com/wangn-tech/bookstore-go/internal/service"
+	"github.com/wangn-tech/bookstore-go/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// AdminOrderHandler 管理员订单管理, 不校验订单归属
+type AdminOrderHandler struct {
+	orderService service.IOrderService
+}
+
+// NewAdminOrderHandler 构造函数
+func NewAdminOrderHandler(orderService service.IOrderService) *AdminOrderHandler {
+	return &AdminOrderHandler{
+		orderService: orderService,
+	}
+}
+
+// GetOrders 分页获取全部订单
+func (a *AdminOrderHandler) GetOrders(ctx *gin.Context) {
+	var req request.OrdersPageDTO
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		logger.Log.Warn("AdminGetOrders: 请求参数错误", zap.Error(err))
+		result.Fail(ctx, http.StatusBadRequest, "无效的请求参数")
+		return
+	}
+	result.PageVerify(&req.Page, &req.PageSize)
+
+	pageResult, err := a.orderService.GetAllOrders(ctx.Request.Context(), &req)
+	if err != nil {
+		logger.Log.Error("AdminGetOrders: 获取订单列表失败", zap.Error(err))
+		result.Fail(ctx, http.StatusInternalServerError, "获取订单列表失败")
+		return
+	}
+	result.Success(ctx, "获取订单列表成功", &response.OrdersPageVO{
+		Orders:    pageResult.Records,
+		Total:     pageResult.Total,
+		Page:      req.Page,
+		PageSize:  req.PageSize,
+		TotalPage: (pageResult.Total + int64(req.PageSize) - 1) / int64(req.PageSize),
+	})
+}
+
+// GetOrderByID 根据 ID 获取任意用户的订单详情
+func (a *AdminOrderHandler) GetOrderByID(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		logger.Log.Warn("AdminGetOrderByID: 请求参数错误", zap.Error(err))
+		result.Fail(ctx, http.StatusBadRequest, "无效的订单 ID")
+		return
+	}
+
+	order, err := a.orderService.GetOrderByIDForAdmin(ctx.Request.Context(), id)
+	if err != nil {
+		failWithOrderError(ctx, "AdminGetOrderByID", "获取订单失败", id, err)
+		return
+	}
+	result.Success(ctx, "获取订单成功", order)
+}
+
+// TransitionOrder 变更订单状态: 发货、送达、完成、退款、取消
+func (a *AdminOrderHandler) TransitionOrder(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		logger.Log.Warn("TransitionOrder: 请求参数错误", zap.Error(err))
+		result.Fail(ctx, http.StatusBadRequest, "无效的订单 ID")
+		return
+	}
+	var req request.OrderTransitionDTO
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		logger.Log.Warn("TransitionOrder: 请求参数错误", zap.Error(err))
+		result.Fail(ctx, http.StatusBadRequest, "无效的请求参数")
+		return
+	}
+
+	adminID := ctx.GetUint64(constants.UserID)
+	if err := a.orderService.TransitionOrderByAdmin(ctx.Request.Context(), adminID, id, *req.Status, req.Remark); err != nil {
+		failWithOrderError(ctx, "TransitionOrder", "变更订单状态失败", id, err)
+		return
+	}
+	logger.Log.Info("TransitionOrder: 变更订单状态成功",
+		zap.Uint64("id", id), zap.Int("status", *req.Status), zap.Uint64("adminID", adminID))
+	result.Success(ctx, "变更订单状态成功", nil)
+}
+
+// GetOrderStatusHistory 获取订单状态变更记录
+func (a *AdminOrderHandler) GetOrderStatusHistory(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		logger.Log.Warn("AdminGetOrderStatusHistory: 请求参数错误", zap.Error(err))
+		result.Fail(ctx, http.StatusBadRequest, "无效的订单 ID")
+		return
+	}
+
+	histories, err := a.orderService.GetOrderStatusHistoryForAdmin(ctx.Request.Context(), id)
+	if err != nil {
+		failWithOrderError(ctx, "AdminGetOrderStatusHistory", "获取订单状态记录失败", id, err)
+		return
+	}
+	result.Success(ctx, "获取订单状态记录成功", histories)
+}
@@ -0,0 +1,65 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/wangn-tech/bookstore-go/common/result"
+	"github.com/wangn-tech/bookstore-go/internal/api/request"
+	"github.com/wangn-tech/bookstore-go/internal/service"
+	"github.com/wangn-tech/bookstore-go/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// AdminReviewHandler 管理员评价审核
+type AdminReviewHandler struct {
+	reviewService service.IReviewService
+}
+
+// NewAdminReviewHandler 构造函数
+func NewAdminReviewHandler(reviewService service.IReviewService) *AdminReviewHandler {
+	return &AdminReviewHandler{
+		reviewService: reviewService,
+	}
+}
+
+// GetReviews 分页获取评价（包含已隐藏的评价）, 可按图书、用户和状态筛选
+func (a *AdminReviewHandler) GetReviews(ctx *gin.Context) {
+	var req request.AdminReviewsPageDTO
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		logger.Log.Warn("AdminGetReviews: 查询参数绑定失败", zap.Error(err))
+		result.Fail(ctx, http.StatusBadRequest, "无效的请求参数")
+		return
+	}
+	reviews, err := a.reviewService.GetReviewsForAdmin(ctx.Request.Context(), &req)
+	if err != nil {
+		logger.Log.Error("AdminGetReviews: 获取评价列表失败", zap.Error(err))
+		result.Fail(ctx, http.StatusInternalServerError, "获取评价列表失败")
+		return
+	}
+	result.Success(ctx, "获取评价列表成功", reviews)
+}
+
+// UpdateReviewStatus 审核评价: 通过或隐藏
+func (a *AdminReviewHandler) UpdateReviewStatus(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		logger.Log.Warn("UpdateReviewStatus: 评价ID无效", zap.String("id", ctx.Param("id")))
+		result.Fail(ctx, http.StatusBadRequest, "评价ID无效")
+		return
+	}
+	var req request.ReviewStatusDTO
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		logger.Log.Warn("UpdateReviewStatus: 请求参数错误", zap.Error(err))
+		result.Fail(ctx, http.StatusBadRequest, "请求参数错误")
+		return
+	}
+
+	review, err := a.reviewService.UpdateReviewStatus(ctx.Request.Context(), id, *req.Status)
+	if err != nil {
+		failWithReviewError(ctx, "UpdateReviewStatus", "更新评价状态失败", id, err)
+		return
+	}
+	result.Success(ctx, "更新评价状态成功", review)
+}
@@ -1,29 +1,104 @@
 package handler
 
 import (
+	"context"
+	"errors"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/wangn-tech/bookstore-go/common/result"
 	"github.com/wangn-tech/bookstore-go/internal/api/request"
 	"github.com/wangn-tech/bookstore-go/internal/api/response"
+	"github.com/wangn-tech/bookstore-go/internal/app/constants"
+	"github.com/wangn-tech/bookstore-go/internal/model"
+	"github.com/wangn-tech/bookstore-go/internal/search"
 	"github.com/wangn-tech/bookstore-go/internal/service"
+	"github.com/wangn-tech/bookstore-go/pkg/isbn"
 	"github.com/wangn-tech/bookstore-go/pkg/logger"
 	"go.uber.org/zap"
 )
 
 type BookHandler struct {
-	bookService service.IBookService
+	bookService      service.IBookService
+	favoriteService  service.IFavoriteService
+	suggestService   service.ISuggestService
+	reviewService    service.IReviewService
+	recommendService service.IRecommendService
+	rankingService   service.IBookRankingService
+	historyService   service.IViewHistoryService
 }
 
-func NewBookHandler(bookService service.IBookService) *BookHandler {
+func NewBookHandler(bookService service.IBookService, favoriteService service.IFavoriteService, suggestService service.ISuggestService,
+	reviewService service.IReviewService, recommendService service.IRecommendService, rankingService service.IBookRankingService,
+	historyService service.IViewHistoryService) *BookHandler {
 	return &BookHandler{
-		bookService: bookService,
+		bookService:      bookService,
+		favoriteService:  favoriteService,
+		suggestService:   suggestService,
+		reviewService:    reviewService,
+		recommendService: recommendService,
+		rankingService:   rankingService,
+		historyService:   historyService,
 	}
 }
 
-// GetBookList 获取书籍列表，支持分页
+const (
+	// suggestTimeout 搜索联想的处理时限, 超时返回空结果, 不阻塞输入
+	suggestTimeout = 200 * time.Millisecond
+	// suggestMaxLimit 搜索联想最多返回的条数
+	suggestMaxLimit = 20
+	// bookDetailReviewSize 图书详情中附带的评价条数, 更多评价通过评价列表分页获取
+	bookDetailReviewSize = 5
+	// recommendMaxLimit 相关图书和个性化推荐最多返回的条数
+	recommendMaxLimit = 20
+	// hotBooksDefaultLimit 畅销榜默认返回的条数
+	hotBooksDefaultLimit = 5
+)
+
+// fillFavoriteInfo 填充收藏数和当前用户的收藏状态, 失败时只记录日志, 不影响图书数据返回
+func (b *BookHandler) fillFavoriteInfo(ctx *gin.Context, books ...*model.Book) {
+	userID := ctx.GetUint64(constants.UserID)
+	if err := b.favoriteService.FillFavoriteInfo(ctx.Request.Context(), userID, books...); err != nil {
+		logger.Log.Warn("fillFavoriteInfo: 获取收藏信息失败", zap.Uint64("userID", userID), zap.Error(err))
+	}
+}
+
+// fillReviewInfo 填充评分分布和最有用的几条评价, 失败时只记录日志, 不影响图书数据返回
+func (b *BookHandler) fillReviewInfo(ctx *gin.Context, detail *response.BookDetailVO) {
+	userID := ctx.GetUint64(constants.UserID)
+	distribution, err := b.reviewService.GetRatingDistribution(ctx.Request.Context(), detail.ID)
+	if err != nil {
+		logger.Log.Warn("fillReviewInfo: 获取评分分布失败", zap.Uint64("bookID", detail.ID), zap.Error(err))
+	} else {
+		detail.RatingDistribution = distribution
+	}
+	reviews, err := b.reviewService.GetBookReviews(ctx.Request.Context(), userID, detail.ID, &request.ReviewsPageDTO{
+		Page:     1,
+		PageSize: bookDetailReviewSize,
+		Sort:     "helpful",
+	})
+	if err != nil {
+		logger.Log.Warn("fillReviewInfo: 获取图书评价失败", zap.Uint64("bookID", detail.ID), zap.Error(err))
+		return
+	}
+	detail.Reviews = reviews
+}
+
+// recordView 记录浏览次数和登录用户的浏览历史, 失败时只记录日志, 不影响图书数据返回
+func (b *BookHandler) recordView(ctx *gin.Context, detail *response.BookDetailVO) {
+	userID := ctx.GetUint64(constants.UserID)
+	count, err := b.historyService.RecordView(ctx.Request.Context(), userID, detail.ID)
+	if err != nil {
+		logger.Log.Warn("recordView: 记录浏览失败", zap.Uint64("userID", userID), zap.Uint64("bookID", detail.ID), zap.Error(err))
+		return
+	}
+	detail.ViewCount = count
+}
+
+// GetBookList 获取书籍列表，支持分页、筛选和排序, 只返回上架图书
 func (b *BookHandler) GetBookList(ctx *gin.Context) {
 	// page, _ := strconv.Atoi(ctx.DefaultQuery("page", "1"))
 	// pageSize, _ := strconv.Atoi(ctx.DefaultQuery("page_size", "12"))
@@ -31,10 +106,11 @@ func (b *BookHandler) GetBookList(ctx *gin.Context) {
 	// 绑定查询参数 (form)，如果绑定失败则使用默认值
 	if err := ctx.ShouldBindQuery(&pageReq); err != nil {
 		// 对于分页查询，即使参数有误，也应提供默认查询结果，而不是直接报错
+		// 绑定失败时 pageReq 可能已部分赋值, 重置后只保留默认分页, 不带任何筛选条件
 		logger.Log.Warn("PageQuery: 查询参数绑定失败，使用默认值", zap.Error(err))
-		pageReq.Page = 1
-		pageReq.PageSize = 10
+		pageReq = request.BooksPageDTO{Page: 1, PageSize: 10}
 	}
+	result.PageVerify(&pageReq.Page, &pageReq.PageSize)
 
 	pageResult, err := b.bookService.GetBooksByPage(ctx.Request.Context(), &pageReq)
 	if err != nil {
@@ -43,6 +119,7 @@ func (b *BookHandler) GetBookList(ctx *gin.Context) {
 		result.Fail(ctx, http.StatusInternalServerError, "获取书籍列表失败")
 		return
 	}
+	b.fillFavoriteInfo(ctx, pageResult.Records...)
 
 	result.Success(ctx, "获取书籍列表成功", &response.BooksPageVO{
 		Books:     pageResult.Records,
@@ -53,20 +130,82 @@ func (b *BookHandler) GetBookList(ctx *gin.Context) {
 	})
 }
 
-// GetHotBooks 获取热销图书
+// GetHotBooks 获取畅销榜, 支持按统计周期 (日/周/月/累计) 和分类筛选
 func (b *BookHandler) GetHotBooks(ctx *gin.Context) {
-	limit, _ := strconv.Atoi(ctx.DefaultQuery("limit", "5"))
+	var req request.HotBooksDTO
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		logger.Log.Warn("GetHotBooks: 查询参数绑定失败", zap.Error(err))
+		result.Fail(ctx, http.StatusBadRequest, "无效的请求参数")
+		return
+	}
+	if req.Limit == 0 {
+		req.Limit = hotBooksDefaultLimit
+	}
 
-	books, err := b.bookService.GetHotBooks(ctx.Request.Context(), limit)
+	books, err := b.rankingService.GetHotBooks(ctx.Request.Context(), req.Period, req.CategoryID, req.Limit)
 	if err != nil {
-		logger.Log.Error("GetHotBooks: 获取热销图书失败", zap.Error(err))
+		if errors.Is(err, service.ErrCategoryNotFound) {
+			logger.Log.Warn("GetHotBooks: 分类不存在", zap.Uint64("categoryID", req.CategoryID))
+			result.Fail(ctx, http.StatusNotFound, err.Error())
+			return
+		}
+		logger.Log.Error("GetHotBooks: 获取热销图书失败", zap.String("period", req.Period), zap.Uint64("categoryID", req.CategoryID), zap.Error(err))
 		result.Fail(ctx, http.StatusInternalServerError, "获取热销图书失败")
 		return
 	}
+	b.fillFavoriteInfo(ctx, books...)
 
 	result.Success(ctx, "获取热销图书成功", books)
 }
 
+// GetRelatedBooks 买了这本书的人还买了
+func (b *BookHandler) GetRelatedBooks(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		logger.Log.Warn("GetRelatedBooks: 图书ID无效", zap.String("id", ctx.Param("id")), zap.Error(err))
+		result.Fail(ctx, http.StatusBadRequest, "图书ID无效")
+		return
+	}
+	limit, _ := strconv.Atoi(ctx.DefaultQuery("limit", "10"))
+	if limit <= 0 || limit > recommendMaxLimit {
+		limit = recommendMaxLimit
+	}
+
+	books, err := b.recommendService.GetRelatedBooks(ctx.Request.Context(), id, limit)
+	if err != nil {
+		if errors.Is(err, service.ErrBookNotFound) {
+			logger.Log.Warn("GetRelatedBooks: 图书不存在", zap.Uint64("id", id))
+			result.Fail(ctx, http.StatusNotFound, err.Error())
+			return
+		}
+		logger.Log.Error("GetRelatedBooks: 获取相关图书失败", zap.Uint64("id", id), zap.Error(err))
+		result.Fail(ctx, http.StatusInternalServerError, "获取相关图书失败")
+		return
+	}
+	b.fillFavoriteInfo(ctx, books...)
+
+	result.Success(ctx, "获取相关图书成功", books)
+}
+
+// GetRecommendations 猜你喜欢: 登录用户按购买和收藏记录推荐, 未登录时返回各分类畅销书
+func (b *BookHandler) GetRecommendations(ctx *gin.Context) {
+	limit, _ := strconv.Atoi(ctx.DefaultQuery("limit", "10"))
+	if limit <= 0 || limit > recommendMaxLimit {
+		limit = recommendMaxLimit
+	}
+	userID := ctx.GetUint64(constants.UserID)
+
+	books, err := b.recommendService.GetRecommendations(ctx.Request.Context(), userID, limit)
+	if err != nil {
+		logger.Log.Error("GetRecommendations: 获取推荐图书失败", zap.Uint64("userID", userID), zap.Error(err))
+		result.Fail(ctx, http.StatusInternalServerError, "获取推荐图书失败")
+		return
+	}
+	b.fillFavoriteInfo(ctx, books...)
+
+	result.Success(ctx, "获取推荐图书成功", books)
+}
+
 // GetNewBooks 获取新书
 func (b *BookHandler) GetNewBooks(ctx *gin.Context) {
 	limit, _ := strconv.Atoi(ctx.DefaultQuery("limit", "5"))
@@ -76,6 +215,7 @@ func (b *BookHandler) GetNewBooks(ctx *gin.Context) {
 		result.Fail(ctx, http.StatusInternalServerError, "获取新书失败")
 		return
 	}
+	b.fillFavoriteInfo(ctx, books...)
 
 	result.Success(ctx, "获取新书成功", books)
 }
@@ -94,48 +234,95 @@ func (b *BookHandler) GetBookDetail(ctx *gin.Context) {
 		result.Fail(ctx, http.StatusInternalServerError, "获取图书详情失败")
 		return
 	}
+	result.Success(ctx, "获取图书详情成功", b.buildDetail(ctx, book))
+}
+
+// GetBookByISBN 根据 ISBN 获取图书详情, ISBN-10 和 ISBN-13 均可, 允许包含连字符
+func (b *BookHandler) GetBookByISBN(ctx *gin.Context) {
+	isbn13, err := isbn.To13(ctx.Param("isbn"))
+	if err != nil {
+		logger.Log.Warn("GetBookByISBN: ISBN 无效", zap.String("isbn", ctx.Param("isbn")), zap.Error(err))
+		result.Fail(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+	book, err := b.bookService.GetBookByISBN(ctx.Request.Context(), isbn13)
+	if err != nil {
+		if errors.Is(err, service.ErrBookNotFound) {
+			logger.Log.Warn("GetBookByISBN: 图书不存在", zap.String("isbn", isbn13))
+			result.Fail(ctx, http.StatusNotFound, err.Error())
+			return
+		}
+		logger.Log.Error("GetBookByISBN: 获取图书详情失败", zap.String("isbn", isbn13), zap.Error(err))
+		result.Fail(ctx, http.StatusInternalServerError, "获取图书详情失败")
+		return
+	}
+	result.Success(ctx, "获取图书详情成功", b.buildDetail(ctx, book))
+}
 
-	result.Success(ctx, "获取图书详情成功", book)
+// buildDetail 组装图书详情: 收藏状态、评价和浏览次数
+func (b *BookHandler) buildDetail(ctx *gin.Context, book *model.Book) *response.BookDetailVO {
+	b.fillFavoriteInfo(ctx, book)
+	detail := &response.BookDetailVO{Book: book}
+	b.fillReviewInfo(ctx, detail)
+	b.recordView(ctx, detail)
+	return detail
 }
 
-// SearchBooks
+// SearchBooks 全文检索图书, 返回按相关度排序的结果、高亮片段和分面统计
 func (b *BookHandler) SearchBooks(ctx *gin.Context) {
+	// 检索参数: q, page, page_size 及筛选条件
+	var searchReq request.BookSearchDTO
+	if err := ctx.ShouldBindQuery(&searchReq); err != nil {
+		logger.Log.Warn("SearchBooks: 查询参数绑定失败", zap.Error(err))
+		result.Fail(ctx, http.StatusBadRequest, "无效的查询参数")
+		return
+	}
 	// 搜索栏输入文本
-	keyword := ctx.Query("q")
-	if keyword == "" {
-		keyword = ctx.Query("keyword") // 兼容旧版本
+	if searchReq.Keyword == "" {
+		searchReq.Keyword = ctx.Query("keyword") // 兼容旧版本
 	}
-	if keyword == "" {
+	searchReq.Keyword = strings.TrimSpace(searchReq.Keyword)
+	if searchReq.Keyword == "" {
 		logger.Log.Warn("SearchBooks: 搜索关键词为空")
 		result.Fail(ctx, http.StatusBadRequest, "搜索关键词不能为空")
 		return
 	}
-
-	// 分页查询参数: page, page_size
-	var pageReq request.BooksPageDTO
-	if err := ctx.ShouldBindQuery(&pageReq); err != nil {
-		logger.Log.Warn("SearchBooks: 查询参数绑定失败，使用默认值", zap.Error(err))
-		pageReq.Page = 1
-		pageReq.PageSize = 12
+	if err := search.ValidatePriceBand(searchReq.PriceBand); err != nil {
+		logger.Log.Warn("SearchBooks: 价格区间无效", zap.String("price_band", searchReq.PriceBand))
+		result.Fail(ctx, http.StatusBadRequest, err.Error())
+		return
 	}
-	// service 层返回分页结果
-	pageResult, err := b.bookService.SearchBooksWithPagination(ctx.Request.Context(), keyword, &pageReq)
+
+	searchResult, err := b.bookService.SearchBooks(ctx.Request.Context(), &searchReq)
 	if err != nil {
-		logger.Log.Error("SearchBooks: 搜索图书失败", zap.Error(err))
+		logger.Log.Error("SearchBooks: 搜索图书失败", zap.String("keyword", searchReq.Keyword), zap.Error(err))
 		result.Fail(ctx, http.StatusInternalServerError, "搜索图书失败")
 		return
 	}
-	// 封装 VO
-	result.Success(ctx, "搜索图书成功", &response.BooksPageVO{
-		Books:     pageResult.Records,
-		Total:     pageResult.Total,
-		Page:      pageReq.Page,
-		PageSize:  pageReq.PageSize,
-		TotalPage: (pageResult.Total + int64(pageReq.PageSize) - 1) / int64(pageReq.PageSize),
-	})
+	b.fillFavoriteInfo(ctx, searchResult.Books...)
+	result.Success(ctx, "搜索图书成功", searchResult)
+}
+
+// SuggestBooks 搜索联想, 按前缀匹配书名、作者、丛书和热门搜索词; q 为空时返回热门搜索词
+func (b *BookHandler) SuggestBooks(ctx *gin.Context) {
+	prefix := ctx.Query("q")
+	limit, _ := strconv.Atoi(ctx.DefaultQuery("limit", "10"))
+	if limit <= 0 || limit > suggestMaxLimit {
+		limit = suggestMaxLimit
+	}
+
+	c, cancel := context.WithTimeout(ctx.Request.Context(), suggestTimeout)
+	defer cancel()
+	suggestions, err := b.suggestService.Suggest(c, prefix, limit)
+	if err != nil {
+		// 联想失败不影响搜索, 返回空结果
+		logger.Log.Warn("SuggestBooks: 获取搜索联想失败", zap.String("q", prefix), zap.Error(err))
+		suggestions = []*response.SuggestionVO{}
+	}
+	result.Success(ctx, "获取搜索联想成功", suggestions)
 }
 
-// GetBooksByCategory
+// GetBooksByCategory 获取分类 (含子分类) 下的图书, category 可以是分类 ID 或名称
 func (b *BookHandler) GetBooksByCategory(ctx *gin.Context) {
 	category := ctx.Param("category")
 	if category == "" {
@@ -144,11 +331,12 @@ func (b *BookHandler) GetBooksByCategory(ctx *gin.Context) {
 		return
 	}
 	// 调用 service 层
-	books, err := b.bookService.GetBooksByType(ctx.Request.Context(), category)
+	books, err := b.bookService.GetBooksByCategory(ctx.Request.Context(), category)
 	if err != nil {
 		logger.Log.Error("GetBooksByCategory: 获取分类图书失败", zap.String("category", category), zap.Error(err))
 		result.Fail(ctx, http.StatusInternalServerError, "获取分类图书失败")
 		return
 	}
+	b.fillFavoriteInfo(ctx, books...)
 	result.Success(ctx, "获取分类图书成功", books)
 }
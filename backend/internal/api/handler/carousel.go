@@ -0,0 +1,32 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/wangn-tech/bookstore-go/common/result"
+	"github.com/wangn-tech/bookstore-go/internal/service"
+	"github.com/wangn-tech/bookstore-go/pkg/logger"
+	"go.uber.org/zap"
+)
+
+type CarouselHandler struct {
+	carouselService service.ICarouselService
+}
+
+func NewCarouselHandler(carouselService service.ICarouselService) *CarouselHandler {
+	return &CarouselHandler{
+		carouselService: carouselService,
+	}
+}
+
+// GetCarousels 获取首页轮播图
+func (c *CarouselHandler) GetCarousels(ctx *gin.Context) {
+	carousels, err := c.carouselService.GetActiveCarousels(ctx.Request.Context())
+	if err != nil {
+		logger.Log.Error("GetCarousels: 获取轮播图失败", zap.Error(err))
+		result.Fail(ctx, http.StatusInternalServerError, "获取轮播图失败")
+		return
+	}
+	result.Success(ctx, "获取轮播图成功", carousels)
+}
@@ -0,0 +1,178 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/wangn-tech/bookstore-go/common/result"
+	"github.com/wangn-tech/bookstore-go/internal/api/request"
+	"github.com/wangn-tech/bookstore-go/internal/app/constants"
+	"github.com/wangn-tech/bookstore-go/internal/service"
+	"github.com/wangn-tech/bookstore-go/pkg/logger"
+	"go.uber.org/zap"
+)
+
+type CartHandler struct {
+	cartService service.ICartService
+}
+
+func NewCartHandler(cartService service.ICartService) *CartHandler {
+	return &CartHandler{
+		cartService: cartService,
+	}
+}
+
+// GetCart 获取购物车
+func (c *CartHandler) GetCart(ctx *gin.Context) {
+	userID := ctx.GetUint64(constants.UserID)
+	if userID == 0 {
+		logger.Log.Warn("GetCart: 用户ID不存在")
+		result.Fail(ctx, http.StatusUnauthorized, "用户未登录")
+		return
+	}
+	cart, err := c.cartService.GetCart(ctx.Request.Context(), userID)
+	if err != nil {
+		logger.Log.Error("GetCart: 获取购物车失败", zap.Uint64("userID", userID), zap.Error(err))
+		result.Fail(ctx, http.StatusInternalServerError, "获取购物车失败")
+		return
+	}
+	result.Success(ctx, "获取购物车成功", cart)
+}
+
+// AddItem 加入购物车
+func (c *CartHandler) AddItem(ctx *gin.Context) {
+	var req request.AddCartItemDTO
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		logger.Log.Warn("AddCartItem: 请求参数错误", zap.Error(err))
+		result.Fail(ctx, http.StatusBadRequest, "无效的请求参数")
+		return
+	}
+	userID := ctx.GetUint64(constants.UserID)
+	if userID == 0 {
+		logger.Log.Warn("AddCartItem: 用户ID不存在")
+		result.Fail(ctx, http.StatusUnauthorized, "用户未登录")
+		return
+	}
+	if err := c.cartService.AddItem(ctx.Request.Context(), userID, req.BookID, req.Quantity); err != nil {
+		failWithCartError(ctx, "AddCartItem", "加入购物车失败", userID, err)
+		return
+	}
+	result.Success(ctx, "加入购物车成功", nil)
+}
+
+// UpdateItem 修改购物车商品数量
+func (c *CartHandler) UpdateItem(ctx *gin.Context) {
+	bookID, err := strconv.ParseUint(ctx.Param("book_id"), 10, 64)
+	if err != nil {
+		logger.Log.Warn("UpdateCartItem: 请求参数错误", zap.Error(err))
+		result.Fail(ctx, http.StatusBadRequest, "无效的图书 ID")
+		return
+	}
+	var req request.UpdateCartItemDTO
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		logger.Log.Warn("UpdateCartItem: 请求参数错误", zap.Error(err))
+		result.Fail(ctx, http.StatusBadRequest, "无效的请求参数")
+		return
+	}
+	userID := ctx.GetUint64(constants.UserID)
+	if userID == 0 {
+		logger.Log.Warn("UpdateCartItem: 用户ID不存在")
+		result.Fail(ctx, http.StatusUnauthorized, "用户未登录")
+		return
+	}
+	if err := c.cartService.UpdateItem(ctx.Request.Context(), userID, bookID, req.Quantity); err != nil {
+		failWithCartError(ctx, "UpdateCartItem", "修改购物车失败", userID, err)
+		return
+	}
+	result.Success(ctx, "修改购物车成功", nil)
+}
+
+// RemoveItem 从购物车移除商品
+func (c *CartHandler) RemoveItem(ctx *gin.Context) {
+	bookID, err := strconv.ParseUint(ctx.Param("book_id"), 10, 64)
+	if err != nil {
+		logger.Log.Warn("RemoveCartItem: 请求参数错误", zap.Error(err))
+		result.Fail(ctx, http.StatusBadRequest, "无效的图书 ID")
+		return
+	}
+	userID := ctx.GetUint64(constants.UserID)
+	if userID == 0 {
+		logger.Log.Warn("RemoveCartItem: 用户ID不存在")
+		result.Fail(ctx, http.StatusUnauthorized, "用户未登录")
+		return
+	}
+	if err := c.cartService.RemoveItem(ctx.Request.Context(), userID, bookID); err != nil {
+		failWithCartError(ctx, "RemoveCartItem", "移除购物车商品失败", userID, err)
+		return
+	}
+	result.Success(ctx, "移除购物车商品成功", nil)
+}
+
+// ClearCart 清空购物车
+func (c *CartHandler) ClearCart(ctx *gin.Context) {
+	userID := ctx.GetUint64(constants.UserID)
+	if userID == 0 {
+		logger.Log.Warn("ClearCart: 用户ID不存在")
+		result.Fail(ctx, http.StatusUnauthorized, "用户未登录")
+		return
+	}
+	if err := c.cartService.ClearCart(ctx.Request.Context(), userID); err != nil {
+		logger.Log.Error("ClearCart: 清空购物车失败", zap.Uint64("userID", userID), zap.Error(err))
+		result.Fail(ctx, http.StatusInternalServerError, "清空购物车失败")
+		return
+	}
+	result.Success(ctx, "清空购物车成功", nil)
+}
+
+// Checkout 购物车结算下单
+func (c *CartHandler) Checkout(ctx *gin.Context) {
+	var req request.CartCheckoutDTO
+	// 请求体可选, 为空时结算整个购物车
+	if ctx.Request.ContentLength > 0 {
+		if err := ctx.ShouldBindJSON(&req); err != nil {
+			logger.Log.Warn("Checkout: 请求参数错误", zap.Error(err))
+			result.Fail(ctx, http.StatusBadRequest, "无效的请求参数")
+			return
+		}
+	}
+	userID := ctx.GetUint64(constants.UserID)
+	if userID == 0 {
+		logger.Log.Warn("Checkout: 用户ID不存在")
+		result.Fail(ctx, http.StatusUnauthorized, "用户未登录")
+		return
+	}
+	order, err := c.cartService.Checkout(ctx.Request.Context(), userID, req.BookIDs)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrCartEmpty):
+			logger.Log.Warn("Checkout: 购物车为空", zap.Uint64("userID", userID))
+			result.Fail(ctx, http.StatusBadRequest, err.Error())
+		case errors.Is(err, service.ErrCartItemNotFound):
+			logger.Log.Warn("Checkout: 购物车中没有该商品", zap.Uint64("userID", userID), zap.Error(err))
+			result.Fail(ctx, http.StatusBadRequest, err.Error())
+		default:
+			failWithCreateOrderError(ctx, "Checkout", userID, err)
+		}
+		return
+	}
+	logger.Log.Info("Checkout: 购物车结算成功", zap.Uint64("userID", userID), zap.Uint64("orderID", order.ID))
+	result.Success(ctx, "创建订单成功", order)
+}
+
+// failWithCartError 根据 service 层错误类型返回对应的 HTTP 状态码
+func failWithCartError(ctx *gin.Context, op, msg string, userID uint64, err error) {
+	switch {
+	case errors.Is(err, service.ErrBookNotFound), errors.Is(err, service.ErrCartItemNotFound):
+		logger.Log.Warn(op+": "+err.Error(), zap.Uint64("userID", userID))
+		result.Fail(ctx, http.StatusNotFound, err.Error())
+	case errors.Is(err, service.ErrBookOffShelf), errors.Is(err, service.ErrStockNotEnough), errors.Is(err, service.ErrCartFull),
+		errors.Is(err, service.ErrCartQuantityExceeded):
+		logger.Log.Warn(op+": 商品不可加入购物车", zap.Uint64("userID", userID), zap.Error(err))
+		result.Fail(ctx, http.StatusConflict, err.Error())
+	default:
+		logger.Log.Error(op+": "+msg, zap.Uint64("userID", userID), zap.Error(err))
+		result.Fail(ctx, http.StatusInternalServerError, msg)
+	}
+}
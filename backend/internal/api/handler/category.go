@@ -1,11 +1,13 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
 	"github.com/wangn-tech/bookstore-go/common/result"
+	"github.com/wangn-tech/bookstore-go/internal/api/request"
 	"github.com/wangn-tech/bookstore-go/internal/model"
 	"github.com/wangn-tech/bookstore-go/internal/service"
 	"github.com/wangn-tech/bookstore-go/pkg/logger"
@@ -44,8 +46,7 @@ func (h *CategoryHandler) GetCategoryByID(ctx *gin.Context) {
 
 	category, err := h.categoryService.GetCategoryByID(ctx.Request.Context(), uint64(id))
 	if err != nil {
-		logger.Log.Warn("获取分类详情失败", zap.Error(err))
-		result.Fail(ctx, http.StatusInternalServerError, "获取分类详情失败")
+		failWithCategoryError(ctx, "获取分类详情失败", err)
 		return
 	}
 	result.Success(ctx, "获取分类详情成功", category)
@@ -61,8 +62,7 @@ func (h *CategoryHandler) CreateCategory(ctx *gin.Context) {
 	}
 
 	if err := h.categoryService.CreateCategory(ctx.Request.Context(), &category); err != nil {
-		logger.Log.Warn("创建分类失败", zap.Error(err))
-		result.Fail(ctx, http.StatusInternalServerError, "创建分类失败")
+		failWithCategoryError(ctx, "创建分类失败", err)
 		return
 	}
 	result.Success(ctx, "创建分类成功", category)
@@ -85,8 +85,7 @@ func (h *CategoryHandler) UpdateCategory(ctx *gin.Context) {
 	category.ID = uint64(id)
 	err = h.categoryService.UpdateCategory(ctx.Request.Context(), &category)
 	if err != nil {
-		logger.Log.Warn("更新分类失败", zap.Error(err))
-		result.Fail(ctx, http.StatusInternalServerError, "更新分类失败")
+		failWithCategoryError(ctx, "更新分类失败", err)
 		return
 	}
 	result.Success(ctx, "更新分类成功", category)
@@ -102,9 +101,93 @@ func (h *CategoryHandler) DeleteCategory(ctx *gin.Context) {
 	}
 
 	if err := h.categoryService.DeleteCategory(ctx.Request.Context(), uint64(id)); err != nil {
-		logger.Log.Warn("删除分类失败", zap.Error(err))
-		result.Fail(ctx, http.StatusInternalServerError, "删除分类失败")
+		failWithCategoryError(ctx, "删除分类失败", err)
 		return
 	}
 	result.Success(ctx, "删除分类成功", nil)
 }
+
+// GetCategoryTree 获取分类树
+func (h *CategoryHandler) GetCategoryTree(ctx *gin.Context) {
+	tree, err := h.categoryService.GetCategoryTree(ctx.Request.Context())
+	if err != nil {
+		logger.Log.Warn("获取分类树失败", zap.Error(err))
+		result.Fail(ctx, http.StatusInternalServerError, "获取分类树失败")
+		return
+	}
+	result.Success(ctx, "获取分类树成功", tree)
+}
+
+// GetBreadcrumbs 获取分类面包屑
+func (h *CategoryHandler) GetBreadcrumbs(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		logger.Log.Warn("获取分类面包屑失败", zap.Error(err))
+		result.Fail(ctx, http.StatusBadRequest, "无效的分类 ID")
+		return
+	}
+	crumbs, err := h.categoryService.GetBreadcrumbs(ctx.Request.Context(), id)
+	if err != nil {
+		failWithCategoryError(ctx, "获取分类面包屑失败", err)
+		return
+	}
+	result.Success(ctx, "获取分类面包屑成功", crumbs)
+}
+
+// MoveCategory 移动分类到新的父分类下
+func (h *CategoryHandler) MoveCategory(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		logger.Log.Warn("移动分类失败", zap.Error(err))
+		result.Fail(ctx, http.StatusBadRequest, "无效的分类 ID")
+		return
+	}
+	var req request.MoveCategoryDTO
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		logger.Log.Warn("移动分类失败", zap.Error(err))
+		result.Fail(ctx, http.StatusBadRequest, "无效的请求参数")
+		return
+	}
+	category, err := h.categoryService.MoveCategory(ctx.Request.Context(), id, req.ParentID)
+	if err != nil {
+		failWithCategoryError(ctx, "移动分类失败", err)
+		return
+	}
+	result.Success(ctx, "移动分类成功", category)
+}
+
+// ReconcileBookCounts 重新统计各分类的图书数量, dry_run=true 时只报告偏差
+func (h *CategoryHandler) ReconcileBookCounts(ctx *gin.Context) {
+	dryRun, _ := strconv.ParseBool(ctx.Query("dry_run"))
+	report, err := h.categoryService.ReconcileBookCounts(ctx.Request.Context(), dryRun)
+	if err != nil {
+		logger.Log.Error("校正分类图书数量失败", zap.Error(err))
+		result.Fail(ctx, http.StatusInternalServerError, "校正分类图书数量失败")
+		return
+	}
+	if len(report.Drifts) > 0 {
+		logger.Log.Warn("分类图书数量存在偏差",
+			zap.Bool("dry_run", dryRun),
+			zap.Int("drifts", len(report.Drifts)),
+		)
+	}
+	result.Success(ctx, "校正分类图书数量成功", report)
+}
+
+// failWithCategoryError 根据 service 层错误类型返回对应的 HTTP 状态码
+func failWithCategoryError(ctx *gin.Context, msg string, err error) {
+	switch {
+	case errors.Is(err, service.ErrCategoryNotFound):
+		logger.Log.Warn(msg, zap.Error(err))
+		result.Fail(ctx, http.StatusNotFound, err.Error())
+	case errors.Is(err, service.ErrParentCategoryNotFound), errors.Is(err, service.ErrCategoryCycle):
+		logger.Log.Warn(msg, zap.Error(err))
+		result.Fail(ctx, http.StatusBadRequest, err.Error())
+	case errors.Is(err, service.ErrCategoryHasChildren):
+		logger.Log.Warn(msg, zap.Error(err))
+		result.Fail(ctx, http.StatusConflict, err.Error())
+	default:
+		logger.Log.Warn(msg, zap.Error(err))
+		result.Fail(ctx, http.StatusInternalServerError, msg)
+	}
+}
@@ -0,0 +1,133 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/wangn-tech/bookstore-go/common/result"
+	"github.com/wangn-tech/bookstore-go/internal/api/request"
+	"github.com/wangn-tech/bookstore-go/internal/api/response"
+	"github.com/wangn-tech/bookstore-go/internal/app/constants"
+	"github.com/wangn-tech/bookstore-go/internal/service"
+	"github.com/wangn-tech/bookstore-go/pkg/logger"
+	"go.uber.org/zap"
+)
+
+type FavoriteHandler struct {
+	favoriteService service.IFavoriteService
+}
+
+func NewFavoriteHandler(favoriteService service.IFavoriteService) *FavoriteHandler {
+	return &FavoriteHandler{
+		favoriteService: favoriteService,
+	}
+}
+
+// AddFavorite 收藏图书
+func (f *FavoriteHandler) AddFavorite(ctx *gin.Context) {
+	bookID, err := strconv.ParseUint(ctx.Param("book_id"), 10, 64)
+	if err != nil {
+		logger.Log.Warn("AddFavorite: 请求参数错误", zap.Error(err))
+		result.Fail(ctx, http.StatusBadRequest, "无效的图书 ID")
+		return
+	}
+	userID := ctx.GetUint64(constants.UserID)
+	if userID == 0 {
+		logger.Log.Warn("AddFavorite: 用户ID不存在")
+		result.Fail(ctx, http.StatusUnauthorized, "用户未登录")
+		return
+	}
+	if err := f.favoriteService.AddFavorite(ctx.Request.Context(), userID, bookID); err != nil {
+		if errors.Is(err, service.ErrBookNotFound) {
+			logger.Log.Warn("AddFavorite: 图书不存在", zap.Uint64("bookID", bookID))
+			result.Fail(ctx, http.StatusNotFound, err.Error())
+			return
+		}
+		logger.Log.Error("AddFavorite: 收藏失败", zap.Uint64("userID", userID), zap.Uint64("bookID", bookID), zap.Error(err))
+		result.Fail(ctx, http.StatusInternalServerError, "收藏失败")
+		return
+	}
+	result.Success(ctx, "收藏成功", nil)
+}
+
+// RemoveFavorite 取消收藏
+func (f *FavoriteHandler) RemoveFavorite(ctx *gin.Context) {
+	bookID, err := strconv.ParseUint(ctx.Param("book_id"), 10, 64)
+	if err != nil {
+		logger.Log.Warn("RemoveFavorite: 请求参数错误", zap.Error(err))
+		result.Fail(ctx, http.StatusBadRequest, "无效的图书 ID")
+		return
+	}
+	userID := ctx.GetUint64(constants.UserID)
+	if userID == 0 {
+		logger.Log.Warn("RemoveFavorite: 用户ID不存在")
+		result.Fail(ctx, http.StatusUnauthorized, "用户未登录")
+		return
+	}
+	if err := f.favoriteService.RemoveFavorite(ctx.Request.Context(), userID, bookID); err != nil {
+		if errors.Is(err, service.ErrFavoriteNotFound) {
+			logger.Log.Warn("RemoveFavorite: 未收藏该图书", zap.Uint64("userID", userID), zap.Uint64("bookID", bookID))
+			result.Fail(ctx, http.StatusNotFound, err.Error())
+			return
+		}
+		logger.Log.Error("RemoveFavorite: 取消收藏失败", zap.Uint64("userID", userID), zap.Uint64("bookID", bookID), zap.Error(err))
+		result.Fail(ctx, http.StatusInternalServerError, "取消收藏失败")
+		return
+	}
+	result.Success(ctx, "取消收藏成功", nil)
+}
+
+// GetFavorites 分页获取收藏列表
+func (f *FavoriteHandler) GetFavorites(ctx *gin.Context) {
+	var req request.FavoritesPageDTO
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		logger.Log.Warn("GetFavorites: 请求参数错误", zap.Error(err))
+		result.Fail(ctx, http.StatusBadRequest, "无效的请求参数")
+		return
+	}
+	result.PageVerify(&req.Page, &req.PageSize)
+	userID := ctx.GetUint64(constants.UserID)
+	if userID == 0 {
+		logger.Log.Warn("GetFavorites: 用户ID不存在")
+		result.Fail(ctx, http.StatusUnauthorized, "用户未登录")
+		return
+	}
+	pageResult, err := f.favoriteService.GetUserFavorites(ctx.Request.Context(), userID, &req)
+	if err != nil {
+		logger.Log.Error("GetFavorites: 获取收藏列表失败", zap.Uint64("userID", userID), zap.Error(err))
+		result.Fail(ctx, http.StatusInternalServerError, "获取收藏列表失败")
+		return
+	}
+	result.Success(ctx, "获取收藏列表成功", &response.FavoritesPageVO{
+		Favorites: pageResult.Records,
+		Total:     pageResult.Total,
+		Page:      req.Page,
+		PageSize:  req.PageSize,
+		TotalPage: (pageResult.Total + int64(req.PageSize) - 1) / int64(req.PageSize),
+	})
+}
+
+// CheckFavorite 查询图书是否已收藏
+func (f *FavoriteHandler) CheckFavorite(ctx *gin.Context) {
+	bookID, err := strconv.ParseUint(ctx.Param("book_id"), 10, 64)
+	if err != nil {
+		logger.Log.Warn("CheckFavorite: 请求参数错误", zap.Error(err))
+		result.Fail(ctx, http.StatusBadRequest, "无效的图书 ID")
+		return
+	}
+	userID := ctx.GetUint64(constants.UserID)
+	if userID == 0 {
+		logger.Log.Warn("CheckFavorite: 用户ID不存在")
+		result.Fail(ctx, http.StatusUnauthorized, "用户未登录")
+		return
+	}
+	status, err := f.favoriteService.GetFavoriteStatus(ctx.Request.Context(), userID, bookID)
+	if err != nil {
+		logger.Log.Error("CheckFavorite: 查询收藏状态失败", zap.Uint64("userID", userID), zap.Uint64("bookID", bookID), zap.Error(err))
+		result.Fail(ctx, http.StatusInternalServerError, "查询收藏状态失败")
+		return
+	}
+	result.Success(ctx, "查询收藏状态成功", status)
+}
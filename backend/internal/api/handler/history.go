@@ -0,0 +1,62 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/wangn-tech/bookstore-go/common/result"
+	"github.com/wangn-tech/bookstore-go/internal/app/constants"
+	"github.com/wangn-tech/bookstore-go/internal/service"
+	"github.com/wangn-tech/bookstore-go/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// HistoryHandler 用户浏览历史
+type HistoryHandler struct {
+	historyService service.IViewHistoryService
+}
+
+func NewHistoryHandler(historyService service.IViewHistoryService) *HistoryHandler {
+	return &HistoryHandler{
+		historyService: historyService,
+	}
+}
+
+// GetHistory 获取最近浏览的图书, 按浏览时间倒序, 包含已下架的图书 (以 status 区分)
+func (h *HistoryHandler) GetHistory(ctx *gin.Context) {
+	limit, _ := strconv.Atoi(ctx.DefaultQuery("limit", "20"))
+	if limit <= 0 || limit > service.ViewHistoryMaxSize {
+		limit = service.ViewHistoryMaxSize
+	}
+	userID := ctx.GetUint64(constants.UserID)
+	if userID == 0 {
+		logger.Log.Warn("GetHistory: 用户ID不存在")
+		result.Fail(ctx, http.StatusUnauthorized, "用户未登录")
+		return
+	}
+
+	books, err := h.historyService.GetHistory(ctx.Request.Context(), userID, limit)
+	if err != nil {
+		logger.Log.Error("GetHistory: 获取浏览历史失败", zap.Uint64("userID", userID), zap.Error(err))
+		result.Fail(ctx, http.StatusInternalServerError, "获取浏览历史失败")
+		return
+	}
+	result.Success(ctx, "获取浏览历史成功", books)
+}
+
+// ClearHistory 清空浏览历史
+func (h *HistoryHandler) ClearHistory(ctx *gin.Context) {
+	userID := ctx.GetUint64(constants.UserID)
+	if userID == 0 {
+		logger.Log.Warn("ClearHistory: 用户ID不存在")
+		result.Fail(ctx, http.StatusUnauthorized, "用户未登录")
+		return
+	}
+	if err := h.historyService.ClearHistory(ctx.Request.Context(), userID); err != nil {
+		logger.Log.Error("ClearHistory: 清空浏览历史失败", zap.Uint64("userID", userID), zap.Error(err))
+		result.Fail(ctx, http.StatusInternalServerError, "清空浏览历史失败")
+		return
+	}
+	result.Success(ctx, "清空浏览历史成功", nil)
+}
@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 
@@ -50,10 +51,16 @@ func (o *OrderHandler) PayOrder(ctx *gin.Context) {
 		result.Fail(ctx, http.StatusBadRequest, "无效的订单 ID")
 		return
 	}
-	err = o.orderService.PayOrder(ctx, uint64(id))
+	// 从 context 中获取 userID
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		logger.Log.Warn("PayOrder: 用户ID不存在")
+		result.Fail(ctx, http.StatusUnauthorized, "用户未登录")
+		return
+	}
+	err = o.orderService.PayOrder(ctx, userID.(uint64), uint64(id))
 	if err != nil {
-		logger.Log.Error("PayOrder: 支付订单失败", zap.Error(err))
-		result.Fail(ctx, http.StatusInternalServerError, "支付订单失败")
+		failWithOrderError(ctx, "PayOrder", "支付订单失败", uint64(id), err)
 		return
 	}
 	result.Success(ctx, "支付订单成功", nil)
@@ -67,6 +74,7 @@ func (o *OrderHandler) GetUserOrders(ctx *gin.Context) {
 		result.Fail(ctx, http.StatusBadRequest, "无效的请求参数")
 		return
 	}
+	result.PageVerify(&req.Page, &req.PageSize)
 	// 从 context 中获取 userID
 	userID, exists := ctx.Get("userID")
 	if !exists {
@@ -99,10 +107,16 @@ func (o *OrderHandler) GetOrderByID(ctx *gin.Context) {
 		result.Fail(ctx, http.StatusBadRequest, "无效的订单 ID")
 		return
 	}
-	order, err := o.orderService.GetOrderByID(ctx, uint64(id))
+	// 从 context 中获取 userID
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		logger.Log.Warn("GetOrderByID: 用户ID不存在")
+		result.Fail(ctx, http.StatusUnauthorized, "用户未登录")
+		return
+	}
+	order, err := o.orderService.GetOrderByID(ctx, userID.(uint64), uint64(id))
 	if err != nil {
-		logger.Log.Error("GetOrderByID: 获取订单失败", zap.Error(err))
-		result.Fail(ctx, http.StatusInternalServerError, "获取订单失败")
+		failWithOrderError(ctx, "GetOrderByID", "获取订单失败", uint64(id), err)
 		return
 	}
 	result.Success(ctx, "获取订单成功", order)
@@ -126,9 +140,95 @@ func (o *OrderHandler) CreateOrder(ctx *gin.Context) {
 	req.UserID = userID.(uint64)
 	order, err := o.orderService.CreateOrder(ctx, &req)
 	if err != nil {
-		logger.Log.Error("CreateOrder: 创建订单失败", zap.Error(err))
-		result.Fail(ctx, http.StatusInternalServerError, "创建订单失败")
+		failWithCreateOrderError(ctx, "CreateOrder", req.UserID, err)
 		return
 	}
 	result.Success(ctx, "创建订单成功", order)
 }
+
+// failWithCreateOrderError 根据下单失败的原因返回对应的 HTTP 状态码
+func failWithCreateOrderError(ctx *gin.Context, op string, userID uint64, err error) {
+	switch {
+	case errors.Is(err, service.ErrPriceChanged):
+		logger.Log.Warn(op+": 订单价格与服务端不一致", zap.Uint64("userID", userID), zap.Error(err))
+		result.Fail(ctx, http.StatusConflict, err.Error())
+	case errors.Is(err, service.ErrBookNotFound):
+		logger.Log.Warn(op+": 图书不存在", zap.Uint64("userID", userID), zap.Error(err))
+		result.Fail(ctx, http.StatusNotFound, err.Error())
+	case errors.Is(err, service.ErrBookOffShelf), errors.Is(err, service.ErrStockNotEnough):
+		logger.Log.Warn(op+": 图书不可购买", zap.Uint64("userID", userID), zap.Error(err))
+		result.Fail(ctx, http.StatusConflict, err.Error())
+	default:
+		logger.Log.Error(op+": 创建订单失败", zap.Uint64("userID", userID), zap.Error(err))
+		result.Fail(ctx, http.StatusInternalServerError, "创建订单失败")
+	}
+}
+
+// CancelOrder 买家取消待支付订单
+func (o *OrderHandler) CancelOrder(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		logger.Log.Warn("CancelOrder: 请求参数错误", zap.Error(err))
+		result.Fail(ctx, http.StatusBadRequest, "无效的订单 ID")
+		return
+	}
+	var req request.CancelOrderDTO
+	// 取消原因可选, 允许空请求体
+	if ctx.Request.ContentLength > 0 {
+		if err := ctx.ShouldBindJSON(&req); err != nil {
+			logger.Log.Warn("CancelOrder: 请求参数错误", zap.Error(err))
+			result.Fail(ctx, http.StatusBadRequest, "无效的请求参数")
+			return
+		}
+	}
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		logger.Log.Warn("CancelOrder: 用户ID不存在")
+		result.Fail(ctx, http.StatusUnauthorized, "用户未登录")
+		return
+	}
+
+	if err := o.orderService.CancelOrder(ctx, userID.(uint64), id, req.Reason); err != nil {
+		failWithOrderError(ctx, "CancelOrder", "取消订单失败", id, err)
+		return
+	}
+	result.Success(ctx, "取消订单成功", nil)
+}
+
+// GetOrderStatusHistory 获取订单状态变更记录
+func (o *OrderHandler) GetOrderStatusHistory(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		logger.Log.Warn("GetOrderStatusHistory: 请求参数错误", zap.Error(err))
+		result.Fail(ctx, http.StatusBadRequest, "无效的订单 ID")
+		return
+	}
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		logger.Log.Warn("GetOrderStatusHistory: 用户ID不存在")
+		result.Fail(ctx, http.StatusUnauthorized, "用户未登录")
+		return
+	}
+
+	histories, err := o.orderService.GetOrderStatusHistory(ctx, userID.(uint64), id)
+	if err != nil {
+		failWithOrderError(ctx, "GetOrderStatusHistory", "获取订单状态记录失败", id, err)
+		return
+	}
+	result.Success(ctx, "获取订单状态记录成功", histories)
+}
+
+// failWithOrderError 根据 service 层错误类型返回对应的 HTTP 状态码
+func failWithOrderError(ctx *gin.Context, op, msg string, id uint64, err error) {
+	switch {
+	case errors.Is(err, service.ErrOrderNotFound):
+		logger.Log.Warn(op+": 订单不存在或不属于当前用户", zap.Uint64("id", id))
+		result.Fail(ctx, http.StatusNotFound, "订单不存在")
+	case errors.Is(err, service.ErrInvalidOrderTransition):
+		logger.Log.Warn(op+": 订单状态不允许此操作", zap.Uint64("id", id), zap.Error(err))
+		result.Fail(ctx, http.StatusConflict, err.Error())
+	default:
+		logger.Log.Error(op+": "+msg, zap.Uint64("id", id), zap.Error(err))
+		result.Fail(ctx, http.StatusInternalServerError, msg)
+	}
+}
@@ -0,0 +1,211 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/wangn-tech/bookstore-go/common/result"
+	"github.com/wangn-tech/bookstore-go/internal/api/request"
+	"github.com/wangn-tech/bookstore-go/internal/app/constants"
+	"github.com/wangn-tech/bookstore-go/internal/service"
+	"github.com/wangn-tech/bookstore-go/pkg/logger"
+	"go.uber.org/zap"
+)
+
+type ReviewHandler struct {
+	reviewService service.IReviewService
+}
+
+func NewReviewHandler(reviewService service.IReviewService) *ReviewHandler {
+	return &ReviewHandler{
+		reviewService: reviewService,
+	}
+}
+
+// GetBookReviews 分页获取图书评价, 支持按星级筛选和按最新/最有用排序
+func (r *ReviewHandler) GetBookReviews(ctx *gin.Context) {
+	bookID, err := strconv.ParseUint(ctx.Param("book_id"), 10, 64)
+	if err != nil {
+		logger.Log.Warn("GetBookReviews: 请求参数错误", zap.Error(err))
+		result.Fail(ctx, http.StatusBadRequest, "无效的图书 ID")
+		return
+	}
+	var req request.ReviewsPageDTO
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		logger.Log.Warn("GetBookReviews: 查询参数绑定失败", zap.Error(err))
+		result.Fail(ctx, http.StatusBadRequest, "无效的请求参数")
+		return
+	}
+	userID := ctx.GetUint64(constants.UserID)
+	reviews, err := r.reviewService.GetBookReviews(ctx.Request.Context(), userID, bookID, &req)
+	if err != nil {
+		logger.Log.Error("GetBookReviews: 获取评价列表失败", zap.Uint64("bookID", bookID), zap.Error(err))
+		result.Fail(ctx, http.StatusInternalServerError, "获取评价列表失败")
+		return
+	}
+	result.Success(ctx, "获取评价列表成功", reviews)
+}
+
+// GetRatingSummary 获取图书评分概况
+func (r *ReviewHandler) GetRatingSummary(ctx *gin.Context) {
+	bookID, err := strconv.ParseUint(ctx.Param("book_id"), 10, 64)
+	if err != nil {
+		logger.Log.Warn("GetRatingSummary: 请求参数错误", zap.Error(err))
+		result.Fail(ctx, http.StatusBadRequest, "无效的图书 ID")
+		return
+	}
+	summary, err := r.reviewService.GetRatingSummary(ctx.Request.Context(), bookID)
+	if err != nil {
+		failWithReviewError(ctx, "GetRatingSummary", "获取评分概况失败", bookID, err)
+		return
+	}
+	result.Success(ctx, "获取评分概况成功", summary)
+}
+
+// CreateReview 发表评价
+func (r *ReviewHandler) CreateReview(ctx *gin.Context) {
+	bookID, err := strconv.ParseUint(ctx.Param("book_id"), 10, 64)
+	if err != nil {
+		logger.Log.Warn("CreateReview: 请求参数错误", zap.Error(err))
+		result.Fail(ctx, http.StatusBadRequest, "无效的图书 ID")
+		return
+	}
+	var req request.ReviewDTO
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		logger.Log.Warn("CreateReview: 请求参数错误", zap.Error(err))
+		result.Fail(ctx, http.StatusBadRequest, "请求参数错误")
+		return
+	}
+	userID := ctx.GetUint64(constants.UserID)
+	if userID == 0 {
+		logger.Log.Warn("CreateReview: 用户ID不存在")
+		result.Fail(ctx, http.StatusUnauthorized, "用户未登录")
+		return
+	}
+	review, err := r.reviewService.CreateReview(ctx.Request.Context(), userID, bookID, &req)
+	if err != nil {
+		failWithReviewError(ctx, "CreateReview", "发表评价失败", bookID, err)
+		return
+	}
+	result.Success(ctx, "发表评价成功", review)
+}
+
+// UpdateReview 修改自己的评价
+func (r *ReviewHandler) UpdateReview(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		logger.Log.Warn("UpdateReview: 请求参数错误", zap.Error(err))
+		result.Fail(ctx, http.StatusBadRequest, "无效的评价 ID")
+		return
+	}
+	var req request.ReviewDTO
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		logger.Log.Warn("UpdateReview: 请求参数错误", zap.Error(err))
+		result.Fail(ctx, http.StatusBadRequest, "请求参数错误")
+		return
+	}
+	userID := ctx.GetUint64(constants.UserID)
+	if userID == 0 {
+		logger.Log.Warn("UpdateReview: 用户ID不存在")
+		result.Fail(ctx, http.StatusUnauthorized, "用户未登录")
+		return
+	}
+	review, err := r.reviewService.UpdateReview(ctx.Request.Context(), userID, id, &req)
+	if err != nil {
+		failWithReviewError(ctx, "UpdateReview", "修改评价失败", id, err)
+		return
+	}
+	result.Success(ctx, "修改评价成功", review)
+}
+
+// DeleteReview 删除自己的评价
+func (r *ReviewHandler) DeleteReview(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		logger.Log.Warn("DeleteReview: 请求参数错误", zap.Error(err))
+		result.Fail(ctx, http.StatusBadRequest, "无效的评价 ID")
+		return
+	}
+	userID := ctx.GetUint64(constants.UserID)
+	if userID == 0 {
+		logger.Log.Warn("DeleteReview: 用户ID不存在")
+		result.Fail(ctx, http.StatusUnauthorized, "用户未登录")
+		return
+	}
+	if err := r.reviewService.DeleteReview(ctx.Request.Context(), userID, id); err != nil {
+		failWithReviewError(ctx, "DeleteReview", "删除评价失败", id, err)
+		return
+	}
+	result.Success(ctx, "删除评价成功", nil)
+}
+
+// VoteHelpful 给评价投有用票
+func (r *ReviewHandler) VoteHelpful(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		logger.Log.Warn("VoteHelpful: 请求参数错误", zap.Error(err))
+		result.Fail(ctx, http.StatusBadRequest, "无效的评价 ID")
+		return
+	}
+	userID := ctx.GetUint64(constants.UserID)
+	if userID == 0 {
+		logger.Log.Warn("VoteHelpful: 用户ID不存在")
+		result.Fail(ctx, http.StatusUnauthorized, "用户未登录")
+		return
+	}
+	if err := r.reviewService.VoteHelpful(ctx.Request.Context(), userID, id); err != nil {
+		failWithReviewError(ctx, "VoteHelpful", "投票失败", id, err)
+		return
+	}
+	result.Success(ctx, "投票成功", nil)
+}
+
+// UnvoteHelpful 撤销有用票
+func (r *ReviewHandler) UnvoteHelpful(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		logger.Log.Warn("UnvoteHelpful: 请求参数错误", zap.Error(err))
+		result.Fail(ctx, http.StatusBadRequest, "无效的评价 ID")
+		return
+	}
+	userID := ctx.GetUint64(constants.UserID)
+	if userID == 0 {
+		logger.Log.Warn("UnvoteHelpful: 用户ID不存在")
+		result.Fail(ctx, http.StatusUnauthorized, "用户未登录")
+		return
+	}
+	if err := r.reviewService.UnvoteHelpful(ctx.Request.Context(), userID, id); err != nil {
+		failWithReviewError(ctx, "UnvoteHelpful", "撤销投票失败", id, err)
+		return
+	}
+	result.Success(ctx, "撤销投票成功", nil)
+}
+
+// failWithReviewError 根据 service 层错误类型返回对应的 HTTP 状态码
+func failWithReviewError(ctx *gin.Context, op, msg string, id uint64, err error) {
+	switch {
+	case errors.Is(err, service.ErrBookNotFound):
+		logger.Log.Warn(op+": 图书不存在", zap.Uint64("id", id))
+		result.Fail(ctx, http.StatusNotFound, err.Error())
+	case errors.Is(err, service.ErrReviewNotFound):
+		logger.Log.Warn(op+": 评价不存在", zap.Uint64("id", id))
+		result.Fail(ctx, http.StatusNotFound, err.Error())
+	case errors.Is(err, service.ErrReviewVoteNotFound):
+		logger.Log.Warn(op+": 未给该评价投票", zap.Uint64("id", id))
+		result.Fail(ctx, http.StatusNotFound, err.Error())
+	case errors.Is(err, service.ErrReviewNotPurchased):
+		logger.Log.Warn(op+": 未购买该图书", zap.Uint64("id", id))
+		result.Fail(ctx, http.StatusForbidden, err.Error())
+	case errors.Is(err, service.ErrReviewVoteSelf):
+		logger.Log.Warn(op+": 给自己的评价投票", zap.Uint64("id", id))
+		result.Fail(ctx, http.StatusForbidden, err.Error())
+	case errors.Is(err, service.ErrReviewExists):
+		logger.Log.Warn(op+": 重复评价", zap.Uint64("id", id))
+		result.Fail(ctx, http.StatusConflict, err.Error())
+	default:
+		logger.Log.Error(op+": "+msg, zap.Uint64("id", id), zap.Error(err))
+		result.Fail(ctx, http.StatusInternalServerError, msg)
+	}
+}
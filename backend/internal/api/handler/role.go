@@ -0,0 +1,77 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/wangn-tech/bookstore-go/common/result"
+	"github.com/wangn-tech/bookstore-go/internal/api/request"
+	"github.com/wangn-tech/bookstore-go/internal/service"
+	"github.com/wangn-tech/bookstore-go/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// RoleHandler 角色管理
+type RoleHandler struct {
+	roleService service.IRoleService
+}
+
+// NewRoleHandler 构造函数
+func NewRoleHandler(roleService service.IRoleService) *RoleHandler {
+	return &RoleHandler{
+		roleService: roleService,
+	}
+}
+
+// GetRoles 获取所有角色及其权限
+func (r *RoleHandler) GetRoles(ctx *gin.Context) {
+	roles, err := r.roleService.GetAllRoles(ctx.Request.Context())
+	if err != nil {
+		logger.Log.Error("GetRoles: 获取角色列表失败", zap.Error(err))
+		result.Fail(ctx, http.StatusInternalServerError, "获取角色列表失败")
+		return
+	}
+	result.Success(ctx, "获取角色列表成功", roles)
+}
+
+// GetUserRoles 获取用户的角色
+func (r *RoleHandler) GetUserRoles(ctx *gin.Context) {
+	userID, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		logger.Log.Warn("GetUserRoles: 用户ID无效", zap.String("id", ctx.Param("id")))
+		result.Fail(ctx, http.StatusBadRequest, "用户ID无效")
+		return
+	}
+	roles, err := r.roleService.GetUserRoles(ctx.Request.Context(), userID)
+	if err != nil {
+		logger.Log.Warn("GetUserRoles: 获取用户角色失败", zap.Uint64("userID", userID), zap.Error(err))
+		result.Fail(ctx, http.StatusInternalServerError, "获取用户角色失败")
+		return
+	}
+	result.Success(ctx, "获取用户角色成功", roles)
+}
+
+// SetUserRoles 设置用户的角色
+func (r *RoleHandler) SetUserRoles(ctx *gin.Context) {
+	userID, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		logger.Log.Warn("SetUserRoles: 用户ID无效", zap.String("id", ctx.Param("id")))
+		result.Fail(ctx, http.StatusBadRequest, "用户ID无效")
+		return
+	}
+	var req request.UserRolesDTO
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		logger.Log.Warn("SetUserRoles: 请求参数错误", zap.Error(err))
+		result.Fail(ctx, http.StatusBadRequest, "请求参数错误")
+		return
+	}
+
+	if err := r.roleService.SetUserRoles(ctx.Request.Context(), userID, req.Roles); err != nil {
+		logger.Log.Warn("SetUserRoles: 设置用户角色失败", zap.Uint64("userID", userID), zap.Error(err))
+		result.Fail(ctx, http.StatusInternalServerError, "设置用户角色失败")
+		return
+	}
+	logger.Log.Info("SetUserRoles: 设置用户角色成功", zap.Uint64("userID", userID), zap.Strings("roles", req.Roles))
+	result.Success(ctx, "设置用户角色成功", nil)
+}
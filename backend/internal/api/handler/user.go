@@ -1,13 +1,16 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
 	"github.com/wangn-tech/bookstore-go/common/result"
 	"github.com/wangn-tech/bookstore-go/internal/api/request"
 	"github.com/wangn-tech/bookstore-go/internal/api/response"
+	"github.com/wangn-tech/bookstore-go/internal/app/constants"
 	"github.com/wangn-tech/bookstore-go/internal/service"
+	"github.com/wangn-tech/bookstore-go/internal/utils"
 	"github.com/wangn-tech/bookstore-go/pkg/logger"
 	"go.uber.org/zap"
 )
@@ -76,8 +79,18 @@ func (u *UserHandler) Login(ctx *gin.Context) {
 		return
 	}
 
+	// 记录登录设备信息
+	meta := &utils.SessionMeta{
+		DeviceName: req.DeviceName,
+		IP:         ctx.ClientIP(),
+		UserAgent:  ctx.Request.UserAgent(),
+	}
+	if meta.DeviceName == "" {
+		meta.DeviceName = meta.UserAgent
+	}
+
 	// 调用 service 层登录用户
-	userLoginVO, err := u.userService.Login(ctx.Request.Context(), req.Username, req.Password)
+	userLoginVO, err := u.userService.Login(ctx.Request.Context(), req.Username, req.Password, meta)
 	if err != nil {
 		logger.Log.Warn("Login: 用户登录失败", zap.Error(err))
 		result.Fail(ctx, http.StatusInternalServerError, "用户登录失败")
@@ -88,6 +101,35 @@ func (u *UserHandler) Login(ctx *gin.Context) {
 	result.Success(ctx, "用户登录成功", userLoginVO)
 }
 
+// RefreshToken 刷新 token
+//
+//	// refreshToken 单次有效, 每次刷新返回新的 token 对
+//	// 已轮换的 refreshToken 被再次使用时视为盗用, 撤销整个登录会话
+func (u *UserHandler) RefreshToken(ctx *gin.Context) {
+	var req request.RefreshTokenDTO
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		logger.Log.Warn("RefreshToken: 请求参数绑定失败", zap.Error(err))
+		result.Fail(ctx, http.StatusBadRequest, "请求参数错误")
+		return
+	}
+
+	tokenResponse, err := u.userService.RefreshToken(ctx.Request.Context(), req.RefreshToken)
+	if err != nil {
+		if errors.Is(err, utils.ErrRefreshTokenReused) {
+			logger.Log.Warn("RefreshToken: refresh token 被重复使用，已撤销 token 家族",
+				zap.String("ip", ctx.ClientIP()),
+				zap.String("userAgent", ctx.Request.UserAgent()))
+			result.Fail(ctx, http.StatusUnauthorized, "登录状态异常，请重新登录")
+			return
+		}
+		logger.Log.Warn("RefreshToken: 刷新 token 失败", zap.Error(err))
+		result.Fail(ctx, http.StatusUnauthorized, "refresh token 无效或已过期，请重新登录")
+		return
+	}
+
+	result.Success(ctx, "刷新 token 成功", tokenResponse)
+}
+
 // GetUserProfile 获取用户信息
 func (u *UserHandler) GetUserProfile(ctx *gin.Context) {
 	// 从 context 中获取 userID
@@ -127,7 +169,7 @@ func (u *UserHandler) GetUserProfile(ctx *gin.Context) {
 // Logout 用户登出
 //
 //	// 在 JWT 方案中, 服务端的登出是可选的, 真正的登出由客户端删除 token 实现
-//	// 此处实现: 在 redis 中删除 token, 使其失效
+//	// 此处实现: 在 redis 中删除当前会话, 使其 token 失效, 其他设备不受影响
 func (u *UserHandler) Logout(ctx *gin.Context) {
 	// 从 context 中获取 userID
 	userIDVal, ok := ctx.Get("userID")
@@ -144,7 +186,7 @@ func (u *UserHandler) Logout(ctx *gin.Context) {
 	}
 
 	// 调用 service 层登出用户
-	if err := u.userService.Logout(ctx.Request.Context(), userID); err != nil {
+	if err := u.userService.Logout(ctx.Request.Context(), userID, ctx.GetString(constants.SessionID)); err != nil {
 		logger.Log.Error("Logout: 撤销 token 失败", zap.Uint64("userID", userID), zap.Error(err))
 		result.Fail(ctx, http.StatusInternalServerError, "用户登出失败")
 		return
@@ -155,6 +197,66 @@ func (u *UserHandler) Logout(ctx *gin.Context) {
 	result.Success(ctx, "用户登出成功", nil)
 }
 
+// ListSessions 获取当前用户的登录会话 (设备) 列表
+func (u *UserHandler) ListSessions(ctx *gin.Context) {
+	userID := ctx.GetUint64(constants.UserID)
+	if userID == 0 {
+		logger.Log.Warn("ListSessions: 用户ID不存在")
+		result.Fail(ctx, http.StatusUnauthorized, "用户未登录")
+		return
+	}
+
+	sessions, err := u.userService.ListSessions(ctx.Request.Context(), userID, ctx.GetString(constants.SessionID))
+	if err != nil {
+		logger.Log.Error("ListSessions: 获取会话列表失败", zap.Uint64("userID", userID), zap.Error(err))
+		result.Fail(ctx, http.StatusInternalServerError, "获取会话列表失败")
+		return
+	}
+	result.Success(ctx, "获取会话列表成功", sessions)
+}
+
+// RevokeSession 撤销单个会话 (下线指定设备)
+func (u *UserHandler) RevokeSession(ctx *gin.Context) {
+	userID := ctx.GetUint64(constants.UserID)
+	if userID == 0 {
+		logger.Log.Warn("RevokeSession: 用户ID不存在")
+		result.Fail(ctx, http.StatusUnauthorized, "用户未登录")
+		return
+	}
+
+	sessionID := ctx.Param("id")
+	if err := u.userService.RevokeSession(ctx.Request.Context(), userID, sessionID); err != nil {
+		if errors.Is(err, service.ErrSessionNotFound) {
+			logger.Log.Warn("RevokeSession: 会话不存在", zap.Uint64("userID", userID), zap.String("sessionID", sessionID))
+			result.Fail(ctx, http.StatusNotFound, "会话不存在")
+			return
+		}
+		logger.Log.Error("RevokeSession: 撤销会话失败", zap.Uint64("userID", userID), zap.Error(err))
+		result.Fail(ctx, http.StatusInternalServerError, "撤销会话失败")
+		return
+	}
+	logger.Log.Info("RevokeSession: 撤销会话成功", zap.Uint64("userID", userID), zap.String("sessionID", sessionID))
+	result.Success(ctx, "撤销会话成功", nil)
+}
+
+// RevokeAllSessions 撤销全部会话 (所有设备下线, 包括当前设备)
+func (u *UserHandler) RevokeAllSessions(ctx *gin.Context) {
+	userID := ctx.GetUint64(constants.UserID)
+	if userID == 0 {
+		logger.Log.Warn("RevokeAllSessions: 用户ID不存在")
+		result.Fail(ctx, http.StatusUnauthorized, "用户未登录")
+		return
+	}
+
+	if err := u.userService.RevokeAllSessions(ctx.Request.Context(), userID); err != nil {
+		logger.Log.Error("RevokeAllSessions: 撤销全部会话失败", zap.Uint64("userID", userID), zap.Error(err))
+		result.Fail(ctx, http.StatusInternalServerError, "撤销全部会话失败")
+		return
+	}
+	logger.Log.Info("RevokeAllSessions: 撤销全部会话成功", zap.Uint64("userID", userID))
+	result.Success(ctx, "撤销全部会话成功", nil)
+}
+
 // UpdateUserProfile 更新用户信息
 func (u *UserHandler) UpdateUserProfile(ctx *gin.Context) {
 	// 从 context 中获取 userID
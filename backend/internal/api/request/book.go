@@ -3,4 +3,78 @@ package request
 type BooksPageDTO struct {
 	Page     int `form:"page" json:"page"`           // 当前页码
 	PageSize int `form:"page_size" json:"page_size"` // 每页数量
+
+	// 筛选条件, 均为可选
+	CategoryID      uint64 `form:"category_id" json:"category_id"`                                                     // 分类 ID, 包含全部子分类
+	MinPrice        *int   `form:"min_price" json:"min_price" binding:"omitempty,min=0"`                               // 最低折后价（元）
+	MaxPrice        *int   `form:"max_price" json:"max_price" binding:"omitempty,min=0"`                               // 最高折后价（元）
+	MinDiscount     *int   `form:"min_discount" json:"min_discount" binding:"omitempty,min=0,max=100"`                 // 最低减免百分比, 如 20 表示至少减免 20%
+	Language        string `form:"language" json:"language" binding:"max=20"`                                          // 语言
+	Format          string `form:"format" json:"format" binding:"max=20"`                                              // 装帧格式
+	Publisher       string `form:"publisher" json:"publisher" binding:"max=100"`                                       // 出版社
+	PublishDateFrom string `form:"publish_date_from" json:"publish_date_from" binding:"omitempty,datetime=2006-01-02"` // 出版日期起 (含)
+	PublishDateTo   string `form:"publish_date_to" json:"publish_date_to" binding:"omitempty,datetime=2006-01-02"`     // 出版日期止 (含)
+	InStock         bool   `form:"in_stock" json:"in_stock"`                                                           // 只看有货 (可售库存 > 0)
+	Status          *int   `form:"status" json:"status" binding:"omitempty,oneof=0 1"`                                 // 上下架状态, 仅管理员列表生效
+
+	// 排序: price 折后价, sale 销量, newest 上架时间, rating 评分; order 为 asc 或 desc
+	Sort  string `form:"sort" json:"sort" binding:"omitempty,oneof=price sale newest rating"`
+	Order string `form:"order" json:"order" binding:"omitempty,oneof=asc desc"`
+}
+
+// BookSearchDTO 图书检索请求
+type BookSearchDTO struct {
+	Keyword  string `form:"q" json:"q"`                 // 搜索关键词
+	Page     int    `form:"page" json:"page"`           // 当前页码
+	PageSize int    `form:"page_size" json:"page_size"` // 每页数量
+
+	// 筛选条件, 同时作用于检索结果和分面统计
+	CategoryID uint64 `form:"category_id" json:"category_id"`               // 分类 ID, 包含全部子分类
+	Publisher  string `form:"publisher" json:"publisher" binding:"max=100"` // 出版社
+	Language   string `form:"language" json:"language" binding:"max=20"`    // 语言
+	PriceBand  string `form:"price_band" json:"price_band"`                 // 价格区间, 如 30-60, 100+
+}
+
+// HotBooksDTO 畅销榜请求
+type HotBooksDTO struct {
+	Period     string `form:"period" json:"period" binding:"omitempty,oneof=day week month all"` // 统计周期: day 近 1 天, week 近 7 天, month 近 30 天, all 累计 (默认)
+	CategoryID uint64 `form:"category" json:"category"`                                          // 分类 ID, 包含全部子分类
+	Limit      int    `form:"limit" json:"limit" binding:"omitempty,min=1,max=50"`               // 返回条数, 默认 5
+}
+
+// AdminBookDTO 管理员创建/更新图书请求
+type AdminBookDTO struct {
+	Title       string `json:"title" binding:"required,max=255"`
+	Author      string `json:"author" binding:"max=100"`
+	Series      string `json:"series" binding:"max=100"`         // 丛书/系列名
+	Price       int    `json:"price" binding:"gt=0"`             // 价格（元）
+	Discount    int    `json:"discount" binding:"min=0,max=100"` // 折扣（百分比，0表示无折扣）
+	Type        string `json:"type" binding:"max=50"`            // 图书类型
+	Stock       int    `json:"stock" binding:"min=0"`            // 库存数量
+	Status      *int   `json:"status" binding:"omitempty,oneof=0 1"`
+	Description string `json:"description"`
+	CoverURL    string `json:"cover_url" binding:"max=255"`
+	ISBN        string `json:"isbn" binding:"required"`
+	Publisher   string `json:"publisher" binding:"max=100"`
+	PublishDate string `json:"publish_date" binding:"max=50"`
+	Pages       int    `json:"pages" binding:"min=0"`
+	Language    string `json:"language" binding:"max=20"`
+	Format      string `json:"format" binding:"max=20"`
+	CategoryID  uint64 `json:"category_id" binding:"required"` // 分类ID
+}
+
+// BookStatusDTO 图书上下架请求
+type BookStatusDTO struct {
+	Status *int `json:"status" binding:"required,oneof=0 1"` // 0-下架，1-上架
+}
+
+// BookImportDTO 图书批量导入的查询参数, 目录文件以 multipart 表单的 file 字段上传
+type BookImportDTO struct {
+	Format string `form:"format" binding:"omitempty,oneof=csv jsonl onix"` // 文件格式, 为空时按文件扩展名推断
+	DryRun bool   `form:"dry_run"`                                         // 只校验不写入
+}
+
+// BookExportDTO 图书导出请求
+type BookExportDTO struct {
+	Format string `form:"format" binding:"omitempty,oneof=csv jsonl onix"` // 文件格式, 默认 csv
 }
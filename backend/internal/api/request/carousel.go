@@ -0,0 +1,20 @@
+package request
+
+import "time"
+
+// CarouselDTO 管理员创建/更新轮播图请求
+type CarouselDTO struct {
+	Title       string     `json:"title" binding:"required,max=255"`
+	Description string     `json:"description"`
+	ImageURL    string     `json:"image_url" binding:"required,max=500"`
+	LinkURL     string     `json:"link_url" binding:"max=500"`
+	SortOrder   int        `json:"sort_order" binding:"min=0"` // 排序, 越小越靠前
+	IsActive    *bool      `json:"is_active"`                  // 是否激活, 不传默认激活
+	StartTime   *time.Time `json:"start_time"`                 // 展示开始时间, 为空表示立即生效
+	EndTime     *time.Time `json:"end_time"`                   // 展示结束时间, 为空表示长期有效
+}
+
+// CarouselReorderDTO 轮播图排序请求
+type CarouselReorderDTO struct {
+	IDs []uint64 `json:"ids" binding:"required,min=1"` // 按展示顺序排列的轮播图 ID, 未列出的排在其后
+}
@@ -0,0 +1,17 @@
+package request
+
+// AddCartItemDTO 加入购物车请求
+type AddCartItemDTO struct {
+	BookID   uint64 `json:"book_id" binding:"required"`
+	Quantity int    `json:"quantity" binding:"required,gt=0,max=99"` // 增加的数量
+}
+
+// UpdateCartItemDTO 修改购物车商品数量请求
+type UpdateCartItemDTO struct {
+	Quantity int `json:"quantity" binding:"required,gt=0,max=99"` // 修改后的数量
+}
+
+// CartCheckoutDTO 购物车结算请求
+type CartCheckoutDTO struct {
+	BookIDs []uint64 `json:"book_ids"` // 结算的图书 ID, 为空时结算整个购物车
+}
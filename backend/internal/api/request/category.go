@@ -0,0 +1,6 @@
+package request
+
+// MoveCategoryDTO 移动分类请求
+type MoveCategoryDTO struct {
+	ParentID *uint64 `json:"parent_id"` // 新的父分类 ID, 为空表示移动为顶级分类
+}
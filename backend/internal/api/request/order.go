@@ -2,18 +2,29 @@ package request
 
 // CreateOrderItemDTO 创建订单项请求
 type CreateOrderItemDTO struct {
-	BookID   uint64 `json:"book_id"`
-	Quantity int    `json:"quantity"`
-	Price    int    `json:"price"` // 价格（元）
+	BookID             uint64 `json:"book_id" binding:"required"`
+	Quantity           int    `json:"quantity" binding:"gt=0"`
+	ExpectedPriceCents int    `json:"expected_price_cents" binding:"min=0"` // 客户端展示的成交单价（分），可选；与服务端价格不一致时拒绝下单。旧版客户端的 price（元）不再读取
 }
 
 // CreateOrderDTO 创建订单请求
 type CreateOrderDTO struct {
-	UserID uint64               `json:"user_id" binding:"required"`
-	Items  []CreateOrderItemDTO `json:"items" binding:"required"`
+	UserID uint64               `json:"-"` // 由 JWT 上下文写入, 不接受客户端传入
+	Items  []CreateOrderItemDTO `json:"items" binding:"required,min=1,dive"`
 }
 
 type OrdersPageDTO struct {
 	Page     int `form:"page" json:"page"`           // 当前页码
 	PageSize int `form:"page_size" json:"page_size"` // 每页数量
 }
+
+// CancelOrderDTO 取消订单请求
+type CancelOrderDTO struct {
+	Reason string `json:"reason" binding:"max=255"` // 取消原因, 可选
+}
+
+// OrderTransitionDTO 管理员变更订单状态请求
+type OrderTransitionDTO struct {
+	Status *int   `json:"status" binding:"required,oneof=2 3 4 5 6"` // 目标状态：2-已取消，3-已发货，4-已送达，5-已完成，6-已退款
+	Remark string `json:"remark" binding:"max=255"`                  // 备注, 如物流单号、退款原因
+}
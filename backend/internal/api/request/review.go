@@ -0,0 +1,29 @@
+package request
+
+// ReviewDTO 发表/修改评价请求
+type ReviewDTO struct {
+	Rating  int    `json:"rating" binding:"required,min=1,max=5"` // 评分 1-5 星
+	Content string `json:"content" binding:"max=2000"`            // 评价内容, 可为空
+}
+
+// ReviewsPageDTO 图书评价列表请求
+type ReviewsPageDTO struct {
+	Page     int    `form:"page" json:"page"`                                          // 当前页码
+	PageSize int    `form:"page_size" json:"page_size"`                                // 每页数量
+	Rating   int    `form:"rating" json:"rating" binding:"omitempty,min=1,max=5"`      // 只看某一星级
+	Sort     string `form:"sort" json:"sort" binding:"omitempty,oneof=newest helpful"` // newest 最新, helpful 最有用
+}
+
+// AdminReviewsPageDTO 管理员评价列表请求
+type AdminReviewsPageDTO struct {
+	Page     int    `form:"page" json:"page"`                                     // 当前页码
+	PageSize int    `form:"page_size" json:"page_size"`                           // 每页数量
+	BookID   uint64 `form:"book_id" json:"book_id"`                               // 图书 ID
+	UserID   uint64 `form:"user_id" json:"user_id"`                               // 用户 ID
+	Status   *int   `form:"status" json:"status" binding:"omitempty,oneof=0 1 2"` // 状态：0-待审核，1-已通过，2-已隐藏
+}
+
+// ReviewStatusDTO 管理员审核评价请求
+type ReviewStatusDTO struct {
+	Status *int `json:"status" binding:"required,oneof=1 2"` // 1-通过，2-隐藏
+}
@@ -0,0 +1,6 @@
+package request
+
+// UserRolesDTO 设置用户角色请求
+type UserRolesDTO struct {
+	Roles []string `json:"roles" binding:"required,min=1,dive,required"` // 角色标识列表
+}
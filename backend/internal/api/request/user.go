@@ -17,6 +17,7 @@ type UserLoginDTO struct {
 	Password     string `json:"password" binding:"required"`
 	CaptchaID    string `json:"captcha_id" binding:"required"`
 	CaptchaValue string `json:"captcha_value" binding:"required"`
+	DeviceName   string `json:"device_name" binding:"max=100"` // 设备名称, 为空时使用 User-Agent
 }
 
 type UserProfileDTO struct {
@@ -25,3 +26,8 @@ type UserProfileDTO struct {
 	Phone    string `json:"phone"`
 	Avatar   string `json:"avatar"`
 }
+
+// RefreshTokenDTO 刷新 token 请求结构体
+type RefreshTokenDTO struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
@@ -1,6 +1,9 @@
 package response
 
-import "github.com/wangn-tech/bookstore-go/internal/model"
+import (
+	"github.com/wangn-tech/bookstore-go/internal/model"
+	"github.com/wangn-tech/bookstore-go/internal/search"
+)
 
 type BooksPageVO struct {
 	Books     []*model.Book `json:"books"`      // 一页显示的书籍列表
@@ -9,3 +12,57 @@ type BooksPageVO struct {
 	PageSize  int           `json:"page_size"`  // 每页数量
 	TotalPage int64         `json:"total_page"` // 总页数
 }
+
+// BookDetailVO 图书详情, 包含评分分布和最有用的几条评价
+type BookDetailVO struct {
+	*model.Book
+	RatingDistribution []*RatingBucketVO `json:"rating_distribution"` // 5 星到 1 星的评价数量
+	Reviews            *ReviewsPageVO    `json:"reviews"`             // 第一页评价, 按有用票数排序
+	ViewCount          int64             `json:"view_count"`          // 累计浏览次数 (含本次)
+}
+
+// SuggestionVO 搜索联想词
+type SuggestionVO struct {
+	Text string `json:"text"`
+	Type string `json:"type"` // title, author, series, query
+}
+
+// BookSearchVO 图书检索结果, Books 按相关度排序
+type BookSearchVO struct {
+	BooksPageVO
+	Highlights map[uint64]map[string][]string `json:"highlights"` // 图书 ID -> 字段 -> 高亮片段
+	Facets     *search.Facets                 `json:"facets"`     // 分面统计
+}
+
+// BookImportVO 图书批量导入结果
+type BookImportVO struct {
+	DryRun  bool                 `json:"dry_run"` // 为 true 时只校验, 未写入数据库
+	Total   int                  `json:"total"`   // 读取的记录数
+	Created int                  `json:"created"` // 新建的图书数
+	Updated int                  `json:"updated"` // 按 ISBN 更新的图书数
+	Failed  int                  `json:"failed"`  // 校验或写入失败的记录数
+	Errors  []*BookImportErrorVO `json:"errors"`  // 失败记录的明细, 最多返回前 1000 条
+}
+
+// BookImportErrorVO 导入失败的记录
+type BookImportErrorVO struct {
+	Line    int    `json:"line"` // 记录在文件中的起始行号
+	ISBN    string `json:"isbn"`
+	Message string `json:"message"`
+}
+
+// InvalidISBN 无效或重复的 ISBN
+type InvalidISBN struct {
+	ID     uint64 `json:"id"`
+	Title  string `json:"title"`
+	ISBN   string `json:"isbn"`
+	Reason string `json:"reason"`
+}
+
+// ISBNCheckVO ISBN 检查结果
+type ISBNCheckVO struct {
+	DryRun     bool           `json:"dry_run"`    // 为 true 时只报告, 不写回
+	Checked    int            `json:"checked"`    // 检查的图书数
+	Normalized int            `json:"normalized"` // ISBN 或 ISBN-13 需要更新的图书数
+	Invalid    []*InvalidISBN `json:"invalid"`    // 格式错误、校验位错误或与其他图书重复的 ISBN
+}
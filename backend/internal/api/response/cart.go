@@ -0,0 +1,20 @@
+package response
+
+import "github.com/wangn-tech/bookstore-go/internal/model"
+
+// CartItemVO 购物车商品
+type CartItemVO struct {
+	BookID    uint64      `json:"book_id"`
+	Quantity  int         `json:"quantity"`  // 数量
+	Price     int         `json:"price"`     // 当前成交单价（分）
+	Subtotal  int         `json:"subtotal"`  // 小计（分）
+	Available bool        `json:"available"` // 是否可购买: 已上架且库存充足
+	Book      *model.Book `json:"book"`
+}
+
+// CartVO 购物车
+type CartVO struct {
+	Items         []*CartItemVO `json:"items"`
+	TotalQuantity int           `json:"total_quantity"` // 可购买商品总数量
+	TotalAmount   int           `json:"total_amount"`   // 可购买商品总金额（分）
+}
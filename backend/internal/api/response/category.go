@@ -0,0 +1,31 @@
+package response
+
+import "github.com/wangn-tech/bookstore-go/internal/model"
+
+// CategoryCrumb 面包屑导航中的一级分类
+type CategoryCrumb struct {
+	ID   uint64 `json:"id"`
+	Name string `json:"name"`
+}
+
+// CategoryNodeVO 分类树节点
+type CategoryNodeVO struct {
+	*model.Category
+	Breadcrumbs []CategoryCrumb   `json:"breadcrumbs"` // 从顶级分类到当前分类的路径
+	Children    []*CategoryNodeVO `json:"children"`
+}
+
+// CategoryCountDrift 分类图书数量偏差
+type CategoryCountDrift struct {
+	ID     uint64 `json:"id"`
+	Name   string `json:"name"`
+	Stored int    `json:"stored"` // 分类表中记录的数量
+	Actual int    `json:"actual"` // 实际上架图书数量
+}
+
+// CategoryCountReconcileVO 分类图书数量校正结果
+type CategoryCountReconcileVO struct {
+	DryRun  bool                  `json:"dry_run"` // 为 true 时只报告偏差, 不写回
+	Checked int                   `json:"checked"` // 检查的分类数
+	Drifts  []*CategoryCountDrift `json:"drifts"`  // 存在偏差的分类
+}
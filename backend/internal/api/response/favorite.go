@@ -0,0 +1,18 @@
+package response
+
+import "github.com/wangn-tech/bookstore-go/internal/model"
+
+type FavoritesPageVO struct {
+	Favorites []*model.Favorite `json:"favorites"`  // 一页显示的收藏列表
+	Total     int64             `json:"total"`      // 总数量
+	Page      int               `json:"page"`       // 当前页码
+	PageSize  int               `json:"page_size"`  // 每页数量
+	TotalPage int64             `json:"total_page"` // 总页数
+}
+
+// FavoriteStatusVO 图书收藏状态
+type FavoriteStatusVO struct {
+	BookID        uint64 `json:"book_id"`
+	IsFavorited   bool   `json:"is_favorited"`   // 当前用户是否已收藏
+	FavoriteCount int64  `json:"favorite_count"` // 收藏数
+}
@@ -0,0 +1,58 @@
+package response
+
+import (
+	"time"
+
+	"github.com/wangn-tech/bookstore-go/internal/model"
+)
+
+// ReviewerVO 评价用户的公开信息
+type ReviewerVO struct {
+	ID       uint64 `json:"id"`
+	Username string `json:"username"`
+	Avatar   string `json:"avatar"`
+}
+
+// ReviewVO 图书评价
+type ReviewVO struct {
+	ID           uint64      `json:"id"`
+	BookID       uint64      `json:"book_id"`
+	Rating       int         `json:"rating"`
+	Content      string      `json:"content"`
+	HelpfulCount int         `json:"helpful_count"`
+	Status       int         `json:"status"`
+	IsVoted      bool        `json:"is_voted"` // 当前用户是否已投有用票
+	User         *ReviewerVO `json:"user"`
+	CreatedAt    time.Time   `json:"created_at"`
+	UpdatedAt    time.Time   `json:"updated_at"`
+}
+
+type ReviewsPageVO struct {
+	Reviews   []*ReviewVO `json:"reviews"`    // 一页显示的评价列表
+	Total     int64       `json:"total"`      // 总数量
+	Page      int         `json:"page"`       // 当前页码
+	PageSize  int         `json:"page_size"`  // 每页数量
+	TotalPage int64       `json:"total_page"` // 总页数
+}
+
+// AdminReviewsPageVO 管理员评价列表, 包含评价的图书
+type AdminReviewsPageVO struct {
+	Reviews   []*model.Review `json:"reviews"`    // 一页显示的评价列表
+	Total     int64           `json:"total"`      // 总数量
+	Page      int             `json:"page"`       // 当前页码
+	PageSize  int             `json:"page_size"`  // 每页数量
+	TotalPage int64           `json:"total_page"` // 总页数
+}
+
+// RatingBucketVO 某一星级的评价数量
+type RatingBucketVO struct {
+	Rating int   `json:"rating"`
+	Count  int64 `json:"count"`
+}
+
+// RatingSummaryVO 图书评分概况
+type RatingSummaryVO struct {
+	RatingAvg    float64           `json:"rating_avg"`   // 平均评分
+	RatingCount  int               `json:"rating_count"` // 评分人数
+	Distribution []*RatingBucketVO `json:"distribution"` // 5 星到 1 星的评价数量
+}
@@ -1,10 +1,11 @@
 package response
 
 type UserInfo struct {
-	ID       uint64 `json:"id"`
-	Username string `json:"username"`
-	Email    string `json:"email"`
-	Phone    string `json:"phone"`
+	ID       uint64   `json:"id"`
+	Username string   `json:"username"`
+	Email    string   `json:"email"`
+	Phone    string   `json:"phone"`
+	Roles    []string `json:"roles"`
 }
 
 type UserLoginVO struct {
@@ -23,3 +24,14 @@ type UserProfileVO struct {
 	CreatedAt string `json:"created_at"`
 	UpdatedAt string `json:"updated_at"`
 }
+
+// SessionVO 登录会话
+type SessionVO struct {
+	ID         string `json:"id"`
+	DeviceName string `json:"device_name"`
+	IP         string `json:"ip"`
+	UserAgent  string `json:"user_agent"`
+	CreatedAt  string `json:"created_at"`
+	LastSeen   string `json:"last_seen"`
+	Current    bool   `json:"current"` // 是否为当前请求所在会话
+}
@@ -5,17 +5,21 @@ import (
 	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
 	"log"
+	"time"
 )
 
 var AppConf *Config
 
 // Config 是应用程序的主配置结构体
 type Config struct {
-	Server ServerConfig   `mapstructure:"server"`
-	MySQL  DatabaseConfig `mapstructure:"mysql"`
-	Redis  RedisConfig    `mapstructure:"redis"`
-	JWT    JWTConfig      `mapstructure:"jwt"`
-	Log    LogConfig      `mapstructure:"log"`
+	Server    ServerConfig    `mapstructure:"server"`
+	MySQL     DatabaseConfig  `mapstructure:"mysql"`
+	Redis     RedisConfig     `mapstructure:"redis"`
+	JWT       JWTConfig       `mapstructure:"jwt"`
+	Log       LogConfig       `mapstructure:"log"`
+	Order     OrderConfig     `mapstructure:"order"`
+	Search    SearchConfig    `mapstructure:"search"`
+	Recommend RecommendConfig `mapstructure:"recommend"`
 }
 
 // ServerConfig 后端服务端口配置
@@ -47,6 +51,23 @@ type JWTConfig struct {
 	Secret string `mapstructure:"secret"`
 }
 
+// OrderConfig 订单配置
+type OrderConfig struct {
+	PendingTTL     time.Duration `mapstructure:"pending_ttl"`     // 待支付订单超时时间, 超时后自动取消, 例如: 30m
+	CancelInterval time.Duration `mapstructure:"cancel_interval"` // 超时订单扫描间隔, 例如: 1m
+}
+
+// SearchConfig 图书检索配置
+type SearchConfig struct {
+	Engine    string `mapstructure:"engine"`     // 检索引擎: mysql (FULLTEXT ngram), bleve (嵌入式索引)
+	BlevePath string `mapstructure:"bleve_path"` // Bleve 索引目录, 例如: ./data/bleve
+}
+
+// RecommendConfig 图书推荐配置
+type RecommendConfig struct {
+	RebuildInterval time.Duration `mapstructure:"rebuild_interval"` // 共同购买相似度重建间隔, 例如: 1h
+}
+
 // LogConfig 定义了日志的配置参数
 type LogConfig struct {
 	Level      string `mapstructure:"level"`      // 日志级别, 例如: debug, info, warn, error
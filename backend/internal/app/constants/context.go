@@ -1,6 +1,9 @@
 package constants
 
 const (
-	UserID   = "userID"
-	Username = "username"
+	UserID      = "userID"
+	Username    = "username"
+	SessionID   = "sessionID"
+	Roles       = "roles"
+	Permissions = "permissions"
 )
@@ -0,0 +1,17 @@
+package constants
+
+// 角色
+const (
+	RoleCustomer = "customer" // 普通用户
+	RoleAdmin    = "admin"    // 管理员
+)
+
+// 权限, 格式 资源:操作
+const (
+	PermBookManage     = "book:manage"     // 图书管理
+	PermCategoryManage = "category:manage" // 分类管理
+	PermOrderManage    = "order:manage"    // 订单管理
+	PermRoleManage     = "role:manage"     // 角色分配
+	PermCarouselManage = "carousel:manage" // 轮播图管理
+	PermReviewManage   = "review:manage"   // 评价审核
+)
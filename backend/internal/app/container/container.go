@@ -0,0 +1,96 @@
+package container
+
+import (
+	"github.com/wangn-tech/bookstore-go/internal/app/config"
+	"github.com/wangn-tech/bookstore-go/internal/repository"
+	"github.com/wangn-tech/bookstore-go/internal/search"
+	"github.com/wangn-tech/bookstore-go/internal/service"
+	"github.com/wangn-tech/bookstore-go/pkg/logger"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// Container 应用依赖容器
+//
+//	// 启动时统一构造全部 DAO 和 Service, 再注入到路由和后台任务, 同一实例在各处共享
+//	// 测试时可直接构造 Container 并替换其中的 Service 实现
+type Container struct {
+	// DAO
+	UserDao      *repository.UserDao
+	RoleDao      *repository.RoleDao
+	BookDao      *repository.BookDao
+	CategoryDao  *repository.CategoryDao
+	OrderDao     *repository.OrderDao
+	CartDao      *repository.CartDao
+	FavoriteDao  *repository.FavoriteDao
+	CarouselDao  *repository.CarouselDao
+	ReviewDao    *repository.ReviewDao
+	RecommendDao *repository.RecommendDao
+
+	// 图书检索引擎
+	SearchEngine search.Engine
+
+	// Service
+	UserService      service.IUserService
+	CaptchaService   service.ICaptchaService
+	RoleService      service.IRoleService
+	BookService      service.IBookService
+	CategoryService  service.ICategoryService
+	OrderService     service.IOrderService
+	CartService      service.ICartService
+	FavoriteService  service.IFavoriteService
+	CarouselService  service.ICarouselService
+	SuggestService   service.ISuggestService
+	ReviewService    service.IReviewService
+	RecommendService service.IRecommendService
+	RankingService   service.IBookRankingService
+	HistoryService   service.IViewHistoryService
+}
+
+// NewContainer 根据数据库连接构造全部依赖
+func NewContainer(db *gorm.DB) *Container {
+	c := &Container{
+		UserDao:      repository.NewUserDao(db),
+		RoleDao:      repository.NewRoleDao(db),
+		BookDao:      repository.NewBookDao(db),
+		CategoryDao:  repository.NewCategoryDao(db),
+		OrderDao:     repository.NewOrderDao(db),
+		CartDao:      repository.NewCartDao(db),
+		FavoriteDao:  repository.NewFavoriteDao(db),
+		CarouselDao:  repository.NewCarouselDao(db),
+		ReviewDao:    repository.NewReviewDao(db),
+		RecommendDao: repository.NewRecommendDao(db),
+	}
+
+	c.UserService = service.NewUserService(c.UserDao, c.RoleDao)
+	c.CaptchaService = service.NewCaptchaService()
+	c.RoleService = service.NewRoleService(c.RoleDao, c.UserDao)
+	c.SearchEngine = newSearchEngine(db)
+
+	c.SuggestService = service.NewSuggestService(c.BookDao)
+	c.BookService = service.NewBookService(c.BookDao, c.CategoryDao, c.SearchEngine, c.SuggestService)
+	c.CategoryService = service.NewCategoryService(c.CategoryDao)
+	c.RankingService = service.NewBookRankingService(c.BookDao, c.CategoryDao, c.OrderDao)
+	c.OrderService = service.NewOrderService(c.OrderDao, c.BookDao, c.RankingService, c.SuggestService)
+	c.HistoryService = service.NewViewHistoryService(c.BookDao)
+	c.CartService = service.NewCartService(c.CartDao, c.BookDao, c.OrderService)
+	c.FavoriteService = service.NewFavoriteService(c.FavoriteDao, c.BookDao)
+	c.CarouselService = service.NewCarouselService(c.CarouselDao)
+	c.ReviewService = service.NewReviewService(c.ReviewDao, c.BookDao, c.OrderDao)
+	c.RecommendService = service.NewRecommendService(c.RecommendDao, c.BookDao, c.OrderDao, c.FavoriteDao)
+	return c
+}
+
+// newSearchEngine 按配置创建检索引擎, 未加载配置或创建失败时使用 MySQL 全文检索
+func newSearchEngine(db *gorm.DB) search.Engine {
+	if config.AppConf == nil {
+		return search.NewMySQLEngine(db)
+	}
+	conf := config.AppConf.Search
+	engine, err := search.NewEngine(search.Config{Engine: conf.Engine, BlevePath: conf.BlevePath}, db)
+	if err != nil {
+		logger.Log.Error("newSearchEngine: 创建检索引擎失败, 改用 MySQL 全文检索", zap.String("engine", conf.Engine), zap.Error(err))
+		return search.NewMySQLEngine(db)
+	}
+	return engine
+}
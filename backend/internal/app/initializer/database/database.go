@@ -36,7 +36,8 @@ func InitDB() {
 		DontSupportRenameColumn:   true,  // 用 `change` 重命名列，MySQL 8 之前的数据库和 MariaDB 不支持重命名列
 		SkipInitializeWithVersion: false, // 根据版本自动配置
 	}), &gorm.Config{
-		Logger: ormLogger,
+		Logger:         ormLogger,
+		TranslateError: true, // 将唯一索引冲突等驱动错误转换为 gorm.ErrDuplicatedKey 等通用错误
 	})
 	if err != nil {
 		panic(fmt.Sprintf("Failed to connect to database: %v", err))
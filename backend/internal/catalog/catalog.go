@@ -0,0 +1,203 @@
+// Package catalog 图书目录的批量导入导出格式
+//
+//	// 支持 CSV、JSON Lines 和 ONIX 3.0 (reference 标签) 三种格式, 各格式读写同一个 Record 结构
+//	// Record 中为 nil 的字段表示文件中未提供, 导入时保留图书原值 (新建时使用默认值)
+package catalog
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/wangn-tech/bookstore-go/internal/model"
+)
+
+// 目录文件格式
+const (
+	FormatCSV   = "csv"
+	FormatJSONL = "jsonl"
+	FormatONIX  = "onix"
+)
+
+// ErrUnknownFormat 不支持的文件格式
+var ErrUnknownFormat = errors.New("不支持的文件格式, 可选 csv、jsonl、onix")
+
+// Record 目录中的一条图书记录, 以 ISBN 标识
+type Record struct {
+	Line int `json:"-"` // 记录在文件中的起始行号, 用于报告错误
+
+	ISBN        string  `json:"isbn"`
+	Title       *string `json:"title,omitempty"`
+	Author      *string `json:"author,omitempty"`
+	Series      *string `json:"series,omitempty"`
+	Price       *int    `json:"price,omitempty"`    // 价格（元）
+	Discount    *int    `json:"discount,omitempty"` // 减免百分比
+	Type        *string `json:"type,omitempty"`
+	Stock       *int    `json:"stock,omitempty"`  // 在库库存
+	Status      *int    `json:"status,omitempty"` // 0-下架, 1-上架
+	Description *string `json:"description,omitempty"`
+	CoverURL    *string `json:"cover_url,omitempty"`
+	Publisher   *string `json:"publisher,omitempty"`
+	PublishDate *string `json:"publish_date,omitempty"`
+	Pages       *int    `json:"pages,omitempty"`
+	Language    *string `json:"language,omitempty"`
+	Format      *string `json:"format,omitempty"`      // 装帧格式
+	CategoryID  *uint64 `json:"category_id,omitempty"` // 分类 ID, 优先于分类名称
+	Category    *string `json:"category,omitempty"`    // 分类名称, 未提供分类 ID 时按名称匹配
+}
+
+// Apply 将记录中提供的字段写入图书, ISBN 和分类由调用方处理
+func (r *Record) Apply(book *model.Book) {
+	setString := func(dst *string, src *string) {
+		if src != nil {
+			*dst = strings.TrimSpace(*src)
+		}
+	}
+	setInt := func(dst *int, src *int) {
+		if src != nil {
+			*dst = *src
+		}
+	}
+	setString(&book.Title, r.Title)
+	setString(&book.Author, r.Author)
+	setString(&book.Series, r.Series)
+	setInt(&book.Price, r.Price)
+	setInt(&book.Discount, r.Discount)
+	setString(&book.Type, r.Type)
+	setInt(&book.Stock, r.Stock)
+	setInt(&book.Status, r.Status)
+	setString(&book.Description, r.Description)
+	setString(&book.CoverURL, r.CoverURL)
+	setString(&book.Publisher, r.Publisher)
+	setString(&book.PublishDate, r.PublishDate)
+	setInt(&book.Pages, r.Pages)
+	setString(&book.Language, r.Language)
+	setString(&book.Format, r.Format)
+}
+
+// FromBook 由图书生成导出记录, categoryName 为图书所属分类的名称
+func FromBook(book *model.Book, categoryName string) *Record {
+	r := &Record{
+		ISBN:        book.ISBN,
+		Title:       &book.Title,
+		Author:      &book.Author,
+		Series:      &book.Series,
+		Price:       &book.Price,
+		Discount:    &book.Discount,
+		Type:        &book.Type,
+		Stock:       &book.Stock,
+		Status:      &book.Status,
+		Description: &book.Description,
+		CoverURL:    &book.CoverURL,
+		Publisher:   &book.Publisher,
+		PublishDate: &book.PublishDate,
+		Pages:       &book.Pages,
+		Language:    &book.Language,
+		Format:      &book.Format,
+	}
+	if book.CategoryID != 0 {
+		r.CategoryID = &book.CategoryID
+		r.Category = &categoryName
+	}
+	return r
+}
+
+// RowError 单条记录的错误, 读取方可以跳过该记录继续读取
+type RowError struct {
+	Line int
+	Err  error
+}
+
+func (e *RowError) Error() string {
+	return fmt.Sprintf("第 %d 行: %v", e.Line, e.Err)
+}
+
+func (e *RowError) Unwrap() error {
+	return e.Err
+}
+
+// Reader 逐条读取目录记录
+type Reader interface {
+	// Read 返回下一条记录, 读完时返回 io.EOF; 单条记录格式错误时返回 *RowError, 可继续读取
+	Read() (*Record, error)
+}
+
+// Writer 逐条写出目录记录
+type Writer interface {
+	Write(r *Record) error
+	// Close 写出缓冲区和文件尾, 不关闭底层 io.Writer
+	Close() error
+}
+
+// NewReader 按格式创建读取器
+func NewReader(format string, r io.Reader) (Reader, error) {
+	switch format {
+	case FormatCSV:
+		return newCSVReader(r)
+	case FormatJSONL:
+		return newJSONLReader(r), nil
+	case FormatONIX:
+		return newONIXReader(r), nil
+	default:
+		return nil, ErrUnknownFormat
+	}
+}
+
+// NewWriter 按格式创建写出器
+func NewWriter(format string, w io.Writer) (Writer, error) {
+	switch format {
+	case FormatCSV:
+		return newCSVWriter(w)
+	case FormatJSONL:
+		return newJSONLWriter(w), nil
+	case FormatONIX:
+		return newONIXWriter(w)
+	default:
+		return nil, ErrUnknownFormat
+	}
+}
+
+// ParseFormat 校验格式名, format 为空时按文件扩展名推断
+func ParseFormat(format string, filename string) (string, error) {
+	format = strings.ToLower(strings.TrimSpace(format))
+	if format == "" {
+		switch strings.ToLower(filepath.Ext(filename)) {
+		case ".csv":
+			format = FormatCSV
+		case ".jsonl", ".ndjson":
+			format = FormatJSONL
+		case ".xml", ".onix":
+			format = FormatONIX
+		}
+	}
+	switch format {
+	case FormatCSV, FormatJSONL, FormatONIX:
+		return format, nil
+	default:
+		return "", ErrUnknownFormat
+	}
+}
+
+// ContentType 导出文件的 Content-Type
+func ContentType(format string) string {
+	switch format {
+	case FormatCSV:
+		return "text/csv; charset=utf-8"
+	case FormatJSONL:
+		return "application/x-ndjson; charset=utf-8"
+	case FormatONIX:
+		return "application/xml; charset=utf-8"
+	default:
+		return "application/octet-stream"
+	}
+}
+
+// FileExt 导出文件的扩展名
+func FileExt(format string) string {
+	if format == FormatONIX {
+		return ".xml"
+	}
+	return "." + format
+}
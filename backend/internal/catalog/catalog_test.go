@@ -0,0 +1,232 @@
+package catalog
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/wangn-tech/bookstore-go/internal/model"
+)
+
+// testBooks 覆盖各格式的特殊情况: 转义字符、多行描述、零折扣、下架、无系列
+var testBooks = []*model.Book{
+	{
+		ISBN:        "9787536692930",
+		Title:       "三体",
+		Author:      "刘慈欣",
+		Series:      "地球往事",
+		Price:       59,
+		Discount:    20,
+		Type:        "科幻",
+		Stock:       100,
+		Status:      1,
+		Description: "地球文明与三体文明的星际战争, \"黑暗森林\" <法则> & 猜疑链\n第二行",
+		CoverURL:    "https://example.com/cover.jpg?w=300&h=400",
+		Publisher:   "重庆出版社",
+		PublishDate: "2008-01-01",
+		Pages:       302,
+		Language:    "中文",
+		Format:      "平装",
+		CategoryID:  1,
+	},
+	{
+		ISBN:        "9780306406157",
+		Title:       "The Art of Computer Programming, Vol. 1",
+		Author:      "Donald E. Knuth",
+		Price:       128,
+		Discount:    0,
+		Type:        "计算机",
+		Stock:       0,
+		Status:      0,
+		PublishDate: "1997-07",
+		Pages:       672,
+		Language:    "英文",
+		Format:      "精装",
+		CategoryID:  2,
+	},
+}
+
+var testCategoryNames = map[uint64]string{1: "科幻", 2: "计算机"}
+
+func writeBooks(t *testing.T, format string, books []*model.Book) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	w, err := NewWriter(format, &buf)
+	if err != nil {
+		t.Fatalf("NewWriter(%s): %v", format, err)
+	}
+	for _, book := range books {
+		if err := w.Write(FromBook(book, testCategoryNames[book.CategoryID])); err != nil {
+			t.Fatalf("Write(%s): %v", format, err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close(%s): %v", format, err)
+	}
+	return buf.Bytes()
+}
+
+func readAll(t *testing.T, format string, data []byte) ([]*Record, []*RowError) {
+	t.Helper()
+	r, err := NewReader(format, bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("NewReader(%s): %v", format, err)
+	}
+	var records []*Record
+	var rowErrs []*RowError
+	for {
+		record, err := r.Read()
+		if errors.Is(err, io.EOF) {
+			return records, rowErrs
+		}
+		var rowErr *RowError
+		if errors.As(err, &rowErr) {
+			rowErrs = append(rowErrs, rowErr)
+			continue
+		}
+		if err != nil {
+			t.Fatalf("Read(%s): %v", format, err)
+		}
+		records = append(records, record)
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	for _, format := range []string{FormatCSV, FormatJSONL, FormatONIX} {
+		t.Run(format, func(t *testing.T) {
+			records, rowErrs := readAll(t, format, writeBooks(t, format, testBooks))
+			if len(rowErrs) > 0 {
+				t.Fatalf("unexpected row errors: %v", rowErrs)
+			}
+			if len(records) != len(testBooks) {
+				t.Fatalf("read %d records, want %d", len(records), len(testBooks))
+			}
+			for i, record := range records {
+				want := testBooks[i]
+				if record.ISBN != want.ISBN {
+					t.Errorf("record %d: isbn = %q, want %q", i, record.ISBN, want.ISBN)
+				}
+				if record.CategoryID == nil || *record.CategoryID != want.CategoryID {
+					t.Errorf("record %d: category_id = %v, want %d", i, record.CategoryID, want.CategoryID)
+				}
+				if record.Category == nil || *record.Category != testCategoryNames[want.CategoryID] {
+					t.Errorf("record %d: category = %v, want %q", i, record.Category, testCategoryNames[want.CategoryID])
+				}
+
+				got := &model.Book{ISBN: record.ISBN, CategoryID: want.CategoryID}
+				record.Apply(got)
+				if !reflect.DeepEqual(got, want) {
+					t.Errorf("record %d: applied book = %+v, want %+v", i, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestApplyKeepsUnsetFields(t *testing.T) {
+	book := *testBooks[0]
+	title := "  三体 II  "
+	stock := 5
+	(&Record{Title: &title, Stock: &stock}).Apply(&book)
+
+	want := *testBooks[0]
+	want.Title = "三体 II"
+	want.Stock = 5
+	if !reflect.DeepEqual(book, want) {
+		t.Errorf("Apply = %+v, want %+v", book, want)
+	}
+}
+
+func TestRowErrorLines(t *testing.T) {
+	tests := []struct {
+		format    string
+		data      string
+		wantLines []int
+		wantRead  int
+	}{
+		{
+			format: FormatCSV,
+			data: "isbn,title,price\n" +
+				"9787536692930,三体,59\n" +
+				"9780306406157,TAOCP,abc\n" +
+				"9787532776771,银河帝国\n" +
+				"9787544253994,\"多行\n书名\",30\n",
+			wantLines: []int{3, 4},
+			wantRead:  2,
+		},
+		{
+			format: FormatJSONL,
+			data: `{"isbn":"9787536692930","title":"三体"}` + "\n" +
+				"\n" +
+				`{"isbn":"9780306406157","title":` + "\n" +
+				`{"isbn":"9787532776771","unknown":1}` + "\n" +
+				`{"isbn":"9787544253994"}` + "\n",
+			wantLines: []int{3, 4},
+			wantRead:  2,
+		},
+		{
+			format: FormatONIX,
+			data: `<?xml version="1.0" encoding="UTF-8"?>` + "\n" +
+				`<ONIXMessage release="3.0">` + "\n" +
+				`<Product><ProductIdentifier><ProductIDType>15</ProductIDType><IDValue>9787536692930</IDValue></ProductIdentifier></Product>` + "\n" +
+				`<Product><RecordReference>no-isbn</RecordReference></Product>` + "\n" +
+				`<Product><ProductIdentifier><ProductIDType>02</ProductIDType><IDValue>0306406152</IDValue></ProductIdentifier></Product>` + "\n" +
+				`</ONIXMessage>` + "\n",
+			wantLines: []int{4},
+			wantRead:  2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			records, rowErrs := readAll(t, tt.format, []byte(tt.data))
+			if len(records) != tt.wantRead {
+				t.Errorf("read %d records, want %d", len(records), tt.wantRead)
+			}
+			lines := make([]int, 0, len(rowErrs))
+			for _, rowErr := range rowErrs {
+				lines = append(lines, rowErr.Line)
+				if !strings.HasPrefix(rowErr.Error(), "第 ") {
+					t.Errorf("RowError message %q does not start with line number", rowErr.Error())
+				}
+			}
+			if !reflect.DeepEqual(lines, tt.wantLines) {
+				t.Errorf("row error lines = %v, want %v", lines, tt.wantLines)
+			}
+		})
+	}
+}
+
+func TestCSVHeaderErrors(t *testing.T) {
+	for _, data := range []string{"", "title,price\n", "isbn,unknown\n"} {
+		if _, err := NewReader(FormatCSV, strings.NewReader(data)); err == nil {
+			t.Errorf("NewReader(csv, %q) succeeded, want header error", data)
+		}
+	}
+	// Excel 保存的 BOM 不影响表头识别
+	if _, err := NewReader(FormatCSV, strings.NewReader(utf8BOM+"ISBN,Title\n")); err != nil {
+		t.Errorf("NewReader(csv) with BOM: %v", err)
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	tests := []struct {
+		format, filename, want string
+		wantErr                bool
+	}{
+		{"", "books.csv", FormatCSV, false},
+		{"", "books.NDJSON", FormatJSONL, false},
+		{"", "books.xml", FormatONIX, false},
+		{"JSONL", "books.csv", FormatJSONL, false},
+		{"", "books.txt", "", true},
+		{"xlsx", "", "", true},
+	}
+	for _, tt := range tests {
+		got, err := ParseFormat(tt.format, tt.filename)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ParseFormat(%q, %q) = %q, %v", tt.format, tt.filename, got, err)
+		}
+	}
+}
@@ -0,0 +1,188 @@
+package catalog
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// utf8BOM Excel 保存的 CSV 带有 BOM, 导出时也写入 BOM 以便 Excel 正确识别中文
+const utf8BOM = "\ufeff"
+
+// csvColumns CSV 列名, 与 Record 的 JSON 字段名一致; 导入时列的顺序任意, 只有 isbn 为必需列
+var csvColumns = []string{
+	"isbn", "title", "author", "series", "price", "discount", "type", "stock", "status",
+	"category_id", "category", "publisher", "publish_date", "pages", "language", "format",
+	"cover_url", "description",
+}
+
+// csvField 读写 Record 中的一列
+type csvField struct {
+	get func(r *Record) string
+	set func(r *Record, value string) error
+}
+
+func stringField(field func(r *Record) **string) csvField {
+	return csvField{
+		get: func(r *Record) string {
+			if p := *field(r); p != nil {
+				return *p
+			}
+			return ""
+		},
+		set: func(r *Record, value string) error {
+			*field(r) = &value
+			return nil
+		},
+	}
+}
+
+func intField(field func(r *Record) **int) csvField {
+	return csvField{
+		get: func(r *Record) string {
+			if p := *field(r); p != nil {
+				return strconv.Itoa(*p)
+			}
+			return ""
+		},
+		set: func(r *Record, value string) error {
+			n, err := strconv.Atoi(value)
+			if err != nil {
+				return fmt.Errorf("不是有效的整数: %q", value)
+			}
+			*field(r) = &n
+			return nil
+		},
+	}
+}
+
+var csvFields = map[string]csvField{
+	"isbn": {
+		get: func(r *Record) string { return r.ISBN },
+		set: func(r *Record, value string) error { r.ISBN = value; return nil },
+	},
+	"title":    stringField(func(r *Record) **string { return &r.Title }),
+	"author":   stringField(func(r *Record) **string { return &r.Author }),
+	"series":   stringField(func(r *Record) **string { return &r.Series }),
+	"price":    intField(func(r *Record) **int { return &r.Price }),
+	"discount": intField(func(r *Record) **int { return &r.Discount }),
+	"type":     stringField(func(r *Record) **string { return &r.Type }),
+	"stock":    intField(func(r *Record) **int { return &r.Stock }),
+	"status":   intField(func(r *Record) **int { return &r.Status }),
+	"category_id": {
+		get: func(r *Record) string {
+			if r.CategoryID != nil {
+				return strconv.FormatUint(*r.CategoryID, 10)
+			}
+			return ""
+		},
+		set: func(r *Record, value string) error {
+			id, err := strconv.ParseUint(value, 10, 64)
+			if err != nil {
+				return fmt.Errorf("不是有效的分类 ID: %q", value)
+			}
+			r.CategoryID = &id
+			return nil
+		},
+	},
+	"category":     stringField(func(r *Record) **string { return &r.Category }),
+	"publisher":    stringField(func(r *Record) **string { return &r.Publisher }),
+	"publish_date": stringField(func(r *Record) **string { return &r.PublishDate }),
+	"pages":        intField(func(r *Record) **int { return &r.Pages }),
+	"language":     stringField(func(r *Record) **string { return &r.Language }),
+	"format":       stringField(func(r *Record) **string { return &r.Format }),
+	"cover_url":    stringField(func(r *Record) **string { return &r.CoverURL }),
+	"description":  stringField(func(r *Record) **string { return &r.Description }),
+}
+
+// csvReader 第一行为表头; 空单元格视为未提供该字段
+type csvReader struct {
+	r       *csv.Reader
+	columns []string
+}
+
+func newCSVReader(r io.Reader) (*csvReader, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	header, err := cr.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, errors.New("CSV 文件为空")
+		}
+		return nil, fmt.Errorf("读取 CSV 表头失败: %w", err)
+	}
+	columns := make([]string, len(header))
+	hasISBN := false
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, utf8BOM)))
+		if _, ok := csvFields[name]; !ok {
+			return nil, fmt.Errorf("CSV 表头包含未知的列: %q", name)
+		}
+		if name == "isbn" {
+			hasISBN = true
+		}
+		columns[i] = name
+	}
+	if !hasISBN {
+		return nil, errors.New("CSV 表头缺少 isbn 列")
+	}
+	return &csvReader{r: cr, columns: columns}, nil
+}
+
+func (c *csvReader) Read() (*Record, error) {
+	row, err := c.r.Read()
+	if err != nil {
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			return nil, &RowError{Line: parseErr.StartLine, Err: parseErr.Err}
+		}
+		return nil, err
+	}
+	line, _ := c.r.FieldPos(0)
+	record := &Record{Line: line}
+	if len(row) != len(c.columns) {
+		return nil, &RowError{Line: line, Err: fmt.Errorf("列数为 %d, 表头为 %d 列", len(row), len(c.columns))}
+	}
+	for i, value := range row {
+		value = strings.TrimSpace(value)
+		if value == "" {
+			continue
+		}
+		if err := csvFields[c.columns[i]].set(record, value); err != nil {
+			return nil, &RowError{Line: line, Err: fmt.Errorf("%s %w", c.columns[i], err)}
+		}
+	}
+	return record, nil
+}
+
+// csvWriter 写出全部列
+type csvWriter struct {
+	w *csv.Writer
+}
+
+func newCSVWriter(w io.Writer) (*csvWriter, error) {
+	if _, err := io.WriteString(w, utf8BOM); err != nil {
+		return nil, err
+	}
+	cw := csv.NewWriter(w)
+	if err := cw.Write(csvColumns); err != nil {
+		return nil, err
+	}
+	return &csvWriter{w: cw}, nil
+}
+
+func (c *csvWriter) Write(r *Record) error {
+	row := make([]string, len(csvColumns))
+	for i, name := range csvColumns {
+		row[i] = csvFields[name].get(r)
+	}
+	return c.w.Write(row)
+}
+
+func (c *csvWriter) Close() error {
+	c.w.Flush()
+	return c.w.Error()
+}
@@ -0,0 +1,69 @@
+package catalog
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// jsonlMaxLineBytes 单行最大长度, 图书描述较长时单行可能超过 bufio.Scanner 的默认上限
+const jsonlMaxLineBytes = 1 << 20
+
+// jsonlReader 每行一个 JSON 对象, 字段名与 Record 的 JSON 标签一致; 空行跳过, 未知字段视为错误
+type jsonlReader struct {
+	scanner *bufio.Scanner
+	line    int
+}
+
+func newJSONLReader(r io.Reader) *jsonlReader {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), jsonlMaxLineBytes)
+	return &jsonlReader{scanner: scanner}
+}
+
+func (j *jsonlReader) Read() (*Record, error) {
+	for j.scanner.Scan() {
+		j.line++
+		data := bytes.TrimSpace(j.scanner.Bytes())
+		if j.line == 1 {
+			data = bytes.TrimPrefix(data, []byte(utf8BOM))
+		}
+		if len(data) == 0 {
+			continue
+		}
+		record := &Record{Line: j.line}
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(record); err != nil {
+			return nil, &RowError{Line: j.line, Err: fmt.Errorf("JSON 格式错误: %w", err)}
+		}
+		return record, nil
+	}
+	if err := j.scanner.Err(); err != nil {
+		return nil, err
+	}
+	return nil, io.EOF
+}
+
+// jsonlWriter 每条记录写出一行 JSON
+type jsonlWriter struct {
+	buf     *bufio.Writer
+	encoder *json.Encoder
+}
+
+func newJSONLWriter(w io.Writer) *jsonlWriter {
+	buf := bufio.NewWriter(w)
+	encoder := json.NewEncoder(buf)
+	encoder.SetEscapeHTML(false)
+	return &jsonlWriter{buf: buf, encoder: encoder}
+}
+
+func (j *jsonlWriter) Write(r *Record) error {
+	return j.encoder.Encode(r)
+}
+
+func (j *jsonlWriter) Close() error {
+	return j.buf.Flush()
+}
@@ -0,0 +1,666 @@
+package catalog
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/wangn-tech/bookstore-go/pkg/isbn"
+)
+
+// ONIX 3.0 映射
+//
+//	// ProductIdentifier (15 ISBN-13 / 02 ISBN-10) → isbn
+//	// TitleDetail (01) / TitleElement (01) → title; Collection 的 TitleElement (02) → series
+//	// Contributor (A01 作者) → author, 多位作者以 ", " 连接
+//	// ProductForm (BB 精装, BC 平装) → format; Language (01) → language; Extent (00 正文页数) → pages
+//	// Subject (24 自定义方案, 方案名 bookstore:category / bookstore:type) → category_id + category / type
+//	// TextContent (03 简介) → description; SupportingResource (01 封面) → cover_url
+//	// Publisher (01) → publisher; PublishingDate (01) → publish_date
+//	// SupplyDetail: ProductAvailability (20 有货 / 40 不可售) → status, Stock/OnHand → stock,
+//	// Price (02 含税零售价, CNY) 的 PriceAmount → price, Discount/DiscountPercent → discount
+const (
+	onixNamespace = "http://ns.editeur.org/onix/3.0/reference"
+
+	onixIDTypeISBN10 = "02"
+	onixIDTypeISBN13 = "15"
+
+	onixTitleTypeDistinctive = "01"
+	onixTitleLevelProduct    = "01"
+	onixTitleLevelCollection = "02"
+	onixCollectionPublisher  = "10"
+
+	onixRoleAuthor          = "A01"
+	onixLanguageRoleText    = "01"
+	onixExtentPages         = "00"
+	onixExtentUnitPages     = "03"
+	onixSubjectProprietary  = "24"
+	onixTextTypeDescription = "03"
+	onixResourceFrontCover  = "01"
+	onixResourceModeImage   = "03"
+	onixResourceFormLink    = "02"
+	onixPublishingRole      = "01"
+	onixPublishingDateRole  = "01"
+	onixSupplierPublisher   = "01"
+	onixAvailable           = "20"
+	onixNotAvailable        = "40"
+	onixPriceTypeRRP        = "02"
+	onixCurrencyCNY         = "CNY"
+
+	onixSchemeCategory = "bookstore:category"
+	onixSchemeType     = "bookstore:type"
+)
+
+// onixProductForms 装帧格式与 ONIX ProductForm 的对应关系, 其他格式导出为 BA (图书)
+var onixProductForms = map[string]string{
+	"精装": "BB",
+	"平装": "BC",
+}
+
+// onixLanguages 语言与 ISO 639-2/B 代码的对应关系, 其他语言按原值读写
+var onixLanguages = map[string]string{
+	"中文": "chi",
+	"英文": "eng",
+	"日文": "jpn",
+	"法文": "fre",
+	"德文": "ger",
+	"俄文": "rus",
+	"韩文": "kor",
+}
+
+type onixHeader struct {
+	XMLName      xml.Name `xml:"Header"`
+	SenderName   string   `xml:"Sender>SenderName"`
+	SentDateTime string   `xml:"SentDateTime"`
+}
+
+type onixProduct struct {
+	XMLName            xml.Name                `xml:"Product"`
+	RecordReference    string                  `xml:"RecordReference"`
+	NotificationType   string                  `xml:"NotificationType"`
+	ProductIdentifiers []onixProductIdentifier `xml:"ProductIdentifier"`
+	DescriptiveDetail  *onixDescriptiveDetail  `xml:"DescriptiveDetail"`
+	CollateralDetail   *onixCollateralDetail   `xml:"CollateralDetail"`
+	PublishingDetail   *onixPublishingDetail   `xml:"PublishingDetail"`
+	ProductSupply      *onixProductSupply      `xml:"ProductSupply"`
+}
+
+type onixProductIdentifier struct {
+	ProductIDType string `xml:"ProductIDType"`
+	IDValue       string `xml:"IDValue"`
+}
+
+type onixDescriptiveDetail struct {
+	ProductComposition string            `xml:"ProductComposition"`
+	ProductForm        string            `xml:"ProductForm"`
+	Collections        []onixCollection  `xml:"Collection"`
+	TitleDetails       []onixTitleDetail `xml:"TitleDetail"`
+	Contributors       []onixContributor `xml:"Contributor"`
+	Languages          []onixLanguage    `xml:"Language"`
+	Extents            []onixExtent      `xml:"Extent"`
+	Subjects           []onixSubject     `xml:"Subject"`
+}
+
+type onixCollection struct {
+	CollectionType string            `xml:"CollectionType"`
+	TitleDetails   []onixTitleDetail `xml:"TitleDetail"`
+}
+
+type onixTitleDetail struct {
+	TitleType     string             `xml:"TitleType"`
+	TitleElements []onixTitleElement `xml:"TitleElement"`
+}
+
+type onixTitleElement struct {
+	TitleElementLevel  string `xml:"TitleElementLevel"`
+	TitleText          string `xml:"TitleText,omitempty"`
+	TitlePrefix        string `xml:"TitlePrefix,omitempty"`
+	TitleWithoutPrefix string `xml:"TitleWithoutPrefix,omitempty"`
+}
+
+// text 完整标题, 带前缀的标题 (如 The) 拼接前缀
+func (e *onixTitleElement) text() string {
+	if e.TitleText != "" {
+		return e.TitleText
+	}
+	return strings.TrimSpace(e.TitlePrefix + " " + e.TitleWithoutPrefix)
+}
+
+type onixContributor struct {
+	SequenceNumber  int    `xml:"SequenceNumber,omitempty"`
+	ContributorRole string `xml:"ContributorRole"`
+	PersonName      string `xml:"PersonName,omitempty"`
+	KeyNames        string `xml:"KeyNames,omitempty"`
+	CorporateName   string `xml:"CorporateName,omitempty"`
+}
+
+// name 贡献者名称
+func (c *onixContributor) name() string {
+	for _, name := range []string{c.PersonName, c.KeyNames, c.CorporateName} {
+		if name != "" {
+			return name
+		}
+	}
+	return ""
+}
+
+type onixLanguage struct {
+	LanguageRole string `xml:"LanguageRole"`
+	LanguageCode string `xml:"LanguageCode"`
+}
+
+type onixExtent struct {
+	ExtentType  string `xml:"ExtentType"`
+	ExtentValue string `xml:"ExtentValue"`
+	ExtentUnit  string `xml:"ExtentUnit"`
+}
+
+type onixSubject struct {
+	SubjectSchemeIdentifier string `xml:"SubjectSchemeIdentifier"`
+	SubjectSchemeName       string `xml:"SubjectSchemeName,omitempty"`
+	SubjectCode             string `xml:"SubjectCode,omitempty"`
+	SubjectHeadingText      string `xml:"SubjectHeadingText,omitempty"`
+}
+
+type onixCollateralDetail struct {
+	TextContents        []onixTextContent        `xml:"TextContent"`
+	SupportingResources []onixSupportingResource `xml:"SupportingResource"`
+}
+
+type onixTextContent struct {
+	TextType        string `xml:"TextType"`
+	ContentAudience string `xml:"ContentAudience"`
+	Text            string `xml:"Text"`
+}
+
+type onixSupportingResource struct {
+	ResourceContentType string                `xml:"ResourceContentType"`
+	ContentAudience     string                `xml:"ContentAudience"`
+	ResourceMode        string                `xml:"ResourceMode"`
+	ResourceVersions    []onixResourceVersion `xml:"ResourceVersion"`
+}
+
+type onixResourceVersion struct {
+	ResourceForm string `xml:"ResourceForm"`
+	ResourceLink string `xml:"ResourceLink"`
+}
+
+type onixPublishingDetail struct {
+	Publishers      []onixPublisher      `xml:"Publisher"`
+	PublishingDates []onixPublishingDate `xml:"PublishingDate"`
+}
+
+type onixPublisher struct {
+	PublishingRole string `xml:"PublishingRole"`
+	PublisherName  string `xml:"PublisherName"`
+}
+
+type onixPublishingDate struct {
+	PublishingDateRole string   `xml:"PublishingDateRole"`
+	Date               onixDate `xml:"Date"`
+}
+
+// onixDate dateformat 00 为 YYYYMMDD (默认), 01 为 YYYYMM, 05 为 YYYY
+type onixDate struct {
+	Format string `xml:"dateformat,attr,omitempty"`
+	Value  string `xml:",chardata"`
+}
+
+type onixProductSupply struct {
+	SupplyDetails []onixSupplyDetail `xml:"SupplyDetail"`
+}
+
+type onixSupplyDetail struct {
+	SupplierRole        string      `xml:"Supplier>SupplierRole"`
+	SupplierName        string      `xml:"Supplier>SupplierName"`
+	ProductAvailability string      `xml:"ProductAvailability"`
+	Stock               *onixStock  `xml:"Stock"`
+	Prices              []onixPrice `xml:"Price"`
+}
+
+type onixStock struct {
+	OnHand *int `xml:"OnHand"`
+}
+
+type onixPrice struct {
+	PriceType    string        `xml:"PriceType"`
+	Discount     *onixDiscount `xml:"Discount"`
+	PriceAmount  string        `xml:"PriceAmount"`
+	CurrencyCode string        `xml:"CurrencyCode,omitempty"`
+}
+
+type onixDiscount struct {
+	DiscountPercent string `xml:"DiscountPercent"`
+}
+
+// onixReader 流式读取 ONIX 消息中的 Product, 只识别 reference 标签名 (不支持 short 标签)
+type onixReader struct {
+	decoder *xml.Decoder
+}
+
+func newONIXReader(r io.Reader) *onixReader {
+	return &onixReader{decoder: xml.NewDecoder(r)}
+}
+
+func (o *onixReader) Read() (*Record, error) {
+	for {
+		token, err := o.decoder.Token()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil, io.EOF
+			}
+			return nil, fmt.Errorf("ONIX 文件格式错误: %w", err)
+		}
+		start, ok := token.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		if start.Name.Local == "ONIXMessage" {
+			for _, attr := range start.Attr {
+				if attr.Name.Local == "release" && !strings.HasPrefix(attr.Value, "3") {
+					return nil, fmt.Errorf("只支持 ONIX 3.0, 文件版本为 %s", attr.Value)
+				}
+			}
+			continue
+		}
+		if start.Name.Local != "Product" {
+			continue
+		}
+		line, _ := o.decoder.InputPos()
+		var product onixProduct
+		if err := o.decoder.DecodeElement(&product, &start); err != nil {
+			return nil, fmt.Errorf("ONIX 文件格式错误: %w", err)
+		}
+		record, err := product.record()
+		if err != nil {
+			return nil, &RowError{Line: line, Err: err}
+		}
+		record.Line = line
+		return record, nil
+	}
+}
+
+// record 将 Product 转换为目录记录
+func (p *onixProduct) record() (*Record, error) {
+	r := &Record{}
+	for _, id := range p.ProductIdentifiers {
+		if id.ProductIDType == onixIDTypeISBN13 || (id.ProductIDType == onixIDTypeISBN10 && r.ISBN == "") {
+			r.ISBN = strings.TrimSpace(id.IDValue)
+		}
+	}
+	if r.ISBN == "" {
+		return nil, errors.New("缺少 ISBN (ProductIDType 15 或 02)")
+	}
+
+	if d := p.DescriptiveDetail; d != nil {
+		if title := findTitle(d.TitleDetails, onixTitleLevelProduct); title != "" {
+			r.Title = &title
+		}
+		for _, collection := range d.Collections {
+			if series := findTitle(collection.TitleDetails, onixTitleLevelCollection); series != "" {
+				r.Series = &series
+				break
+			}
+		}
+		var authors []string
+		for _, contributor := range d.Contributors {
+			if name := contributor.name(); contributor.ContributorRole == onixRoleAuthor && name != "" {
+				authors = append(authors, name)
+			}
+		}
+		if len(authors) > 0 {
+			author := strings.Join(authors, ", ")
+			r.Author = &author
+		}
+		for format, form := range onixProductForms {
+			if d.ProductForm == form {
+				r.Format = &format
+			}
+		}
+		for _, language := range d.Languages {
+			if language.LanguageRole == onixLanguageRoleText && language.LanguageCode != "" {
+				name := language.LanguageCode
+				for n, code := range onixLanguages {
+					if code == language.LanguageCode {
+						name = n
+					}
+				}
+				r.Language = &name
+				break
+			}
+		}
+		for _, extent := range d.Extents {
+			if extent.ExtentType == onixExtentPages && extent.ExtentUnit == onixExtentUnitPages {
+				pages, err := strconv.Atoi(strings.TrimSpace(extent.ExtentValue))
+				if err != nil {
+					return nil, fmt.Errorf("页数不是有效的整数: %q", extent.ExtentValue)
+				}
+				r.Pages = &pages
+			}
+		}
+		for _, subject := range d.Subjects {
+			if subject.SubjectSchemeIdentifier != onixSubjectProprietary {
+				continue
+			}
+			switch subject.SubjectSchemeName {
+			case onixSchemeCategory:
+				if subject.SubjectCode != "" {
+					id, err := strconv.ParseUint(subject.SubjectCode, 10, 64)
+					if err != nil {
+						return nil, fmt.Errorf("分类 ID 无效: %q", subject.SubjectCode)
+					}
+					r.CategoryID = &id
+				}
+				if subject.SubjectHeadingText != "" {
+					name := subject.SubjectHeadingText
+					r.Category = &name
+				}
+			case onixSchemeType:
+				if subject.SubjectHeadingText != "" {
+					bookType := subject.SubjectHeadingText
+					r.Type = &bookType
+				}
+			}
+		}
+	}
+
+	if c := p.CollateralDetail; c != nil {
+		for _, text := range c.TextContents {
+			if text.TextType == onixTextTypeDescription {
+				description := strings.TrimSpace(text.Text)
+				r.Description = &description
+				break
+			}
+		}
+		for _, resource := range c.SupportingResources {
+			if resource.ResourceContentType == onixResourceFrontCover && len(resource.ResourceVersions) > 0 {
+				link := strings.TrimSpace(resource.ResourceVersions[0].ResourceLink)
+				r.CoverURL = &link
+				break
+			}
+		}
+	}
+
+	if pd := p.PublishingDetail; pd != nil {
+		for _, publisher := range pd.Publishers {
+			if publisher.PublishingRole == onixPublishingRole {
+				name := publisher.PublisherName
+				r.Publisher = &name
+				break
+			}
+		}
+		for _, date := range pd.PublishingDates {
+			if date.PublishingDateRole == onixPublishingDateRole {
+				value, err := date.Date.publishDate()
+				if err != nil {
+					return nil, err
+				}
+				r.PublishDate = &value
+				break
+			}
+		}
+	}
+
+	if ps := p.ProductSupply; ps != nil && len(ps.SupplyDetails) > 0 {
+		supply := ps.SupplyDetails[0]
+		switch supply.ProductAvailability {
+		case onixAvailable:
+			status := 1
+			r.Status = &status
+		case onixNotAvailable:
+			status := 0
+			r.Status = &status
+		}
+		if supply.Stock != nil && supply.Stock.OnHand != nil {
+			stock := *supply.Stock.OnHand
+			r.Stock = &stock
+		}
+		for _, price := range supply.Prices {
+			if price.CurrencyCode != "" && price.CurrencyCode != onixCurrencyCNY {
+				continue
+			}
+			amount, err := parseYuan(price.PriceAmount)
+			if err != nil {
+				return nil, err
+			}
+			r.Price = &amount
+			// 价格不带 Discount 表示无折扣
+			discount := 0
+			if price.Discount != nil {
+				discount, err = strconv.Atoi(strings.TrimSpace(price.Discount.DiscountPercent))
+				if err != nil {
+					return nil, fmt.Errorf("折扣不是有效的整数: %q", price.Discount.DiscountPercent)
+				}
+			}
+			r.Discount = &discount
+			break
+		}
+	}
+	return r, nil
+}
+
+// findTitle 返回指定层级的完整标题
+func findTitle(details []onixTitleDetail, level string) string {
+	for _, detail := range details {
+		if detail.TitleType != onixTitleTypeDistinctive {
+			continue
+		}
+		for _, element := range detail.TitleElements {
+			if element.TitleElementLevel == level {
+				return element.text()
+			}
+		}
+	}
+	return ""
+}
+
+// parseYuan 解析价格, 图书价格以元为单位存储, 不支持角分
+func parseYuan(amount string) (int, error) {
+	amount = strings.TrimSpace(amount)
+	yuan, cents, _ := strings.Cut(amount, ".")
+	if strings.Trim(cents, "0") != "" {
+		return 0, fmt.Errorf("价格只支持整数元: %q", amount)
+	}
+	n, err := strconv.Atoi(yuan)
+	if err != nil {
+		return 0, fmt.Errorf("价格无效: %q", amount)
+	}
+	return n, nil
+}
+
+// publishDate 转换为 YYYY-MM-DD (或 YYYY-MM、YYYY)
+func (d onixDate) publishDate() (string, error) {
+	value := strings.TrimSpace(d.Value)
+	layouts := map[string][2]string{
+		"":   {"20060102", "2006-01-02"},
+		"00": {"20060102", "2006-01-02"},
+		"01": {"200601", "2006-01"},
+		"05": {"2006", "2006"},
+	}
+	layout, ok := layouts[d.Format]
+	if !ok {
+		return "", fmt.Errorf("不支持的出版日期格式: dateformat=%s", d.Format)
+	}
+	t, err := time.Parse(layout[0], value)
+	if err != nil {
+		return "", fmt.Errorf("出版日期无效: %q", value)
+	}
+	return t.Format(layout[1]), nil
+}
+
+// newONIXDate 由 YYYY-MM-DD (或 YYYY-MM、YYYY) 生成 ONIX 日期, 无法识别时返回 false
+func newONIXDate(value string) (onixDate, bool) {
+	for _, layout := range []struct{ in, out, format string }{
+		{"2006-01-02", "20060102", "00"},
+		{"2006-01", "200601", "01"},
+		{"2006", "2006", "05"},
+	} {
+		if t, err := time.Parse(layout.in, strings.TrimSpace(value)); err == nil {
+			date := onixDate{Value: t.Format(layout.out)}
+			if layout.format != "00" {
+				date.Format = layout.format
+			}
+			return date, true
+		}
+	}
+	return onixDate{}, false
+}
+
+// onixWriter 写出 ONIX 3.0 消息, 每条记录一个 Product
+type onixWriter struct {
+	w       io.Writer
+	encoder *xml.Encoder
+	seq     int
+}
+
+func newONIXWriter(w io.Writer) (*onixWriter, error) {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return nil, err
+	}
+	if _, err := fmt.Fprintf(w, "<ONIXMessage release=\"3.0\" xmlns=\"%s\">\n", onixNamespace); err != nil {
+		return nil, err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("  ", "  ")
+	header := onixHeader{SenderName: "bookstore", SentDateTime: time.Now().Format("20060102T1504-0700")}
+	if err := encoder.Encode(header); err != nil {
+		return nil, err
+	}
+	return &onixWriter{w: w, encoder: encoder}, nil
+}
+
+func (o *onixWriter) Write(r *Record) error {
+	o.seq++
+	return o.encoder.Encode(newONIXProduct(r, o.seq))
+}
+
+func (o *onixWriter) Close() error {
+	if err := o.encoder.Flush(); err != nil {
+		return err
+	}
+	_, err := io.WriteString(o.w, "\n</ONIXMessage>\n")
+	return err
+}
+
+// newONIXProduct 由目录记录生成 Product
+func newONIXProduct(r *Record, seq int) *onixProduct {
+	value := func(p *string) string {
+		if p == nil {
+			return ""
+		}
+		return *p
+	}
+	productID := isbn.Normalize(r.ISBN)
+	idType := onixIDTypeISBN13
+	if len(productID) == 10 {
+		idType = onixIDTypeISBN10
+	}
+	p := &onixProduct{
+		RecordReference:    fmt.Sprintf("bookstore-%d-%s", seq, productID),
+		NotificationType:   "03",
+		ProductIdentifiers: []onixProductIdentifier{{ProductIDType: idType, IDValue: productID}},
+	}
+
+	form := onixProductForms[value(r.Format)]
+	if form == "" {
+		form = "BA"
+	}
+	d := &onixDescriptiveDetail{
+		ProductComposition: "00",
+		ProductForm:        form,
+		TitleDetails: []onixTitleDetail{{
+			TitleType:     onixTitleTypeDistinctive,
+			TitleElements: []onixTitleElement{{TitleElementLevel: onixTitleLevelProduct, TitleText: value(r.Title)}},
+		}},
+	}
+	if series := value(r.Series); series != "" {
+		d.Collections = []onixCollection{{
+			CollectionType: onixCollectionPublisher,
+			TitleDetails: []onixTitleDetail{{
+				TitleType:     onixTitleTypeDistinctive,
+				TitleElements: []onixTitleElement{{TitleElementLevel: onixTitleLevelCollection, TitleText: series}},
+			}},
+		}}
+	}
+	if author := value(r.Author); author != "" {
+		d.Contributors = []onixContributor{{SequenceNumber: 1, ContributorRole: onixRoleAuthor, PersonName: author}}
+	}
+	if language := value(r.Language); language != "" {
+		code := onixLanguages[language]
+		if code == "" {
+			code = language
+		}
+		d.Languages = []onixLanguage{{LanguageRole: onixLanguageRoleText, LanguageCode: code}}
+	}
+	if r.Pages != nil && *r.Pages > 0 {
+		d.Extents = []onixExtent{{ExtentType: onixExtentPages, ExtentValue: strconv.Itoa(*r.Pages), ExtentUnit: onixExtentUnitPages}}
+	}
+	if r.CategoryID != nil {
+		d.Subjects = append(d.Subjects, onixSubject{
+			SubjectSchemeIdentifier: onixSubjectProprietary,
+			SubjectSchemeName:       onixSchemeCategory,
+			SubjectCode:             strconv.FormatUint(*r.CategoryID, 10),
+			SubjectHeadingText:      value(r.Category),
+		})
+	}
+	if bookType := value(r.Type); bookType != "" {
+		d.Subjects = append(d.Subjects, onixSubject{
+			SubjectSchemeIdentifier: onixSubjectProprietary,
+			SubjectSchemeName:       onixSchemeType,
+			SubjectHeadingText:      bookType,
+		})
+	}
+	p.DescriptiveDetail = d
+
+	c := &onixCollateralDetail{}
+	if description := value(r.Description); description != "" {
+		c.TextContents = []onixTextContent{{TextType: onixTextTypeDescription, ContentAudience: "00", Text: description}}
+	}
+	if cover := value(r.CoverURL); cover != "" {
+		c.SupportingResources = []onixSupportingResource{{
+			ResourceContentType: onixResourceFrontCover,
+			ContentAudience:     "00",
+			ResourceMode:        onixResourceModeImage,
+			ResourceVersions:    []onixResourceVersion{{ResourceForm: onixResourceFormLink, ResourceLink: cover}},
+		}}
+	}
+	if len(c.TextContents) > 0 || len(c.SupportingResources) > 0 {
+		p.CollateralDetail = c
+	}
+
+	pd := &onixPublishingDetail{}
+	if publisher := value(r.Publisher); publisher != "" {
+		pd.Publishers = []onixPublisher{{PublishingRole: onixPublishingRole, PublisherName: publisher}}
+	}
+	if date, ok := newONIXDate(value(r.PublishDate)); ok {
+		pd.PublishingDates = []onixPublishingDate{{PublishingDateRole: onixPublishingDateRole, Date: date}}
+	}
+	if len(pd.Publishers) > 0 || len(pd.PublishingDates) > 0 {
+		p.PublishingDetail = pd
+	}
+
+	supply := onixSupplyDetail{
+		SupplierRole:        onixSupplierPublisher,
+		SupplierName:        "bookstore",
+		ProductAvailability: onixNotAvailable,
+	}
+	if r.Stock != nil {
+		supply.Stock = &onixStock{OnHand: r.Stock}
+	}
+	if r.Status != nil && *r.Status == 1 {
+		supply.ProductAvailability = onixAvailable
+	}
+	if r.Price != nil {
+		price := onixPrice{PriceType: onixPriceTypeRRP, PriceAmount: fmt.Sprintf("%d.00", *r.Price), CurrencyCode: onixCurrencyCNY}
+		if r.Discount != nil && *r.Discount > 0 {
+			price.Discount = &onixDiscount{DiscountPercent: strconv.Itoa(*r.Discount)}
+		}
+		supply.Prices = []onixPrice{price}
+	}
+	p.ProductSupply = &onixProductSupply{SupplyDetails: []onixSupplyDetail{supply}}
+	return p
+}
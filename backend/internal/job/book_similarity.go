@@ -0,0 +1,79 @@
+package job
+
+import (
+	"context"
+	"time"
+
+	"github.com/wangn-tech/bookstore-go/internal/service"
+	"github.com/wangn-tech/bookstore-go/internal/utils"
+	"github.com/wangn-tech/bookstore-go/pkg/logger"
+	"go.uber.org/zap"
+)
+
+const (
+	// bookSimilarityLockKey 相似度重建锁, 多副本部署时同一时刻只有一个实例执行重建
+	bookSimilarityLockKey = "lock:job:book_similarity"
+
+	defaultSimilarityInterval = time.Hour
+)
+
+// BookSimilarityJob 定时根据已支付订单重建图书共同购买相似度
+type BookSimilarityJob struct {
+	recommendService service.IRecommendService
+	interval         time.Duration // 重建间隔
+}
+
+// NewBookSimilarityJob 创建相似度重建任务, interval 未配置时使用默认值
+func NewBookSimilarityJob(recommendService service.IRecommendService, interval time.Duration) *BookSimilarityJob {
+	if interval <= 0 {
+		interval = defaultSimilarityInterval
+	}
+	return &BookSimilarityJob{
+		recommendService: recommendService,
+		interval:         interval,
+	}
+}
+
+// Start 在后台协程中启动后立即执行一次, 之后按间隔执行, ctx 取消时退出
+func (j *BookSimilarityJob) Start(ctx context.Context) {
+	logger.Log.Info("BookSimilarityJob: 图书相似度重建任务已启动", zap.Duration("interval", j.interval))
+	go func() {
+		j.runOnce(ctx)
+		ticker := time.NewTicker(j.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				j.runOnce(ctx)
+			}
+		}
+	}()
+}
+
+// runOnce 执行一次重建
+func (j *BookSimilarityJob) runOnce(ctx context.Context) {
+	// 锁的过期时间与重建间隔一致, 实例异常退出时锁会自动释放
+	token, ok, err := utils.TryLock(ctx, bookSimilarityLockKey, j.interval)
+	if err != nil {
+		logger.Log.Error("BookSimilarityJob: 获取锁失败", zap.Error(err))
+		return
+	}
+	if !ok {
+		return
+	}
+	defer func() {
+		if err := utils.Unlock(ctx, bookSimilarityLockKey, token); err != nil {
+			logger.Log.Warn("BookSimilarityJob: 释放锁失败", zap.Error(err))
+		}
+	}()
+
+	start := time.Now()
+	count, err := j.recommendService.RebuildSimilarities(ctx)
+	if err != nil {
+		logger.Log.Error("BookSimilarityJob: 重建图书相似度失败", zap.Error(err))
+		return
+	}
+	logger.Log.Info("BookSimilarityJob: 图书相似度重建完成", zap.Int("count", count), zap.Duration("elapsed", time.Since(start)))
+}
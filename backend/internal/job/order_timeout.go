@@ -0,0 +1,87 @@
+package job
+
+import (
+	"context"
+	"time"
+
+	"github.com/wangn-tech/bookstore-go/internal/service"
+	"github.com/wangn-tech/bookstore-go/internal/utils"
+	"github.com/wangn-tech/bookstore-go/pkg/logger"
+	"go.uber.org/zap"
+)
+
+const (
+	// orderTimeoutLockKey 超时订单扫描锁, 多副本部署时同一时刻只有一个实例执行扫描
+	orderTimeoutLockKey = "lock:job:order_timeout"
+	// orderTimeoutBatchSize 每轮最多处理的订单数
+	orderTimeoutBatchSize = 100
+
+	defaultPendingTTL     = 30 * time.Minute
+	defaultCancelInterval = time.Minute
+)
+
+// OrderTimeoutJob 定时取消超时未支付的订单
+type OrderTimeoutJob struct {
+	orderService service.IOrderService
+	ttl          time.Duration // 待支付订单超时时间
+	interval     time.Duration // 扫描间隔
+}
+
+// NewOrderTimeoutJob 创建超时订单取消任务, ttl/interval 未配置时使用默认值
+func NewOrderTimeoutJob(orderService service.IOrderService, ttl, interval time.Duration) *OrderTimeoutJob {
+	if ttl <= 0 {
+		ttl = defaultPendingTTL
+	}
+	if interval <= 0 {
+		interval = defaultCancelInterval
+	}
+	return &OrderTimeoutJob{
+		orderService: orderService,
+		ttl:          ttl,
+		interval:     interval,
+	}
+}
+
+// Start 在后台协程中按间隔执行, ctx 取消时退出
+func (j *OrderTimeoutJob) Start(ctx context.Context) {
+	logger.Log.Info("OrderTimeoutJob: 超时订单取消任务已启动", zap.Duration("ttl", j.ttl), zap.Duration("interval", j.interval))
+	go func() {
+		ticker := time.NewTicker(j.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				j.runOnce(ctx)
+			}
+		}
+	}()
+}
+
+// runOnce 执行一轮扫描
+func (j *OrderTimeoutJob) runOnce(ctx context.Context) {
+	// 锁的过期时间与扫描间隔一致, 实例异常退出时锁会自动释放
+	token, ok, err := utils.TryLock(ctx, orderTimeoutLockKey, j.interval)
+	if err != nil {
+		logger.Log.Error("OrderTimeoutJob: 获取锁失败", zap.Error(err))
+		return
+	}
+	if !ok {
+		return
+	}
+	defer func() {
+		if err := utils.Unlock(ctx, orderTimeoutLockKey, token); err != nil {
+			logger.Log.Warn("OrderTimeoutJob: 释放锁失败", zap.Error(err))
+		}
+	}()
+
+	cancelled, err := j.orderService.CancelExpiredOrders(ctx, j.ttl, orderTimeoutBatchSize)
+	if err != nil {
+		logger.Log.Error("OrderTimeoutJob: 查询超时订单失败", zap.Error(err))
+		return
+	}
+	if cancelled > 0 {
+		logger.Log.Info("OrderTimeoutJob: 本轮自动取消超时订单", zap.Int("count", cancelled))
+	}
+}
@@ -1,6 +1,7 @@
 package middlerware
 
 import (
+	"errors"
 	"net/http"
 	"strings"
 
@@ -36,25 +37,58 @@ func JWTAuth() gin.HandlerFunc {
 		tokenStr := tokenParts[1]
 		claims, err := utils.ParseToken(tokenStr)
 		if err != nil {
-			// token 解析失败
+			// token 解析失败, 或所属会话已失效、被撤销
 			logger.Log.Warn("token 解析失败", zap.Error(err), zap.String("path", ctx.Request.URL.Path))
 			// 根据不同的错误类型返回不同的消息
-			result.Fail(ctx, http.StatusUnauthorized, "无效的token")
+			if errors.Is(err, utils.ErrTokenRevoked) {
+				result.Fail(ctx, http.StatusUnauthorized, "token已失效，请重新登录")
+			} else {
+				result.Fail(ctx, http.StatusUnauthorized, "无效的token")
+			}
 			ctx.Abort()
 			return
 		}
-		valid := utils.IsTokenValidInRedis(claims.UserID, tokenStr, constants.AccessToken)
-		if !valid {
-			logger.Log.Warn("JWTAuth: token 已失效或被撤销",
-				zap.Uint64("userID", claims.UserID),
-				zap.Error(err))
-			result.Fail(ctx, http.StatusUnauthorized, "token已失效，请重新登录")
+		if claims.TokenType != constants.AccessToken {
+			logger.Log.Warn("JWTAuth: 非 access token", zap.Uint64("userID", claims.UserID))
+			result.Fail(ctx, http.StatusUnauthorized, "无效的token")
 			ctx.Abort()
 			return
 		}
+		// 更新会话最近活跃时间, 失败不影响本次请求
+		if err := utils.TouchSession(claims.ID); err != nil {
+			logger.Log.Warn("JWTAuth: 更新会话活跃时间失败", zap.String("sessionID", claims.ID), zap.Error(err))
+		}
 		// 将当前请求的 claims 信息保存到请求的上下文 c 上
-		ctx.Set(constants.UserID, claims.UserID)
-		ctx.Set(constants.Username, claims.Username)
+		setClaims(ctx, claims)
 		ctx.Next()
 	}
 }
+
+// OptionalJWTAuth 可选的 JWT 认证中间件, 用于匿名可访问但登录后返回个性化数据的接口
+//
+//	// 携带有效的 access token 时与 JWTAuth 一样写入上下文, 否则按匿名用户继续处理, 不返回 401
+func OptionalJWTAuth() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		tokenParts := strings.SplitN(ctx.GetHeader("Authorization"), " ", 2)
+		if len(tokenParts) != 2 || tokenParts[0] != "Bearer" {
+			ctx.Next()
+			return
+		}
+		claims, err := utils.ParseToken(tokenParts[1])
+		if err != nil || claims.TokenType != constants.AccessToken {
+			ctx.Next()
+			return
+		}
+		setClaims(ctx, claims)
+		ctx.Next()
+	}
+}
+
+// setClaims 将 claims 信息保存到请求上下文
+func setClaims(ctx *gin.Context, claims *utils.Claims) {
+	ctx.Set(constants.UserID, claims.UserID)
+	ctx.Set(constants.Username, claims.Username)
+	ctx.Set(constants.SessionID, claims.ID)
+	ctx.Set(constants.Roles, claims.Roles)
+	ctx.Set(constants.Permissions, claims.Permissions)
+}
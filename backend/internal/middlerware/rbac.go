@@ -0,0 +1,53 @@
+package middlerware
+
+import (
+	"net/http"
+	"slices"
+
+	"github.com/gin-gonic/gin"
+	"github.com/wangn-tech/bookstore-go/common/result"
+	"github.com/wangn-tech/bookstore-go/internal/app/constants"
+	"github.com/wangn-tech/bookstore-go/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// RequireRoles 角色校验中间件, 拥有任一角色即可访问, 需在 JWTAuth 之后使用
+//
+//	// 既可用于路由组 group.Use(RequireRoles(...)), 也可用于单个路由 group.GET(path, RequireRoles(...), handler)
+func RequireRoles(roles ...string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		userRoles := ctx.GetStringSlice(constants.Roles)
+		for _, role := range roles {
+			if slices.Contains(userRoles, role) {
+				ctx.Next()
+				return
+			}
+		}
+		logger.Log.Warn("RequireRoles: 角色不足，拒绝访问",
+			zap.Uint64("userID", ctx.GetUint64(constants.UserID)),
+			zap.Strings("required", roles),
+			zap.Strings("roles", userRoles),
+			zap.String("path", ctx.Request.URL.Path))
+		result.Fail(ctx, http.StatusForbidden, "无访问权限")
+		ctx.Abort()
+	}
+}
+
+// RequirePermissions 权限校验中间件, 需同时拥有全部权限才可访问, 需在 JWTAuth 之后使用
+func RequirePermissions(permissions ...string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		userPerms := ctx.GetStringSlice(constants.Permissions)
+		for _, perm := range permissions {
+			if !slices.Contains(userPerms, perm) {
+				logger.Log.Warn("RequirePermissions: 权限不足，拒绝访问",
+					zap.Uint64("userID", ctx.GetUint64(constants.UserID)),
+					zap.String("missing", perm),
+					zap.String("path", ctx.Request.URL.Path))
+				result.Fail(ctx, http.StatusForbidden, "无访问权限")
+				ctx.Abort()
+				return
+			}
+		}
+		ctx.Next()
+	}
+}
@@ -1,19 +1,31 @@
 package model
 
-import "time"
+import (
+	"time"
+
+	"github.com/wangn-tech/bookstore-go/pkg/hanzi"
+	"github.com/wangn-tech/bookstore-go/pkg/isbn"
+	"gorm.io/gorm"
+)
+
+// searchKeysMaxRunes search_keys 列长度
+const searchKeysMaxRunes = 1024
 
 type Book struct {
 	ID          uint64    `gorm:"primaryKey" json:"id"`
 	Title       string    `gorm:"not null" json:"title"`
 	Author      string    `json:"author"`
+	Series      string    `json:"series"`      // 丛书/系列名
 	Price       int       `json:"price"`       // 价格（元）
-	Discount    int       `json:"discount"`    // 折扣（百分比，100表示无折扣）
+	Discount    int       `json:"discount"`    // 折扣（减免百分比，0表示无折扣）
 	Type        string    `json:"type"`        // 图书类型
-	Stock       int       `json:"stock"`       // 库存数量
+	Stock       int       `json:"stock"`       // 库存数量（在库）
+	Reserved    int       `json:"reserved"`    // 已预占库存（待支付订单占用）
 	Status      int       `json:"status"`      // 图书状态：0-下架，1-上架
 	Description string    `json:"description"` // 图书描述
 	CoverURL    string    `json:"cover_url"`
-	ISBN        string    `json:"isbn"`         // ISBN号
+	ISBN        string    `json:"isbn"`         // ISBN号, 去除连字符和空格后保存
+	ISBN13      *string   `json:"isbn13"`       // 规范化的 ISBN-13, 唯一; ISBN 无效时为空
 	Publisher   string    `json:"publisher"`    // 出版社
 	PublishDate string    `json:"publish_date"` // 出版日期
 	Pages       int       `json:"pages"`        // 页数
@@ -21,10 +33,65 @@ type Book struct {
 	Format      string    `json:"format"`       // 装帧格式
 	CategoryID  uint64    `json:"category_id"`  // 分类ID
 	Sale        int       `json:"sale"`         // 销售量
+	RatingAvg   float64   `json:"rating_avg"`   // 平均评分
+	RatingCount int       `json:"rating_count"` // 评分人数
+	SearchKeys  string    `json:"-"`            // 检索键: 书名/作者/系列的简体文本、全拼和拼音首字母
 	CreatedAt   time.Time `json:"created_at"`
 	UpdatedAt   time.Time `json:"updated_at"`
+
+	AvailableStock int   `gorm:"-" json:"available_stock"` // 可售库存 = 在库库存 - 已预占库存
+	IsFavorited    bool  `gorm:"-" json:"is_favorited"`    // 当前用户是否已收藏, 未登录时为 false
+	FavoriteCount  int64 `gorm:"-" json:"favorite_count"`  // 收藏数
 }
 
 func (b *Book) TableName() string {
 	return "books"
 }
+
+// AfterFind 查询后计算可售库存
+func (b *Book) AfterFind(tx *gorm.DB) error {
+	b.AvailableStock = max(b.Stock-b.Reserved, 0)
+	return nil
+}
+
+// BeforeSave 保存前根据书名、作者和系列生成检索键, 并规范化 ISBN
+//
+//	// 按列更新 (Model(&Book{}).Update) 时 Title 为空, 不重新生成
+func (b *Book) BeforeSave(tx *gorm.DB) error {
+	if b.Title != "" {
+		b.SearchKeys = b.BuildSearchKeys()
+		b.ISBN, b.ISBN13 = NormalizeISBN(b.ISBN)
+	}
+	return nil
+}
+
+// NormalizeISBN 返回去除连字符和空格的 ISBN 及对应的 ISBN-13, ISBN 格式或校验位错误时 ISBN-13 为 nil
+func NormalizeISBN(s string) (string, *string) {
+	normalized := isbn.Normalize(s)
+	isbn13, err := isbn.To13(normalized)
+	if err != nil {
+		return normalized, nil
+	}
+	return normalized, &isbn13
+}
+
+// BuildSearchKeys 由书名、作者和系列生成检索键, 超出列长度时截断
+func (b *Book) BuildSearchKeys() string {
+	keys := []rune(hanzi.SearchKeys(b.Title, b.Author, b.Series))
+	if len(keys) > searchKeysMaxRunes {
+		keys = keys[:searchKeysMaxRunes]
+	}
+	return string(keys)
+}
+
+// ListPriceInCents 原价（分）
+func (b *Book) ListPriceInCents() int {
+	return b.Price * 100
+}
+
+// SalePriceInCents 折后单价（分）
+//
+//	// Price 单位为元, Discount 为减免百分比 (0 表示无折扣), 折后价 = Price * 100 * (100 - Discount) / 100
+func (b *Book) SalePriceInCents() int {
+	return b.Price * (100 - b.Discount)
+}
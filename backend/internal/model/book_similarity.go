@@ -0,0 +1,18 @@
+package model
+
+import "time"
+
+// BookSimilarity 图书之间的共同购买相似度, 由推荐任务定期全量重建
+//
+//	// 每对图书双向各存一行, 只保留每本图书相似度最高的若干本
+type BookSimilarity struct {
+	BookID        uint64    `gorm:"primaryKey" json:"book_id"`
+	SimilarBookID uint64    `gorm:"primaryKey" json:"similar_book_id"`
+	Score         float64   `json:"score"`    // 余弦相似度: 共同购买人数 / sqrt(两本书各自的购买人数之积)
+	CoCount       int       `json:"co_count"` // 共同购买人数
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+func (s *BookSimilarity) TableName() string {
+	return "book_similarities"
+}
@@ -0,0 +1,17 @@
+package model
+
+import "time"
+
+// CartItem 购物车商品
+type CartItem struct {
+	ID        uint64    `gorm:"primaryKey" json:"id"`
+	UserID    uint64    `json:"user_id"`
+	BookID    uint64    `json:"book_id"`
+	Quantity  int       `json:"quantity"` // 数量
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func (c *CartItem) TableName() string {
+	return "cart_items"
+}
@@ -5,6 +5,7 @@ import "time"
 // Category 图书分类模型
 type Category struct {
 	ID          uint64    `gorm:"primaryKey" json:"id"`
+	ParentID    *uint64   `json:"parent_id"`                     // 父分类ID, 为空表示顶级分类
 	Name        string    `gorm:"not null;unique" json:"name"`   // 分类名称
 	Description string    `json:"description"`                   // 分类描述
 	Icon        string    `json:"icon"`                          // 分类图标
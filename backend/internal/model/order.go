@@ -2,13 +2,27 @@ package model
 
 import "time"
 
+// 订单状态
+//
+//	// 待支付 → 已支付 → 已发货 → 已送达 → 已完成
+//	// 待支付 → 已取消; 已支付/已发货/已送达 → 已退款
+const (
+	OrderStatusPending   = 0 // 待支付
+	OrderStatusPaid      = 1 // 已支付
+	OrderStatusCancelled = 2 // 已取消
+	OrderStatusShipped   = 3 // 已发货
+	OrderStatusDelivered = 4 // 已送达
+	OrderStatusCompleted = 5 // 已完成
+	OrderStatusRefunded  = 6 // 已退款
+)
+
 // Order 订单模型
 type Order struct {
 	ID          uint64     `gorm:"primaryKey" json:"id"`
 	UserID      uint64     `gorm:"not null" json:"user_id"`         // 用户ID
 	OrderNo     string     `gorm:"not null;unique" json:"order_no"` // 订单号
 	TotalAmount int        `gorm:"not null" json:"total_amount"`    // 订单总金额（分）
-	Status      int        `gorm:"default:0" json:"status"`         // 订单状态：0-待支付，1-已支付，2-已取消
+	Status      int        `gorm:"default:0" json:"status"`         // 订单状态：0-待支付，1-已支付，2-已取消，3-已发货，4-已送达，5-已完成，6-已退款
 	IsPaid      bool       `gorm:"default:false" json:"is_paid"`    // 是否已支付
 	PaymentTime *time.Time `json:"payment_time"`                    // 支付时间
 	CreatedAt   time.Time  `json:"created_at"`
@@ -5,11 +5,13 @@ import "time"
 // OrderItem 订单项模型
 type OrderItem struct {
 	ID        uint64    `gorm:"primaryKey" json:"id"`
-	OrderID   uint64    `gorm:"not null" json:"order_id"` // 订单ID
-	BookID    uint64    `gorm:"not null" json:"book_id"`  // 图书ID
-	Quantity  int       `gorm:"not null" json:"quantity"` // 数量
-	Price     int       `gorm:"not null" json:"price"`    // 单价（分）
-	Subtotal  int       `gorm:"not null" json:"subtotal"` // 小计（分）
+	OrderID   uint64    `gorm:"not null" json:"order_id"`   // 订单ID
+	BookID    uint64    `gorm:"not null" json:"book_id"`    // 图书ID
+	Quantity  int       `gorm:"not null" json:"quantity"`   // 数量
+	ListPrice int       `gorm:"not null" json:"list_price"` // 下单时原价（分）
+	Discount  int       `gorm:"not null" json:"discount"`   // 下单时折扣（百分比，0表示无折扣）
+	Price     int       `gorm:"not null" json:"price"`      // 成交单价（分）
+	Subtotal  int       `gorm:"not null" json:"subtotal"`   // 小计（分）
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
 
@@ -0,0 +1,26 @@
+package model
+
+import "time"
+
+// 订单状态变更操作人类型
+const (
+	OperatorUser   = "user"   // 买家
+	OperatorAdmin  = "admin"  // 管理员
+	OperatorSystem = "system" // 系统 (定时任务等)
+)
+
+// OrderStatusHistory 订单状态变更记录
+type OrderStatusHistory struct {
+	ID           uint64    `gorm:"primaryKey" json:"id"`
+	OrderID      uint64    `gorm:"not null" json:"order_id"`      // 订单ID
+	FromStatus   *int      `json:"from_status"`                   // 变更前状态, 创建订单时为空
+	ToStatus     int       `gorm:"not null" json:"to_status"`     // 变更后状态
+	OperatorType string    `gorm:"not null" json:"operator_type"` // 操作人类型：user, admin, system
+	OperatorID   uint64    `json:"operator_id"`                   // 操作人ID, 系统操作为 0
+	Remark       string    `json:"remark"`                        // 备注
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+func (h *OrderStatusHistory) TableName() string {
+	return "order_status_history"
+}
@@ -0,0 +1,51 @@
+package model
+
+import "time"
+
+// 评价状态
+//
+//	// 评价发布后即展示 (先发后审), 管理员审核通过或隐藏; 隐藏的评价不展示, 也不计入图书评分
+const (
+	ReviewStatusPending  = 0 // 待审核
+	ReviewStatusApproved = 1 // 已通过
+	ReviewStatusHidden   = 2 // 已隐藏
+)
+
+// Review 图书评价, 每个用户对每本图书只能评价一次
+type Review struct {
+	ID           uint64    `gorm:"primaryKey" json:"id"`
+	UserID       uint64    `gorm:"not null" json:"user_id"`
+	BookID       uint64    `gorm:"not null" json:"book_id"`
+	Rating       int       `gorm:"not null" json:"rating"`         // 评分 1-5 星
+	Content      string    `json:"content"`                        // 评价内容
+	HelpfulCount int       `gorm:"default:0" json:"helpful_count"` // 有用票数
+	Status       int       `gorm:"default:0" json:"status"`        // 状态：0-待审核，1-已通过，2-已隐藏
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+
+	IsVoted bool `gorm:"-" json:"is_voted"` // 当前用户是否已投有用票, 未登录时为 false
+
+	// 关联字段
+	User *User `gorm:"foreignKey:UserID" json:"user,omitempty"`
+	Book *Book `gorm:"foreignKey:BookID" json:"book,omitempty"`
+}
+
+func (r *Review) TableName() string {
+	return "reviews"
+}
+
+// Visible 评价是否展示并计入评分
+func (r *Review) Visible() bool {
+	return r.Status != ReviewStatusHidden
+}
+
+// ReviewVote 评价的有用票, 每个用户对每条评价只能投一次
+type ReviewVote struct {
+	ReviewID  uint64    `gorm:"primaryKey" json:"review_id"`
+	UserID    uint64    `gorm:"primaryKey" json:"user_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (v *ReviewVote) TableName() string {
+	return "review_votes"
+}
@@ -0,0 +1,41 @@
+package model
+
+import "time"
+
+// Role 角色模型
+type Role struct {
+	ID          uint64       `gorm:"primaryKey" json:"id"`
+	Name        string       `gorm:"not null;unique" json:"name"` // 角色标识: customer, admin
+	Description string       `json:"description"`                 // 角色描述
+	CreatedAt   time.Time    `json:"created_at"`
+	UpdatedAt   time.Time    `json:"updated_at"`
+	Permissions []Permission `gorm:"many2many:role_permissions" json:"permissions,omitempty"`
+}
+
+func (r *Role) TableName() string {
+	return "roles"
+}
+
+// Permission 权限模型
+type Permission struct {
+	ID          uint64    `gorm:"primaryKey" json:"id"`
+	Code        string    `gorm:"not null;unique" json:"code"` // 权限标识, 格式 资源:操作, 如 book:manage
+	Description string    `json:"description"`                 // 权限描述
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+func (p *Permission) TableName() string {
+	return "permissions"
+}
+
+// UserRole 用户-角色关联
+type UserRole struct {
+	UserID    uint64    `gorm:"primaryKey" json:"user_id"`
+	RoleID    uint64    `gorm:"primaryKey" json:"role_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (ur *UserRole) TableName() string {
+	return "user_roles"
+}
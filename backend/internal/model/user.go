@@ -13,7 +13,7 @@ type User struct {
 	Email     string    `gorm:"unique;not null" json:"email"`
 	Phone     string    `json:"phone"`
 	Avatar    string    `json:"avatar"`
-	IsAdmin   bool      `gorm:"default:false" json:"is_admin"` // 是否为管理员
+	IsAdmin   bool      `gorm:"default:false" json:"is_admin"` // 是否为管理员, 由角色分配同步, 权限只以 user_roles 为准
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
 }
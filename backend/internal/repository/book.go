@@ -6,6 +6,7 @@ import (
 	"github.com/wangn-tech/bookstore-go/common/result"
 	"github.com/wangn-tech/bookstore-go/internal/model"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type BookDao struct {
@@ -18,33 +19,81 @@ func NewBookDao(db *gorm.DB) *BookDao {
 	}
 }
 
-// DecreaseStockAndIncreaseSaleTx 原子扣减库存并增加销量
-func (b *BookDao) DecreaseStockAndIncreaseSaleTx(ctx context.Context, tx *gorm.DB, bookID uint64, qty int) error {
+// ReserveStockTx 下单时原子预占库存
+func (b *BookDao) ReserveStockTx(ctx context.Context, tx *gorm.DB, bookID uint64, qty int) error {
 	db := tx
 	if db == nil {
 		db = b.db
 	}
-	// UPDATE books SET stock = stock - :qty, sale  = sale  + :qty WHERE id = :book_id AND stock >= :qty;
+	// UPDATE books SET reserved = reserved + :qty WHERE id = :book_id AND status = 1 AND stock - reserved >= :qty;
 	// 单条 SQL 语句实现原子操作，避免超卖
-	// 	// 两个并发请求同时扣同一本书的库存时，InnoDB 对该行加锁，先提交者成功更新并减少库存
-	// 	// 后提交者因条件 stock >= qty 不再满足而更新 0 行，从而避免超卖
+	// 	// 两个并发下单请求同时预占同一本书时，InnoDB 对该行加锁，先提交者成功预占
+	// 	// 后提交者因条件 stock - reserved >= qty 不再满足而更新 0 行，下单失败
 	res := db.WithContext(ctx).
 		Model(&model.Book{}).
-		Where("id = ? AND stock >= ?", bookID, qty).
+		Where("id = ? AND status = ? AND stock - reserved >= ?", bookID, 1, qty).
+		Update("reserved", gorm.Expr("reserved + ?", qty))
+	if res.Error != nil {
+		return res.Error
+	}
+	// 没有受影响行表示可售库存不足、图书已下架或不存在
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// ReleaseStockTx 取消订单时释放预占库存
+func (b *BookDao) ReleaseStockTx(ctx context.Context, tx *gorm.DB, bookID uint64, qty int) error {
+	db := tx
+	if db == nil {
+		db = b.db
+	}
+	return db.WithContext(ctx).
+		Model(&model.Book{}).
+		Where("id = ?", bookID).
+		Update("reserved", gorm.Expr("GREATEST(reserved - ?, 0)", qty)).Error
+}
+
+// ConsumeReservedStockTx 支付时将预占库存转为销量: 扣减在库库存和预占库存, 增加销量
+func (b *BookDao) ConsumeReservedStockTx(ctx context.Context, tx *gorm.DB, bookID uint64, qty int) error {
+	db := tx
+	if db == nil {
+		db = b.db
+	}
+	res := db.WithContext(ctx).
+		Model(&model.Book{}).
+		Where("id = ? AND stock >= ? AND reserved >= ?", bookID, qty, qty).
 		Updates(map[string]any{
-			"stock": gorm.Expr("stock - ?", qty),
-			"sale":  gorm.Expr("sale + ?", qty),
+			"stock":    gorm.Expr("stock - ?", qty),
+			"reserved": gorm.Expr("reserved - ?", qty),
+			"sale":     gorm.Expr("sale + ?", qty),
 		})
 	if res.Error != nil {
 		return res.Error
 	}
-	// 没有受影响行表示库存不足或书不存在
+	// 没有受影响行表示预占记录与库存不一致或书不存在
 	if res.RowsAffected == 0 {
 		return gorm.ErrRecordNotFound
 	}
 	return nil
 }
 
+// IncreaseStockAndDecreaseSaleTx 退款时归还库存并扣减销量
+func (b *BookDao) IncreaseStockAndDecreaseSaleTx(ctx context.Context, tx *gorm.DB, bookID uint64, qty int) error {
+	db := tx
+	if db == nil {
+		db = b.db
+	}
+	return db.WithContext(ctx).
+		Model(&model.Book{}).
+		Where("id = ?", bookID).
+		Updates(map[string]any{
+			"stock": gorm.Expr("stock + ?", qty),
+			"sale":  gorm.Expr("GREATEST(sale - ?, 0)", qty),
+		}).Error
+}
+
 // GetBookByIDForAdmin 根据ID获取书籍信息（管理员用）, 不过滤 Status
 func (b *BookDao) GetBookByIDForAdmin(ctx context.Context, id uint64) (*model.Book, error) {
 	var book model.Book
@@ -55,13 +104,104 @@ func (b *BookDao) GetBookByIDForAdmin(ctx context.Context, id uint64) (*model.Bo
 	return &book, nil
 }
 
-// GetBooksByPage 分页获取书籍列表
-func (b *BookDao) GetBooksByPage(ctx context.Context, page int, pageSize int) (*result.PageResult[*model.Book], error) {
+// GetBooksByIDs 根据 ID 批量获取图书 (不限上下架状态)
+func (b *BookDao) GetBooksByIDs(ctx context.Context, ids []uint64) ([]*model.Book, error) {
+	var books []*model.Book
+	if len(ids) == 0 {
+		return books, nil
+	}
+	if err := b.db.WithContext(ctx).Where("id IN ?", ids).Find(&books).Error; err != nil {
+		return nil, err
+	}
+	return books, nil
+}
+
+// BookFilter 图书列表筛选和排序条件, 零值字段不参与筛选
+type BookFilter struct {
+	Status          *int     // 上下架状态
+	CategoryIDs     []uint64 // 分类 ID
+	MinPriceCents   *int     // 最低折后价（分）
+	MaxPriceCents   *int     // 最高折后价（分）
+	MinDiscount     *int     // 最低减免百分比
+	Language        string
+	Format          string
+	Publisher       string
+	PublishDateFrom string // 出版日期起, 格式 2006-01-02
+	PublishDateTo   string // 出版日期止, 格式 2006-01-02
+	InStock         bool   // 可售库存 > 0
+	Sort            string // price, sale, newest, rating
+	Desc            bool
+}
+
+// salePriceExpr 折后价（分）的 SQL 表达式, 与 model.Book.SalePriceInCents 一致
+const salePriceExpr = "price * (100 - discount)"
+
+// bookSortColumns 排序字段白名单, 只允许按这些表达式排序
+var bookSortColumns = map[string]string{
+	"price":  salePriceExpr,
+	"sale":   "sale",
+	"newest": "created_at",
+	"rating": "rating_avg",
+}
+
+// apply 将筛选条件应用到查询
+func (f *BookFilter) apply(query *gorm.DB) *gorm.DB {
+	if f.Status != nil {
+		query = query.Where("status = ?", *f.Status)
+	}
+	if len(f.CategoryIDs) > 0 {
+		query = query.Where("category_id IN ?", f.CategoryIDs)
+	}
+	if f.MinPriceCents != nil {
+		query = query.Where(salePriceExpr+" >= ?", *f.MinPriceCents)
+	}
+	if f.MaxPriceCents != nil {
+		query = query.Where(salePriceExpr+" <= ?", *f.MaxPriceCents)
+	}
+	if f.MinDiscount != nil {
+		query = query.Where("discount >= ?", *f.MinDiscount)
+	}
+	if f.Language != "" {
+		query = query.Where("language = ?", f.Language)
+	}
+	if f.Format != "" {
+		query = query.Where("format = ?", f.Format)
+	}
+	if f.Publisher != "" {
+		query = query.Where("publisher = ?", f.Publisher)
+	}
+	// publish_date 为 VARCHAR, 格式统一为 YYYY-MM-DD 时可按字符串比较
+	if f.PublishDateFrom != "" {
+		query = query.Where("publish_date >= ?", f.PublishDateFrom)
+	}
+	if f.PublishDateTo != "" {
+		query = query.Where("publish_date <= ?", f.PublishDateTo)
+	}
+	if f.InStock {
+		query = query.Where("stock - reserved > 0")
+	}
+	return query
+}
+
+// orderBy 排序子句, 未指定或不在白名单中时按 ID 排序; 追加 id 保证分页结果稳定
+func (f *BookFilter) orderBy() string {
+	column, ok := bookSortColumns[f.Sort]
+	if !ok {
+		return "id ASC"
+	}
+	if f.Desc {
+		return column + " DESC, id DESC"
+	}
+	return column + " ASC, id ASC"
+}
+
+// GetBooksByPage 按条件分页获取书籍列表
+func (b *BookDao) GetBooksByPage(ctx context.Context, filter *BookFilter, page int, pageSize int) (*result.PageResult[*model.Book], error) {
 	var total int64
 	var books []*model.Book
 
 	// 构建查询
-	query := b.db.WithContext(ctx).Model(&model.Book{})
+	query := filter.apply(b.db.WithContext(ctx).Model(&model.Book{}))
 
 	// 统计总数
 	if err := query.Count(&total).Error; err != nil {
@@ -69,7 +209,7 @@ func (b *BookDao) GetBooksByPage(ctx context.Context, page int, pageSize int) (*
 	}
 
 	// 分页查询
-	if err := query.Scopes(result.Paginate(&page, &pageSize)).Find(&books).Error; err != nil {
+	if err := query.Order(filter.orderBy()).Scopes(result.Paginate(&page, &pageSize)).Find(&books).Error; err != nil {
 		return nil, err
 	}
 
@@ -79,12 +219,15 @@ func (b *BookDao) GetBooksByPage(ctx context.Context, page int, pageSize int) (*
 	}, nil
 }
 
-// GetHotBooks 获取热销图书
-func (b *BookDao) GetHotBooks(ctx context.Context, limit int) ([]*model.Book, error) {
+// GetHotBooks 按累计销量获取热销图书, categoryIDs 为空时不限分类
+func (b *BookDao) GetHotBooks(ctx context.Context, categoryIDs []uint64, limit int) ([]*model.Book, error) {
 	var books []*model.Book
-	err := b.db.WithContext(ctx).Model(&model.Book{}).
-		Where("status = ?", 1).
-		Order("sale DESC").
+	query := b.db.WithContext(ctx).Model(&model.Book{}).
+		Where("status = ?", 1)
+	if len(categoryIDs) > 0 {
+		query = query.Where("category_id IN ?", categoryIDs)
+	}
+	err := query.Order("sale DESC").
 		Limit(limit).
 		Find(&books).Error
 	if err != nil {
@@ -117,39 +260,193 @@ func (b *BookDao) GetBookByID(ctx context.Context, id uint64) (*model.Book, erro
 	return &book, nil
 }
 
-// SearchBooksWithPagination 根据关键词搜索书籍，支持分页
-func (b *BookDao) SearchBooksWithPagination(ctx context.Context, keyword string, page int, pageSize int) (*result.PageResult[*model.Book], error) {
-	var total int64
+// GetBooksByType 根据图书类型 (type 字段) 获取书籍列表, 仅用于兼容旧的分类链接
+func (b *BookDao) GetBooksByType(ctx context.Context, bookType string) ([]*model.Book, error) {
 	var books []*model.Book
+	err := b.db.WithContext(ctx).Model(&model.Book{}).
+		Where("status = ? AND type = ?", 1, bookType).
+		Find(&books).Error
+	if err != nil {
+		return nil, err
+	}
+	return books, nil
+}
 
-	// 构建查询
-	query := b.db.WithContext(ctx).Model(&model.Book{}).
-		Where("title LIKE ? OR author LIKE ?", "%"+keyword+"%", "%"+keyword+"%")
-
-	// 统计总数
-	if err := query.Count(&total).Error; err != nil {
+// GetBooksByCategoryIDs 获取指定分类 (含子分类) 下的上架图书
+func (b *BookDao) GetBooksByCategoryIDs(ctx context.Context, categoryIDs []uint64) ([]*model.Book, error) {
+	var books []*model.Book
+	err := b.db.WithContext(ctx).Model(&model.Book{}).
+		Where("status = ? AND category_id IN ?", 1, categoryIDs).
+		Find(&books).Error
+	if err != nil {
 		return nil, err
 	}
+	return books, nil
+}
 
-	// 分页查询
-	if err := query.Scopes(result.Paginate(&page, &pageSize)).Find(&books).Error; err != nil {
+// GetBookForUpdateTx 在事务中查询图书并锁行
+func (b *BookDao) GetBookForUpdateTx(ctx context.Context, tx *gorm.DB, id uint64) (*model.Book, error) {
+	db := tx
+	if db == nil {
+		db = b.db
+	}
+	var book model.Book
+	err := db.WithContext(ctx).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		First(&book, id).Error
+	if err != nil {
 		return nil, err
 	}
+	return &book, nil
+}
 
-	return &result.PageResult[*model.Book]{
-		Total:   total,
-		Records: books,
-	}, nil
+// CreateBookTx 创建图书
+func (b *BookDao) CreateBookTx(ctx context.Context, tx *gorm.DB, book *model.Book) error {
+	db := tx
+	if db == nil {
+		db = b.db
+	}
+	return db.WithContext(ctx).Create(book).Error
 }
 
-// GetBooksByCategory 根据分类获取书籍列表
-func (b *BookDao) GetBooksByType(ctx context.Context, bookType string) ([]*model.Book, error) {
-	var books []*model.Book
+// UpdateBookTx 更新图书信息
+//
+//	// 预占库存和销量由下单、支付流程原子维护, 评分由评价维护, 不随图书信息覆盖
+func (b *BookDao) UpdateBookTx(ctx context.Context, tx *gorm.DB, book *model.Book) error {
+	db := tx
+	if db == nil {
+		db = b.db
+	}
+	return db.WithContext(ctx).Omit("reserved", "sale", "rating_avg", "rating_count").Save(book).Error
+}
+
+// UpdateBookStatusTx 更新图书上下架状态
+func (b *BookDao) UpdateBookStatusTx(ctx context.Context, tx *gorm.DB, id uint64, status int) error {
+	db := tx
+	if db == nil {
+		db = b.db
+	}
+	return db.WithContext(ctx).
+		Model(&model.Book{}).
+		Where("id = ?", id).
+		Update("status", status).Error
+}
+
+// UpdateSearchKeys 更新检索键, 不触发钩子、不修改 updated_at
+func (b *BookDao) UpdateSearchKeys(ctx context.Context, id uint64, keys string) error {
+	return b.db.WithContext(ctx).
+		Model(&model.Book{}).
+		Where("id = ?", id).
+		UpdateColumn("search_keys", keys).Error
+}
+
+// DeleteBookTx 删除图书
+func (b *BookDao) DeleteBookTx(ctx context.Context, tx *gorm.DB, id uint64) error {
+	db := tx
+	if db == nil {
+		db = b.db
+	}
+	res := db.WithContext(ctx).Delete(&model.Book{}, id)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// RefreshRatingTx 根据展示中的评价重新统计图书的平均评分和评分人数
+//
+//	// 在同一条 UPDATE 语句中统计并写入, 避免统计与写入之间的评价变更被覆盖
+func (b *BookDao) RefreshRatingTx(ctx context.Context, tx *gorm.DB, id uint64) error {
+	db := tx
+	if db == nil {
+		db = b.db
+	}
+	visible := "FROM reviews WHERE reviews.book_id = ? AND reviews.status <> ?"
+	return db.WithContext(ctx).
+		Model(&model.Book{}).
+		Where("id = ?", id).
+		UpdateColumns(map[string]any{
+			"rating_count": gorm.Expr("(SELECT COUNT(*) "+visible+")", id, model.ReviewStatusHidden),
+			"rating_avg":   gorm.Expr("(SELECT COALESCE(ROUND(AVG(rating), 2), 0) "+visible+")", id, model.ReviewStatusHidden),
+		}).Error
+}
+
+// HasOrderItems 检查图书是否已存在订单项
+func (b *BookDao) HasOrderItems(ctx context.Context, bookID uint64) (bool, error) {
+	var count int64
+	err := b.db.WithContext(ctx).Model(&model.OrderItem{}).
+		Where("book_id = ?", bookID).
+		Limit(1).
+		Count(&count).Error
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// BookISBN 图书的 ISBN 及 ISBN-13
+type BookISBN struct {
+	ID     uint64
+	ISBN   string
+	ISBN13 *string
+}
+
+// GetBookISBNs 获取全部图书的 ISBN 和 ISBN-13 (不限上下架状态), 按 ID 升序
+func (b *BookDao) GetBookISBNs(ctx context.Context) ([]*BookISBN, error) {
+	var rows []*BookISBN
 	err := b.db.WithContext(ctx).Model(&model.Book{}).
-		Where("status = ? AND type = ?", 1, bookType).
-		Find(&books).Error
+		Select("id", "isbn", "isbn13").
+		Where("isbn13 IS NOT NULL OR (isbn IS NOT NULL AND isbn <> '')").
+		Order("id ASC").
+		Scan(&rows).Error
 	if err != nil {
 		return nil, err
 	}
-	return books, nil
+	return rows, nil
+}
+
+// GetBookByISBN13 根据 ISBN-13 获取上架图书
+func (b *BookDao) GetBookByISBN13(ctx context.Context, isbn13 string) (*model.Book, error) {
+	var book model.Book
+	err := b.db.WithContext(ctx).Where("isbn13 = ? AND status = ?", isbn13, 1).First(&book).Error
+	if err != nil {
+		return nil, err
+	}
+	return &book, nil
+}
+
+// GetBookIDByISBN13Tx 获取使用该 ISBN-13 的图书 ID (不限上下架状态), 不存在时返回 0
+func (b *BookDao) GetBookIDByISBN13Tx(ctx context.Context, tx *gorm.DB, isbn13 string) (uint64, error) {
+	db := tx
+	if db == nil {
+		db = b.db
+	}
+	var ids []uint64
+	err := db.WithContext(ctx).Model(&model.Book{}).
+		Where("isbn13 = ?", isbn13).
+		Limit(1).
+		Pluck("id", &ids).Error
+	if err != nil || len(ids) == 0 {
+		return 0, err
+	}
+	return ids[0], nil
+}
+
+// UpdateISBN 更新 ISBN 和 ISBN-13, 不触发钩子、不修改 updated_at
+func (b *BookDao) UpdateISBN(ctx context.Context, id uint64, isbn string, isbn13 *string) error {
+	return b.db.WithContext(ctx).Model(&model.Book{}).
+		Where("id = ?", id).
+		UpdateColumns(map[string]any{"isbn": isbn, "isbn13": isbn13}).Error
+}
+
+// ScanBooks 按 ID 顺序分批遍历全部图书 (不限上下架状态)
+func (b *BookDao) ScanBooks(ctx context.Context, batchSize int, fn func(books []*model.Book) error) error {
+	var books []*model.Book
+	return b.db.WithContext(ctx).
+		FindInBatches(&books, batchSize, func(tx *gorm.DB, batch int) error {
+			return fn(books)
+		}).Error
 }
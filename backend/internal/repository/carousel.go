@@ -0,0 +1,92 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/wangn-tech/bookstore-go/internal/model"
+	"gorm.io/gorm"
+)
+
+type CarouselDao struct {
+	db *gorm.DB
+}
+
+func NewCarouselDao(db *gorm.DB) *CarouselDao {
+	return &CarouselDao{
+		db: db,
+	}
+}
+
+// GetActiveCarousels 获取当前应展示的轮播图: 已激活且处于展示时间窗口内, 按排序升序
+func (c *CarouselDao) GetActiveCarousels(ctx context.Context, now time.Time) ([]*model.Carousel, error) {
+	var carousels []*model.Carousel
+	err := c.db.WithContext(ctx).
+		Where("is_active = ?", true).
+		Where("start_time IS NULL OR start_time <= ?", now).
+		Where("end_time IS NULL OR end_time > ?", now).
+		Order("sort_order ASC, id ASC").
+		Find(&carousels).Error
+	if err != nil {
+		return nil, err
+	}
+	return carousels, nil
+}
+
+// GetAllCarousels 获取全部轮播图（管理员用）, 按排序升序
+func (c *CarouselDao) GetAllCarousels(ctx context.Context) ([]*model.Carousel, error) {
+	var carousels []*model.Carousel
+	err := c.db.WithContext(ctx).
+		Order("sort_order ASC, id ASC").
+		Find(&carousels).Error
+	if err != nil {
+		return nil, err
+	}
+	return carousels, nil
+}
+
+// GetCarouselByID 根据 ID 获取轮播图
+func (c *CarouselDao) GetCarouselByID(ctx context.Context, id uint64) (*model.Carousel, error) {
+	var carousel model.Carousel
+	if err := c.db.WithContext(ctx).First(&carousel, id).Error; err != nil {
+		return nil, err
+	}
+	return &carousel, nil
+}
+
+// CreateCarousel 创建轮播图
+func (c *CarouselDao) CreateCarousel(ctx context.Context, carousel *model.Carousel) error {
+	return c.db.WithContext(ctx).Create(carousel).Error
+}
+
+// UpdateCarousel 更新轮播图
+func (c *CarouselDao) UpdateCarousel(ctx context.Context, carousel *model.Carousel) error {
+	return c.db.WithContext(ctx).Save(carousel).Error
+}
+
+// DeleteCarousel 删除轮播图
+func (c *CarouselDao) DeleteCarousel(ctx context.Context, id uint64) error {
+	res := c.db.WithContext(ctx).Delete(&model.Carousel{}, id)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// UpdateSortOrders 批量更新排序, sortOrders: 轮播图 ID → 排序值
+func (c *CarouselDao) UpdateSortOrders(ctx context.Context, sortOrders map[uint64]int) error {
+	return c.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for id, sortOrder := range sortOrders {
+			err := tx.Model(&model.Carousel{}).
+				Where("id = ?", id).
+				Update("sort_order", sortOrder).Error
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,62 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/wangn-tech/bookstore-go/internal/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type CartDao struct {
+	db *gorm.DB
+}
+
+func NewCartDao(db *gorm.DB) *CartDao {
+	return &CartDao{
+		db: db,
+	}
+}
+
+// GetCartItems 获取用户购物车的全部商品, 按加入时间排序
+func (c *CartDao) GetCartItems(ctx context.Context, userID uint64) ([]*model.CartItem, error) {
+	var items []*model.CartItem
+	err := c.db.WithContext(ctx).
+		Where("user_id = ?", userID).
+		Order("created_at ASC, id ASC").
+		Find(&items).Error
+	if err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// SaveCartItem 设置购物车商品数量, 不存在时新增
+func (c *CartDao) SaveCartItem(ctx context.Context, userID uint64, bookID uint64, quantity int) error {
+	item := &model.CartItem{
+		UserID:   userID,
+		BookID:   bookID,
+		Quantity: quantity,
+	}
+	return c.db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "user_id"}, {Name: "book_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"quantity", "updated_at"}),
+		}).
+		Create(item).Error
+}
+
+// DeleteCartItems 从购物车移除指定图书
+func (c *CartDao) DeleteCartItems(ctx context.Context, userID uint64, bookIDs ...uint64) (int64, error) {
+	res := c.db.WithContext(ctx).
+		Where("user_id = ? AND book_id IN ?", userID, bookIDs).
+		Delete(&model.CartItem{})
+	return res.RowsAffected, res.Error
+}
+
+// ClearCart 清空用户购物车
+func (c *CartDao) ClearCart(ctx context.Context, userID uint64) error {
+	return c.db.WithContext(ctx).
+		Where("user_id = ?", userID).
+		Delete(&model.CartItem{}).Error
+}
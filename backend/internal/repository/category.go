@@ -5,6 +5,7 @@ import (
 
 	"github.com/wangn-tech/bookstore-go/internal/model"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type CategoryDao struct {
@@ -17,10 +18,27 @@ func NewCategoryDao(db *gorm.DB) *CategoryDao {
 	}
 }
 
-// GetAllCategories 获取所有分类
+// GetAllCategories 获取所有分类, 按排序权重升序
 func (r *CategoryDao) GetAllCategories(ctx context.Context) ([]*model.Category, error) {
 	var categories []*model.Category
-	if err := r.db.WithContext(ctx).Find(&categories).Error; err != nil {
+	if err := r.db.WithContext(ctx).Order("sort ASC, id ASC").Find(&categories).Error; err != nil {
+		return nil, err
+	}
+	return categories, nil
+}
+
+// GetAllCategoriesForUpdateTx 在事务中按 ID 顺序锁定并获取全部分类, 用于修改分类树结构时串行化
+func (r *CategoryDao) GetAllCategoriesForUpdateTx(ctx context.Context, tx *gorm.DB) ([]*model.Category, error) {
+	db := tx
+	if db == nil {
+		db = r.db
+	}
+	var categories []*model.Category
+	err := db.WithContext(ctx).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Order("id ASC").
+		Find(&categories).Error
+	if err != nil {
 		return nil, err
 	}
 	return categories, nil
@@ -35,17 +53,106 @@ func (r *CategoryDao) GetCategoryByID(ctx context.Context, id uint64) (*model.Ca
 	return &category, nil
 }
 
+// GetCategoryByName 根据名称获取分类
+func (r *CategoryDao) GetCategoryByName(ctx context.Context, name string) (*model.Category, error) {
+	var category model.Category
+	if err := r.db.WithContext(ctx).Where("name = ?", name).First(&category).Error; err != nil {
+		return nil, err
+	}
+	return &category, nil
+}
+
 // CreateCategory 创建分类
 func (r *CategoryDao) CreateCategory(ctx context.Context, category *model.Category) error {
 	return r.db.WithContext(ctx).Create(category).Error
 }
 
 // UpdateCategory 更新分类
+//
+//	// 父分类只能通过 UpdateParentTx 修改 (需要校验循环), 图书数量由系统维护
 func (r *CategoryDao) UpdateCategory(ctx context.Context, category *model.Category) error {
-	return r.db.WithContext(ctx).Save(category).Error
+	return r.db.WithContext(ctx).Omit("parent_id", "book_count", "created_at").Save(category).Error
+}
+
+// UpdateParentTx 修改父分类, parentID 为 nil 表示移动为顶级分类
+func (r *CategoryDao) UpdateParentTx(ctx context.Context, tx *gorm.DB, id uint64, parentID *uint64) error {
+	db := tx
+	if db == nil {
+		db = r.db
+	}
+	return db.WithContext(ctx).
+		Model(&model.Category{}).
+		Where("id = ?", id).
+		Update("parent_id", parentID).Error
+}
+
+// HasChildren 检查分类是否存在子分类
+func (r *CategoryDao) HasChildren(ctx context.Context, id uint64) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&model.Category{}).
+		Where("parent_id = ?", id).
+		Limit(1).
+		Count(&count).Error
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
 }
 
 // DeleteCategory 删除分类
 func (r *CategoryDao) DeleteCategory(ctx context.Context, id uint64) error {
-	return r.db.WithContext(ctx).Delete(&model.Category{}, id).Error
+	res := r.db.WithContext(ctx).Delete(&model.Category{}, id)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// IncrBookCountTx 调整分类的图书数量
+func (r *CategoryDao) IncrBookCountTx(ctx context.Context, tx *gorm.DB, id uint64, delta int) error {
+	db := tx
+	if db == nil {
+		db = r.db
+	}
+	return db.WithContext(ctx).
+		Model(&model.Category{}).
+		Where("id = ?", id).
+		Update("book_count", gorm.Expr("GREATEST(book_count + ?, 0)", delta)).Error
+}
+
+// CategoryBookCount 分类记录的图书数量与实际上架图书数量
+type CategoryBookCount struct {
+	ID        uint64
+	Name      string
+	BookCount int // 分类表中记录的数量
+	Actual    int // 根据 books 统计的上架图书数量
+}
+
+// GetCategoryBookCounts 统计每个分类实际的上架图书数量
+func (r *CategoryDao) GetCategoryBookCounts(ctx context.Context) ([]*CategoryBookCount, error) {
+	var counts []*CategoryBookCount
+	err := r.db.WithContext(ctx).
+		Table("categories c").
+		Select("c.id, c.name, c.book_count, COUNT(b.id) AS actual").
+		Joins("LEFT JOIN books b ON b.category_id = c.id AND b.status = ?", 1).
+		Group("c.id, c.name, c.book_count").
+		Order("c.id ASC").
+		Scan(&counts).Error
+	if err != nil {
+		return nil, err
+	}
+	return counts, nil
+}
+
+// RecountBookCount 根据 books 重新统计分类的上架图书数量并写回
+//
+//	// 在同一条 UPDATE 语句中统计并写入, 避免统计与写入之间的图书变更被覆盖
+func (r *CategoryDao) RecountBookCount(ctx context.Context, id uint64) error {
+	return r.db.WithContext(ctx).
+		Model(&model.Category{}).
+		Where("id = ?", id).
+		Update("book_count", gorm.Expr("(SELECT COUNT(*) FROM books WHERE books.category_id = ? AND books.status = ?)", id, 1)).Error
 }
@@ -0,0 +1,114 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/wangn-tech/bookstore-go/common/result"
+	"github.com/wangn-tech/bookstore-go/internal/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type FavoriteDao struct {
+	db *gorm.DB
+}
+
+func NewFavoriteDao(db *gorm.DB) *FavoriteDao {
+	return &FavoriteDao{
+		db: db,
+	}
+}
+
+// AddFavorite 收藏图书, 已收藏时不重复插入 (unique_user_book)
+func (f *FavoriteDao) AddFavorite(ctx context.Context, userID uint64, bookID uint64) error {
+	return f.db.WithContext(ctx).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&model.Favorite{UserID: userID, BookID: bookID}).Error
+}
+
+// RemoveFavorite 取消收藏, 未收藏时返回 gorm.ErrRecordNotFound
+func (f *FavoriteDao) RemoveFavorite(ctx context.Context, userID uint64, bookID uint64) error {
+	res := f.db.WithContext(ctx).
+		Where("user_id = ? AND book_id = ?", userID, bookID).
+		Delete(&model.Favorite{})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// GetUserFavoritesByPage 分页获取用户的收藏, 预加载图书, 按收藏时间倒序
+func (f *FavoriteDao) GetUserFavoritesByPage(ctx context.Context, userID uint64, page int, pageSize int) (*result.PageResult[*model.Favorite], error) {
+	var total int64
+	var favorites []*model.Favorite
+
+	query := f.db.WithContext(ctx).Model(&model.Favorite{}).Where("user_id = ?", userID)
+	if err := query.Count(&total).Error; err != nil {
+		return nil, err
+	}
+	if err := query.Preload("Book").
+		Order("created_at DESC, id DESC").
+		Scopes(result.Paginate(&page, &pageSize)).
+		Find(&favorites).Error; err != nil {
+		return nil, err
+	}
+
+	return &result.PageResult[*model.Favorite]{
+		Total:   total,
+		Records: favorites,
+	}, nil
+}
+
+// GetFavoritedBookIDs 返回 bookIDs 中已被用户收藏的图书 ID
+func (f *FavoriteDao) GetFavoritedBookIDs(ctx context.Context, userID uint64, bookIDs []uint64) (map[uint64]bool, error) {
+	var ids []uint64
+	err := f.db.WithContext(ctx).Model(&model.Favorite{}).
+		Where("user_id = ? AND book_id IN ?", userID, bookIDs).
+		Pluck("book_id", &ids).Error
+	if err != nil {
+		return nil, err
+	}
+	favorited := make(map[uint64]bool, len(ids))
+	for _, id := range ids {
+		favorited[id] = true
+	}
+	return favorited, nil
+}
+
+// CountByBookIDs 统计每本图书的收藏数
+func (f *FavoriteDao) CountByBookIDs(ctx context.Context, bookIDs []uint64) (map[uint64]int64, error) {
+	var rows []struct {
+		BookID uint64
+		Count  int64
+	}
+	err := f.db.WithContext(ctx).Model(&model.Favorite{}).
+		Select("book_id, COUNT(*) AS count").
+		Where("book_id IN ?", bookIDs).
+		Group("book_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	counts := make(map[uint64]int64, len(rows))
+	for _, row := range rows {
+		counts[row.BookID] = row.Count
+	}
+	return counts, nil
+}
+
+// GetFavoriteBookIDs 获取用户收藏的图书 ID, 按收藏时间倒序
+func (f *FavoriteDao) GetFavoriteBookIDs(ctx context.Context, userID uint64, limit int) ([]uint64, error) {
+	var ids []uint64
+	err := f.db.WithContext(ctx).Model(&model.Favorite{}).
+		Where("user_id = ?", userID).
+		Order("created_at DESC, id DESC").
+		Limit(limit).
+		Pluck("book_id", &ids).Error
+	if err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
@@ -10,6 +10,9 @@ import (
 	"gorm.io/gorm/clause"
 )
 
+// paidOrderStatuses 计入购买记录的订单状态: 已支付、已发货、已送达、已完成 (不含已退款)
+var paidOrderStatuses = []int{model.OrderStatusPaid, model.OrderStatusShipped, model.OrderStatusDelivered, model.OrderStatusCompleted}
+
 type OrderDao struct {
 	db *gorm.DB
 }
@@ -36,7 +39,7 @@ func (o *OrderDao) GetOrderStatistics(ctx context.Context, userID uint64) (map[s
 			"COUNT(*) AS total_orders",
 			"COALESCE(SUM(total_amount), 0) AS total_amount",
 			"SUM(CASE WHEN is_paid = TRUE THEN 1 ELSE 0 END) AS paid_orders",
-			"COUNT(*) - SUM(CASE WHEN is_paid = TRUE THEN 1 ELSE 0 END) AS pending_orders",
+			"COALESCE(SUM(CASE WHEN status = 0 THEN 1 ELSE 0 END), 0) AS pending_orders",
 		).
 		Where("user_id = ?", userID).
 		Scan(&stats).Error
@@ -54,8 +57,8 @@ func (o *OrderDao) GetOrderStatistics(ctx context.Context, userID uint64) (map[s
 
 }
 
-// GetOrderWithItemsForUpdate 在事务中查询订单并锁行（含订单项）
-func (o *OrderDao) GetOrderWithItemsForUpdate(ctx context.Context, tx *gorm.DB, id uint64) (*model.Order, error) {
+// GetOrderWithItemsForUpdate 在事务中查询用户的订单并锁行（含订单项）
+func (o *OrderDao) GetOrderWithItemsForUpdate(ctx context.Context, tx *gorm.DB, id uint64, userID uint64) (*model.Order, error) {
 	db := tx
 	if db == nil {
 		db = o.db
@@ -64,6 +67,23 @@ func (o *OrderDao) GetOrderWithItemsForUpdate(ctx context.Context, tx *gorm.DB,
 	err := db.WithContext(ctx).
 		Clauses(clause.Locking{Strength: "UPDATE"}). // FOR UPDATE 行级锁
 		Preload("OrderItems").                       // 预加载 OrderItems，不锁
+		First(&order, "id = ? AND user_id = ?", id, userID).Error
+	if err != nil {
+		return nil, err
+	}
+	return &order, nil
+}
+
+// GetOrderWithItemsForUpdateByAdmin 在事务中查询订单并锁行（含订单项）, 不校验订单归属
+func (o *OrderDao) GetOrderWithItemsForUpdateByAdmin(ctx context.Context, tx *gorm.DB, id uint64) (*model.Order, error) {
+	db := tx
+	if db == nil {
+		db = o.db
+	}
+	var order model.Order
+	err := db.WithContext(ctx).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Preload("OrderItems").
 		First(&order, "id = ?", id).Error
 	if err != nil {
 		return nil, err
@@ -71,27 +91,88 @@ func (o *OrderDao) GetOrderWithItemsForUpdate(ctx context.Context, tx *gorm.DB,
 	return &order, nil
 }
 
-// MarkPaidTx 标记订单已支付（幂等：仅当未支付才更新）
-func (o *OrderDao) MarkPaidTx(ctx context.Context, tx *gorm.DB, id uint64, paidAt time.Time) error {
+// GetExpiredPendingOrderIDs 获取创建时间早于 before 的待支付订单 ID
+func (o *OrderDao) GetExpiredPendingOrderIDs(ctx context.Context, before time.Time, limit int) ([]uint64, error) {
+	var ids []uint64
+	err := o.db.WithContext(ctx).
+		Model(&model.Order{}).
+		Where("status = ? AND created_at < ?", model.OrderStatusPending, before).
+		Order("id ASC").
+		Limit(limit).
+		Pluck("id", &ids).Error
+	if err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+// GetExpiredPendingOrderForUpdate 在事务中锁定超时的待支付订单（含订单项）
+//
+//	// FOR UPDATE SKIP LOCKED: 已被其他事务锁定 (买家正在支付或其他副本正在取消) 的订单直接跳过
+//	// 订单已不是待支付状态或未超时时返回 gorm.ErrRecordNotFound
+func (o *OrderDao) GetExpiredPendingOrderForUpdate(ctx context.Context, tx *gorm.DB, id uint64, before time.Time) (*model.Order, error) {
 	db := tx
 	if db == nil {
 		db = o.db
 	}
+	var order model.Order
+	err := db.WithContext(ctx).
+		Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+		Preload("OrderItems").
+		Where("status = ? AND created_at < ?", model.OrderStatusPending, before).
+		First(&order, "id = ?", id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &order, nil
+}
+
+// UpdateStatusTx 更新订单状态（乐观校验：仅当当前状态为 from 时更新）
+func (o *OrderDao) UpdateStatusTx(ctx context.Context, tx *gorm.DB, id uint64, from int, to int, extra map[string]any) error {
+	db := tx
+	if db == nil {
+		db = o.db
+	}
+	updates := map[string]any{"status": to}
+	for k, v := range extra {
+		updates[k] = v
+	}
 	res := db.WithContext(ctx).
 		Model(&model.Order{}).
-		Where("id = ? AND is_paid = ?", id, false). // 仅当未支付时更新, is_paid: false -> true
-		Updates(map[string]any{
-			"status":       1,
-			"is_paid":      true,
-			"payment_time": paidAt,
-		})
+		Where("id = ? AND status = ?", id, from).
+		Updates(updates)
 	if res.Error != nil {
 		return res.Error
 	}
-	// RowsAffected 为 0 说明已支付或不存在
+	// 没有受影响行表示状态已被并发修改
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
 	return nil
 }
 
+// CreateStatusHistoryTx 记录订单状态变更
+func (o *OrderDao) CreateStatusHistoryTx(ctx context.Context, tx *gorm.DB, history *model.OrderStatusHistory) error {
+	db := tx
+	if db == nil {
+		db = o.db
+	}
+	return db.WithContext(ctx).Create(history).Error
+}
+
+// GetStatusHistory 获取订单的状态变更记录, 按时间正序
+func (o *OrderDao) GetStatusHistory(ctx context.Context, orderID uint64) ([]*model.OrderStatusHistory, error) {
+	var histories []*model.OrderStatusHistory
+	err := o.db.WithContext(ctx).
+		Where("order_id = ?", orderID).
+		Order("id ASC").
+		Find(&histories).Error
+	if err != nil {
+		return nil, err
+	}
+	return histories, nil
+}
+
 // GetUserOrdersByPage 获取用户的订单列表，支持分页
 func (o *OrderDao) GetUserOrdersByPage(ctx context.Context, userID uint64, page int, pageSize int) (*result.PageResult[*model.Order], error) {
 	var total int64
@@ -124,7 +205,42 @@ func (o *OrderDao) GetUserOrdersByPage(ctx context.Context, userID uint64, page
 	}, nil
 }
 
-// GetOrderByID 根据 ID 获取订单详情
+// GetUserOrderByID 根据 ID 获取用户的订单详情, 订单不属于该用户时返回 gorm.ErrRecordNotFound
+func (o *OrderDao) GetUserOrderByID(ctx context.Context, id uint64, userID uint64) (*model.Order, error) {
+	var order model.Order
+	err := o.db.WithContext(ctx).
+		Preload("OrderItems.Book").
+		Where("user_id = ?", userID).
+		First(&order, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &order, nil
+}
+
+// GetOrdersByPage 分页获取全部订单（管理员用）
+func (o *OrderDao) GetOrdersByPage(ctx context.Context, page int, pageSize int) (*result.PageResult[*model.Order], error) {
+	var total int64
+	var orders []*model.Order
+
+	query := o.db.WithContext(ctx).Model(&model.Order{})
+	if err := query.Count(&total).Error; err != nil {
+		return nil, err
+	}
+	if err := query.Preload("OrderItems.Book").
+		Order("created_at DESC").
+		Scopes(result.Paginate(&page, &pageSize)).
+		Find(&orders).Error; err != nil {
+		return nil, err
+	}
+
+	return &result.PageResult[*model.Order]{
+		Total:   total,
+		Records: orders,
+	}, nil
+}
+
+// GetOrderByID 根据 ID 获取订单详情（管理员用）, 不校验订单归属
 func (o *OrderDao) GetOrderByID(ctx context.Context, id uint64) (*model.Order, error) {
 	var order model.Order
 	// 关联预加载 Preload
@@ -140,20 +256,87 @@ func (o *OrderDao) CreateOrder(ctx context.Context, order *model.Order) error {
 	return o.db.WithContext(ctx).Create(order).Error
 }
 
-// CreateOrderWithItems 创建订单及其订单项，使用事务
-func (o *OrderDao) CreateOrderWithItems(ctx context.Context, order *model.Order, items []*model.OrderItem) error {
-	return o.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
-		// 创建订单
-		if err := tx.Create(order).Error; err != nil {
+// CreateOrderWithItemsTx 在事务中创建订单及其订单项
+func (o *OrderDao) CreateOrderWithItemsTx(ctx context.Context, tx *gorm.DB, order *model.Order, items []*model.OrderItem) error {
+	db := tx
+	if db == nil {
+		db = o.db
+	}
+	db = db.WithContext(ctx)
+	// 创建订单
+	if err := db.Create(order).Error; err != nil {
+		return err
+	}
+	// 创建订单项
+	for _, item := range items {
+		item.OrderID = order.ID
+		if err := db.Create(item).Error; err != nil {
 			return err
 		}
-		// 创建订单项
-		for _, item := range items {
-			item.OrderID = order.ID
-			if err := tx.Create(item).Error; err != nil {
-				return err
-			}
-		}
-		return nil
-	})
+	}
+	// 记录订单创建
+	return db.Create(&model.OrderStatusHistory{
+		OrderID:      order.ID,
+		ToStatus:     order.Status,
+		OperatorType: model.OperatorUser,
+		OperatorID:   order.UserID,
+		Remark:       "创建订单",
+	}).Error
+}
+
+// HasPaidOrderItem 检查用户是否有包含该图书的已支付订单
+func (o *OrderDao) HasPaidOrderItem(ctx context.Context, userID uint64, bookID uint64) (bool, error) {
+	var count int64
+	err := o.db.WithContext(ctx).Model(&model.OrderItem{}).
+		Joins("JOIN orders ON orders.id = order_items.order_id").
+		Where("orders.user_id = ? AND order_items.book_id = ?", userID, bookID).
+		Where("orders.status IN ?", paidOrderStatuses).
+		Limit(1).
+		Count(&count).Error
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// GetPaidBookIDs 获取用户已支付订单中购买过的图书 ID, 按最近购买时间倒序
+func (o *OrderDao) GetPaidBookIDs(ctx context.Context, userID uint64, limit int) ([]uint64, error) {
+	var ids []uint64
+	err := o.db.WithContext(ctx).Model(&model.OrderItem{}).
+		Joins("JOIN orders ON orders.id = order_items.order_id").
+		Where("orders.user_id = ? AND orders.status IN ?", userID, paidOrderStatuses).
+		Group("order_items.book_id").
+		Order("MAX(orders.created_at) DESC").
+		Limit(limit).
+		Pluck("order_items.book_id", &ids).Error
+	if err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+// BookDailySale 图书单日销量
+type BookDailySale struct {
+	Day        string // 支付日期, 格式 yyyymmdd
+	BookID     uint64
+	CategoryID uint64 // 图书当前所属分类, 未分类为 0
+	Quantity   int
+}
+
+// GetDailyBookSales 按支付日期统计 since 之后已支付订单中各图书的销量 (不含已退款订单)
+//
+//	// 支付日期按数据库会话时区计算, 需与应用时区一致
+func (o *OrderDao) GetDailyBookSales(ctx context.Context, since time.Time) ([]*BookDailySale, error) {
+	var sales []*BookDailySale
+	err := o.db.WithContext(ctx).Model(&model.OrderItem{}).
+		Select("DATE_FORMAT(orders.payment_time, '%Y%m%d') AS day, order_items.book_id, COALESCE(books.category_id, 0) AS category_id, SUM(order_items.quantity) AS quantity").
+		Joins("JOIN orders ON orders.id = order_items.order_id").
+		Joins("JOIN books ON books.id = order_items.book_id").
+		Where("orders.status IN ? AND orders.payment_time >= ?", paidOrderStatuses, since).
+		Group("day, order_items.book_id, books.category_id").
+		Scan(&sales).Error
+	if err != nil {
+		return nil, err
+	}
+	return sales, nil
 }
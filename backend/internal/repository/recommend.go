@@ -0,0 +1,146 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/wangn-tech/bookstore-go/internal/model"
+	"gorm.io/gorm"
+)
+
+// similarityInsertBatchSize 相似度批量写入的每批行数
+const similarityInsertBatchSize = 500
+
+type RecommendDao struct {
+	db *gorm.DB
+}
+
+func NewRecommendDao(db *gorm.DB) *RecommendDao {
+	return &RecommendDao{
+		db: db,
+	}
+}
+
+// ScanPurchases 按用户顺序遍历已支付订单中的 (用户, 图书) 购买记录, 同一用户多次购买同一本书只返回一次
+func (r *RecommendDao) ScanPurchases(ctx context.Context, fn func(userID uint64, bookID uint64) error) error {
+	rows, err := r.db.WithContext(ctx).
+		Table("order_items").
+		Select("DISTINCT orders.user_id, order_items.book_id").
+		Joins("JOIN orders ON orders.id = order_items.order_id").
+		Where("orders.status IN ?", paidOrderStatuses).
+		Order("orders.user_id, order_items.book_id").
+		Rows()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var userID, bookID uint64
+		if err := rows.Scan(&userID, &bookID); err != nil {
+			return err
+		}
+		if err := fn(userID, bookID); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// ReplaceSimilaritiesTx 清空并写入全部相似度, 在事务中执行时读请求在提交前看到的仍是旧数据
+func (r *RecommendDao) ReplaceSimilaritiesTx(ctx context.Context, tx *gorm.DB, similarities []*model.BookSimilarity) error {
+	db := tx
+	if db == nil {
+		db = r.db
+	}
+	if err := db.WithContext(ctx).Where("1 = 1").Delete(&model.BookSimilarity{}).Error; err != nil {
+		return err
+	}
+	if len(similarities) == 0 {
+		return nil
+	}
+	return db.WithContext(ctx).CreateInBatches(similarities, similarityInsertBatchSize).Error
+}
+
+// GetRelatedBooks 获取与图书相似度最高的上架图书
+func (r *RecommendDao) GetRelatedBooks(ctx context.Context, bookID uint64, limit int) ([]*model.Book, error) {
+	var books []*model.Book
+	err := r.db.WithContext(ctx).
+		Model(&model.Book{}).
+		Joins("JOIN book_similarities ON book_similarities.similar_book_id = books.id").
+		Where("book_similarities.book_id = ? AND books.status = ?", bookID, 1).
+		Order("book_similarities.score DESC, books.sale DESC").
+		Limit(limit).
+		Find(&books).Error
+	if err != nil {
+		return nil, err
+	}
+	return books, nil
+}
+
+// GetSimilarToBooks 按与 seedIDs 的相似度之和排序获取上架图书, 排除 excludeIDs
+func (r *RecommendDao) GetSimilarToBooks(ctx context.Context, seedIDs []uint64, excludeIDs []uint64, limit int) ([]*model.Book, error) {
+	var books []*model.Book
+	if len(seedIDs) == 0 {
+		return books, nil
+	}
+	query := r.db.WithContext(ctx).
+		Model(&model.Book{}).
+		Select("books.*").
+		Joins("JOIN book_similarities ON book_similarities.similar_book_id = books.id").
+		Where("book_similarities.book_id IN ? AND books.status = ?", seedIDs, 1)
+	if len(excludeIDs) > 0 {
+		query = query.Where("books.id NOT IN ?", excludeIDs)
+	}
+	// books.id 为主键, 按其分组后可直接选取 books.*
+	err := query.Group("books.id").
+		Order("SUM(book_similarities.score) DESC, books.sale DESC").
+		Limit(limit).
+		Find(&books).Error
+	if err != nil {
+		return nil, err
+	}
+	return books, nil
+}
+
+// GetCategoryBestsellers 获取指定分类下销量最高的上架图书, 排除 excludeIDs
+func (r *RecommendDao) GetCategoryBestsellers(ctx context.Context, categoryIDs []uint64, excludeIDs []uint64, limit int) ([]*model.Book, error) {
+	var books []*model.Book
+	if len(categoryIDs) == 0 {
+		return books, nil
+	}
+	query := r.db.WithContext(ctx).
+		Model(&model.Book{}).
+		Where("category_id IN ? AND status = ?", categoryIDs, 1)
+	if len(excludeIDs) > 0 {
+		query = query.Where("id NOT IN ?", excludeIDs)
+	}
+	err := query.Order("sale DESC, id DESC").
+		Limit(limit).
+		Find(&books).Error
+	if err != nil {
+		return nil, err
+	}
+	return books, nil
+}
+
+// GetBestsellersAcrossCategories 各分类销量榜交替排列: 先取每个分类的第一名, 再取第二名, 依此类推
+//
+//	// 相比全站销量榜, 结果覆盖更多分类, 用于没有任何购买和收藏记录的用户
+func (r *RecommendDao) GetBestsellersAcrossCategories(ctx context.Context, excludeIDs []uint64, limit int) ([]*model.Book, error) {
+	var books []*model.Book
+	ranked := r.db.WithContext(ctx).
+		Model(&model.Book{}).
+		Select("books.*, ROW_NUMBER() OVER (PARTITION BY category_id ORDER BY sale DESC, id DESC) AS category_rank").
+		Where("status = ?", 1)
+	if len(excludeIDs) > 0 {
+		ranked = ranked.Where("id NOT IN ?", excludeIDs)
+	}
+	err := r.db.WithContext(ctx).
+		Table("(?) AS ranked", ranked).
+		Order("category_rank ASC, sale DESC, id DESC").
+		Limit(limit).
+		Find(&books).Error
+	if err != nil {
+		return nil, err
+	}
+	return books, nil
+}
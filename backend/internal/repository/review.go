@@ -0,0 +1,266 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/wangn-tech/bookstore-go/common/result"
+	"github.com/wangn-tech/bookstore-go/internal/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ReviewDao struct {
+	db *gorm.DB
+}
+
+func NewReviewDao(db *gorm.DB) *ReviewDao {
+	return &ReviewDao{
+		db: db,
+	}
+}
+
+// CreateReviewTx 创建评价, 同一用户重复评价同一本书时违反唯一键 uk_user_book
+func (r *ReviewDao) CreateReviewTx(ctx context.Context, tx *gorm.DB, review *model.Review) error {
+	db := tx
+	if db == nil {
+		db = r.db
+	}
+	return db.WithContext(ctx).Create(review).Error
+}
+
+// GetReviewByID 根据 ID 获取评价, 预加载评价用户的公开信息
+func (r *ReviewDao) GetReviewByID(ctx context.Context, id uint64) (*model.Review, error) {
+	var review model.Review
+	if err := r.db.WithContext(ctx).Scopes(preloadReviewer).First(&review, id).Error; err != nil {
+		return nil, err
+	}
+	return &review, nil
+}
+
+// GetReviewForUpdateTx 在事务中查询评价并锁行
+func (r *ReviewDao) GetReviewForUpdateTx(ctx context.Context, tx *gorm.DB, id uint64) (*model.Review, error) {
+	db := tx
+	if db == nil {
+		db = r.db
+	}
+	var review model.Review
+	err := db.WithContext(ctx).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		First(&review, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &review, nil
+}
+
+// ExistsUserReview 检查用户是否已评价该图书
+func (r *ReviewDao) ExistsUserReview(ctx context.Context, userID uint64, bookID uint64) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&model.Review{}).
+		Where("user_id = ? AND book_id = ?", userID, bookID).
+		Limit(1).
+		Count(&count).Error
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// UpdateReviewTx 更新评价的评分、内容和状态
+func (r *ReviewDao) UpdateReviewTx(ctx context.Context, tx *gorm.DB, review *model.Review) error {
+	db := tx
+	if db == nil {
+		db = r.db
+	}
+	return db.WithContext(ctx).
+		Model(review).
+		Select("rating", "content", "status").
+		Updates(review).Error
+}
+
+// UpdateReviewStatusTx 更新评价的审核状态
+func (r *ReviewDao) UpdateReviewStatusTx(ctx context.Context, tx *gorm.DB, id uint64, status int) error {
+	db := tx
+	if db == nil {
+		db = r.db
+	}
+	return db.WithContext(ctx).
+		Model(&model.Review{}).
+		Where("id = ?", id).
+		Update("status", status).Error
+}
+
+// DeleteReviewTx 删除评价, 有用票随外键级联删除
+func (r *ReviewDao) DeleteReviewTx(ctx context.Context, tx *gorm.DB, id uint64) error {
+	db := tx
+	if db == nil {
+		db = r.db
+	}
+	res := db.WithContext(ctx).Delete(&model.Review{}, id)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// ReviewFilter 评价列表的筛选和排序条件, 零值字段不参与筛选
+type ReviewFilter struct {
+	BookID      uint64
+	UserID      uint64
+	Rating      int    // 评分 1-5
+	Status      *int   // 审核状态
+	VisibleOnly bool   // 只包含展示中的评价 (未隐藏)
+	Sort        string // newest 最新, helpful 最有用, 默认最新
+	WithBook    bool   // 预加载图书
+}
+
+// reviewSortColumns 排序白名单
+var reviewSortColumns = map[string]string{
+	"newest":  "created_at DESC, id DESC",
+	"helpful": "helpful_count DESC, created_at DESC, id DESC",
+}
+
+// apply 将筛选条件应用到查询
+func (f *ReviewFilter) apply(query *gorm.DB) *gorm.DB {
+	if f.BookID != 0 {
+		query = query.Where("book_id = ?", f.BookID)
+	}
+	if f.UserID != 0 {
+		query = query.Where("user_id = ?", f.UserID)
+	}
+	if f.Rating != 0 {
+		query = query.Where("rating = ?", f.Rating)
+	}
+	if f.Status != nil {
+		query = query.Where("status = ?", *f.Status)
+	}
+	if f.VisibleOnly {
+		query = query.Where("status <> ?", model.ReviewStatusHidden)
+	}
+	return query
+}
+
+func (f *ReviewFilter) orderBy() string {
+	if order, ok := reviewSortColumns[f.Sort]; ok {
+		return order
+	}
+	return reviewSortColumns["newest"]
+}
+
+// GetReviewsByPage 按条件分页获取评价, 预加载评价用户的公开信息
+func (r *ReviewDao) GetReviewsByPage(ctx context.Context, filter *ReviewFilter, page int, pageSize int) (*result.PageResult[*model.Review], error) {
+	var total int64
+	var reviews []*model.Review
+
+	query := filter.apply(r.db.WithContext(ctx).Model(&model.Review{}))
+	if err := query.Count(&total).Error; err != nil {
+		return nil, err
+	}
+
+	query = query.Scopes(preloadReviewer)
+	if filter.WithBook {
+		query = query.Preload("Book")
+	}
+	if err := query.Order(filter.orderBy()).
+		Scopes(result.Paginate(&page, &pageSize)).
+		Find(&reviews).Error; err != nil {
+		return nil, err
+	}
+
+	return &result.PageResult[*model.Review]{
+		Total:   total,
+		Records: reviews,
+	}, nil
+}
+
+// preloadReviewer 预加载评价用户, 只查询公开字段
+func preloadReviewer(db *gorm.DB) *gorm.DB {
+	return db.Preload("User", func(db *gorm.DB) *gorm.DB {
+		return db.Select("id", "username", "avatar")
+	})
+}
+
+// GetRatingDistribution 统计图书展示中评价的各星级数量
+func (r *ReviewDao) GetRatingDistribution(ctx context.Context, bookID uint64) (map[int]int64, error) {
+	var rows []struct {
+		Rating int
+		Count  int64
+	}
+	err := r.db.WithContext(ctx).Model(&model.Review{}).
+		Select("rating, COUNT(*) AS count").
+		Where("book_id = ? AND status <> ?", bookID, model.ReviewStatusHidden).
+		Group("rating").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	distribution := make(map[int]int64, len(rows))
+	for _, row := range rows {
+		distribution[row.Rating] = row.Count
+	}
+	return distribution, nil
+}
+
+// AddVoteTx 投有用票, 返回是否新增 (已投过时不重复插入)
+func (r *ReviewDao) AddVoteTx(ctx context.Context, tx *gorm.DB, reviewID uint64, userID uint64) (bool, error) {
+	db := tx
+	if db == nil {
+		db = r.db
+	}
+	res := db.WithContext(ctx).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&model.ReviewVote{ReviewID: reviewID, UserID: userID})
+	if res.Error != nil {
+		return false, res.Error
+	}
+	return res.RowsAffected > 0, nil
+}
+
+// RemoveVoteTx 撤销有用票, 未投票时返回 gorm.ErrRecordNotFound
+func (r *ReviewDao) RemoveVoteTx(ctx context.Context, tx *gorm.DB, reviewID uint64, userID uint64) error {
+	db := tx
+	if db == nil {
+		db = r.db
+	}
+	res := db.WithContext(ctx).
+		Where("review_id = ? AND user_id = ?", reviewID, userID).
+		Delete(&model.ReviewVote{})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// IncrHelpfulCountTx 调整评价的有用票数
+func (r *ReviewDao) IncrHelpfulCountTx(ctx context.Context, tx *gorm.DB, id uint64, delta int) error {
+	db := tx
+	if db == nil {
+		db = r.db
+	}
+	return db.WithContext(ctx).
+		Model(&model.Review{}).
+		Where("id = ?", id).
+		UpdateColumn("helpful_count", gorm.Expr("GREATEST(helpful_count + ?, 0)", delta)).Error
+}
+
+// GetVotedReviewIDs 返回 reviewIDs 中用户已投有用票的评价 ID
+func (r *ReviewDao) GetVotedReviewIDs(ctx context.Context, userID uint64, reviewIDs []uint64) (map[uint64]bool, error) {
+	var ids []uint64
+	err := r.db.WithContext(ctx).Model(&model.ReviewVote{}).
+		Where("user_id = ? AND review_id IN ?", userID, reviewIDs).
+		Pluck("review_id", &ids).Error
+	if err != nil {
+		return nil, err
+	}
+	voted := make(map[uint64]bool, len(ids))
+	for _, id := range ids {
+		voted[id] = true
+	}
+	return voted, nil
+}
@@ -0,0 +1,84 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/wangn-tech/bookstore-go/internal/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type RoleDao struct {
+	db *gorm.DB
+}
+
+func NewRoleDao(db *gorm.DB) *RoleDao {
+	return &RoleDao{
+		db: db,
+	}
+}
+
+// GetAllRoles 获取所有角色及其权限
+func (r *RoleDao) GetAllRoles(ctx context.Context) ([]*model.Role, error) {
+	var roles []*model.Role
+	if err := r.db.WithContext(ctx).Preload("Permissions").Order("id").Find(&roles).Error; err != nil {
+		return nil, err
+	}
+	return roles, nil
+}
+
+// GetRolesByNames 根据角色标识批量获取角色
+func (r *RoleDao) GetRolesByNames(ctx context.Context, names []string) ([]*model.Role, error) {
+	var roles []*model.Role
+	if err := r.db.WithContext(ctx).Where("name IN ?", names).Find(&roles).Error; err != nil {
+		return nil, err
+	}
+	return roles, nil
+}
+
+// GetUserRoles 获取用户的角色及其权限
+func (r *RoleDao) GetUserRoles(ctx context.Context, userID uint64) ([]*model.Role, error) {
+	var roles []*model.Role
+	err := r.db.WithContext(ctx).
+		Preload("Permissions").
+		Joins("JOIN user_roles ON user_roles.role_id = roles.id").
+		Where("user_roles.user_id = ?", userID).
+		Order("roles.id").
+		Find(&roles).Error
+	if err != nil {
+		return nil, err
+	}
+	return roles, nil
+}
+
+// AssignRoleByNameTx 为用户追加角色（已存在则忽略）
+func (r *RoleDao) AssignRoleByNameTx(ctx context.Context, tx *gorm.DB, userID uint64, roleName string) error {
+	db := tx
+	if db == nil {
+		db = r.db
+	}
+	var role model.Role
+	if err := db.WithContext(ctx).Where("name = ?", roleName).First(&role).Error; err != nil {
+		return err
+	}
+	return db.WithContext(ctx).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&model.UserRole{UserID: userID, RoleID: role.ID}).Error
+}
+
+// ReplaceUserRolesTx 覆盖用户的角色列表
+func (r *RoleDao) ReplaceUserRolesTx(ctx context.Context, tx *gorm.DB, userID uint64, roleIDs []uint64) error {
+	db := tx
+	if db == nil {
+		db = r.db
+	}
+	if err := db.WithContext(ctx).Where("user_id = ?", userID).Delete(&model.UserRole{}).Error; err != nil {
+		return err
+	}
+	for _, roleID := range roleIDs {
+		if err := db.WithContext(ctx).Create(&model.UserRole{UserID: userID, RoleID: roleID}).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
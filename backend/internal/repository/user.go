@@ -68,6 +68,24 @@ func (u *UserDao) CreateUser(ctx context.Context, user *model.User) error {
 	return u.db.WithContext(ctx).Create(user).Error
 }
 
+// CreateUserTx 在事务中创建新用户
+func (u *UserDao) CreateUserTx(ctx context.Context, tx *gorm.DB, user *model.User) error {
+	db := tx
+	if db == nil {
+		db = u.db
+	}
+	return db.WithContext(ctx).Create(user).Error
+}
+
+// UpdateIsAdminTx 同步用户的 is_admin 标记
+func (u *UserDao) UpdateIsAdminTx(ctx context.Context, tx *gorm.DB, userID uint64, isAdmin bool) error {
+	db := tx
+	if db == nil {
+		db = u.db
+	}
+	return db.WithContext(ctx).Model(&model.User{}).Where("id = ?", userID).Update("is_admin", isAdmin).Error
+}
+
 // GetUserByUsername 根据 username 获取 user
 func (u *UserDao) GetUserByUsername(ctx context.Context, username string) (*model.User, error) {
 	var user model.User
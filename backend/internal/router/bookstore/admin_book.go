@@ -0,0 +1,32 @@
+package bookstore
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/wangn-tech/bookstore-go/internal/api/handler"
+	"github.com/wangn-tech/bookstore-go/internal/app/constants"
+	"github.com/wangn-tech/bookstore-go/internal/app/container"
+	"github.com/wangn-tech/bookstore-go/internal/middlerware"
+	"github.com/wangn-tech/bookstore-go/internal/service"
+)
+
+type AdminBookRouter struct {
+	bookService service.IBookService
+}
+
+func (a *AdminBookRouter) InitAdminBookRouter(router *gin.RouterGroup, c *container.Container) {
+	a.bookService = c.BookService
+	adminBookHandler := handler.NewAdminBookHandler(a.bookService)
+
+	adminBookGroup := router.Group("/book")
+	adminBookGroup.Use(middlerware.JWTAuth(), middlerware.RequirePermissions(constants.PermBookManage))
+	{
+		adminBookGroup.GET("/list", adminBookHandler.GetBookList)            // 获取图书列表（含下架）
+		adminBookGroup.GET("/export", adminBookHandler.ExportBooks)          // 导出图书目录
+		adminBookGroup.POST("/import", adminBookHandler.ImportBooks)         // 批量导入图书
+		adminBookGroup.GET("/:id", adminBookHandler.GetBookDetail)           // 获取图书详情
+		adminBookGroup.POST("/create", adminBookHandler.CreateBook)          // 创建图书
+		adminBookGroup.PUT("/:id", adminBookHandler.UpdateBook)              // 更新图书
+		adminBookGroup.PUT("/:id/status", adminBookHandler.UpdateBookStatus) // 图书上架/下架
+		adminBookGroup.DELETE("/:id", adminBookHandler.DeleteBook)           // 删除图书
+	}
+}
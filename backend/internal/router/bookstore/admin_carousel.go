@@ -0,0 +1,30 @@
+package bookstore
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/wangn-tech/bookstore-go/internal/api/handler"
+	"github.com/wangn-tech/bookstore-go/internal/app/constants"
+	"github.com/wangn-tech/bookstore-go/internal/app/container"
+	"github.com/wangn-tech/bookstore-go/internal/middlerware"
+	"github.com/wangn-tech/bookstore-go/internal/service"
+)
+
+type AdminCarouselRouter struct {
+	carouselService service.ICarouselService
+}
+
+func (a *AdminCarouselRouter) InitAdminCarouselRouter(router *gin.RouterGroup, c *container.Container) {
+	a.carouselService = c.CarouselService
+	adminCarouselHandler := handler.NewAdminCarouselHandler(a.carouselService)
+
+	adminCarouselGroup := router.Group("/carousel")
+	adminCarouselGroup.Use(middlerware.JWTAuth(), middlerware.RequirePermissions(constants.PermCarouselManage))
+	{
+		adminCarouselGroup.GET("/list", adminCarouselHandler.GetCarousels)        // 获取全部轮播图
+		adminCarouselGroup.GET("/:id", adminCarouselHandler.GetCarousel)          // 获取轮播图详情
+		adminCarouselGroup.POST("/create", adminCarouselHandler.CreateCarousel)   // 创建轮播图
+		adminCarouselGroup.PUT("/reorder", adminCarouselHandler.ReorderCarousels) // 调整轮播图顺序
+		adminCarouselGroup.PUT("/:id", adminCarouselHandler.UpdateCarousel)       // 更新轮播图
+		adminCarouselGroup.DELETE("/:id", adminCarouselHandler.DeleteCarousel)    // 删除轮播图
+	}
+}
@@ -0,0 +1,28 @@
+package bookstore
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/wangn-tech/bookstore-go/internal/api/handler"
+	"github.com/wangn-tech/bookstore-go/internal/app/constants"
+	"github.com/wangn-tech/bookstore-go/internal/app/container"
+	"github.com/wangn-tech/bookstore-go/internal/middlerware"
+	"github.com/wangn-tech/bookstore-go/internal/service"
+)
+
+type AdminOrderRouter struct {
+	orderService service.IOrderService
+}
+
+func (a *AdminOrderRouter) InitAdminOrderRouter(router *gin.RouterGroup, c *container.Container) {
+	a.orderService = c.OrderService
+	adminOrderHandler := handler.NewAdminOrderHandler(a.orderService)
+
+	adminOrderGroup := router.Group("/order")
+	adminOrderGroup.Use(middlerware.JWTAuth(), middlerware.RequirePermissions(constants.PermOrderManage))
+	{
+		adminOrderGroup.GET("/list", adminOrderHandler.GetOrders)                    // 获取全部订单列表
+		adminOrderGroup.GET("/:id", adminOrderHandler.GetOrderByID)                  // 获取任意订单详情
+		adminOrderGroup.POST("/:id/transition", adminOrderHandler.TransitionOrder)   // 变更订单状态
+		adminOrderGroup.GET("/:id/history", adminOrderHandler.GetOrderStatusHistory) // 获取订单状态变更记录
+	}
+}
@@ -0,0 +1,26 @@
+package bookstore
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/wangn-tech/bookstore-go/internal/api/handler"
+	"github.com/wangn-tech/bookstore-go/internal/app/constants"
+	"github.com/wangn-tech/bookstore-go/internal/app/container"
+	"github.com/wangn-tech/bookstore-go/internal/middlerware"
+	"github.com/wangn-tech/bookstore-go/internal/service"
+)
+
+type AdminReviewRouter struct {
+	reviewService service.IReviewService
+}
+
+func (a *AdminReviewRouter) InitAdminReviewRouter(router *gin.RouterGroup, c *container.Container) {
+	a.reviewService = c.ReviewService
+	adminReviewHandler := handler.NewAdminReviewHandler(a.reviewService)
+
+	adminReviewGroup := router.Group("/review")
+	adminReviewGroup.Use(middlerware.JWTAuth(), middlerware.RequirePermissions(constants.PermReviewManage))
+	{
+		adminReviewGroup.GET("/list", adminReviewHandler.GetReviews)               // 获取评价列表
+		adminReviewGroup.PUT("/:id/status", adminReviewHandler.UpdateReviewStatus) // 审核评价
+	}
+}
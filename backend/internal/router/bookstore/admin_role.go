@@ -0,0 +1,28 @@
+package bookstore
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/wangn-tech/bookstore-go/internal/api/handler"
+	"github.com/wangn-tech/bookstore-go/internal/app/constants"
+	"github.com/wangn-tech/bookstore-go/internal/app/container"
+	"github.com/wangn-tech/bookstore-go/internal/middlerware"
+	"github.com/wangn-tech/bookstore-go/internal/service"
+)
+
+type AdminRoleRouter struct {
+	roleService service.IRoleService
+}
+
+func (a *AdminRoleRouter) InitAdminRoleRouter(router *gin.RouterGroup, c *container.Container) {
+	a.roleService = c.RoleService
+	roleHandler := handler.NewRoleHandler(a.roleService)
+
+	// 整个路由组需要 role:manage 权限
+	adminGroup := router.Group("")
+	adminGroup.Use(middlerware.JWTAuth(), middlerware.RequirePermissions(constants.PermRoleManage))
+	{
+		adminGroup.GET("/role/list", roleHandler.GetRoles)          // 获取所有角色
+		adminGroup.GET("/user/:id/roles", roleHandler.GetUserRoles) // 获取用户角色
+		adminGroup.PUT("/user/:id/roles", roleHandler.SetUserRoles) // 设置用户角色
+	}
+}
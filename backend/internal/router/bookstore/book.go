@@ -3,8 +3,8 @@ package bookstore
 import (
 	"github.com/gin-gonic/gin"
 	"github.com/wangn-tech/bookstore-go/internal/api/handler"
-	"github.com/wangn-tech/bookstore-go/internal/app/initializer/database"
-	"github.com/wangn-tech/bookstore-go/internal/repository"
+	"github.com/wangn-tech/bookstore-go/internal/app/container"
+	"github.com/wangn-tech/bookstore-go/internal/middlerware"
 	"github.com/wangn-tech/bookstore-go/internal/service"
 )
 
@@ -12,19 +12,23 @@ type BookRouter struct {
 	bookService service.IBookService
 }
 
-func (b *BookRouter) InitBookRouter(router *gin.RouterGroup) {
-	b.bookService = service.NewBookService(
-		repository.NewBookDao(database.DB),
-	)
-	bookHandler := handler.NewBookHandler(b.bookService)
+func (b *BookRouter) InitBookRouter(router *gin.RouterGroup, c *container.Container) {
+	b.bookService = c.BookService
+	bookHandler := handler.NewBookHandler(b.bookService, c.FavoriteService, c.SuggestService, c.ReviewService, c.RecommendService, c.RankingService, c.HistoryService)
 
 	bookRouter := router.Group("/book")
+	// 登录用户返回收藏状态, 匿名用户可正常访问
+	bookRouter.Use(middlerware.OptionalJWTAuth())
 	{
 		bookRouter.GET("/list", bookHandler.GetBookList)                      // 获取图书列表
-		bookRouter.GET("/hot", bookHandler.GetHotBooks)                       // 获取热销图书
+		bookRouter.GET("/hot", bookHandler.GetHotBooks)                       // 获取畅销榜
 		bookRouter.GET("/new", bookHandler.GetNewBooks)                       // 获取新书
+		bookRouter.GET("/recommend", bookHandler.GetRecommendations)          // 猜你喜欢
 		bookRouter.GET("/detail/:id", bookHandler.GetBookDetail)              // 获取图书详情
+		bookRouter.GET("/isbn/:isbn", bookHandler.GetBookByISBN)              // 根据 ISBN 获取图书详情
+		bookRouter.GET("/:id/related", bookHandler.GetRelatedBooks)           // 买了这本书的人还买了
 		bookRouter.GET("/search", bookHandler.SearchBooks)                    // 搜索图书
+		bookRouter.GET("/suggest", bookHandler.SuggestBooks)                  // 搜索联想
 		bookRouter.GET("/category/:category", bookHandler.GetBooksByCategory) // 获取分类下的图书
 	}
 
@@ -0,0 +1,19 @@
+package bookstore
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/wangn-tech/bookstore-go/internal/api/handler"
+	"github.com/wangn-tech/bookstore-go/internal/app/container"
+	"github.com/wangn-tech/bookstore-go/internal/service"
+)
+
+type CarouselRouter struct {
+	carouselService service.ICarouselService
+}
+
+func (cr *CarouselRouter) InitCarouselRouter(router *gin.RouterGroup, c *container.Container) {
+	cr.carouselService = c.CarouselService
+	carouselHandler := handler.NewCarouselHandler(cr.carouselService)
+
+	router.GET("/carousel", carouselHandler.GetCarousels) // 获取首页轮播图
+}
@@ -0,0 +1,30 @@
+package bookstore
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/wangn-tech/bookstore-go/internal/api/handler"
+	"github.com/wangn-tech/bookstore-go/internal/app/container"
+	"github.com/wangn-tech/bookstore-go/internal/middlerware"
+	"github.com/wangn-tech/bookstore-go/internal/service"
+)
+
+type CartRouter struct {
+	cartService service.ICartService
+}
+
+func (cr *CartRouter) InitCartRouter(router *gin.RouterGroup, c *container.Container) {
+	cr.cartService = c.CartService
+
+	cartHandler := handler.NewCartHandler(cr.cartService)
+
+	cartGroup := router.Group("/cart")
+	cartGroup.Use(middlerware.JWTAuth())
+	{
+		cartGroup.GET("/list", cartHandler.GetCart)           // 获取购物车
+		cartGroup.POST("/add", cartHandler.AddItem)           // 加入购物车
+		cartGroup.PUT("/:book_id", cartHandler.UpdateItem)    // 修改商品数量
+		cartGroup.DELETE("/:book_id", cartHandler.RemoveItem) // 移除商品
+		cartGroup.DELETE("/clear", cartHandler.ClearCart)     // 清空购物车
+		cartGroup.POST("/checkout", cartHandler.Checkout)     // 结算下单
+	}
+}
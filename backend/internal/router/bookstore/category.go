@@ -3,8 +3,9 @@ package bookstore
 import (
 	"github.com/gin-gonic/gin"
 	"github.com/wangn-tech/bookstore-go/internal/api/handler"
-	"github.com/wangn-tech/bookstore-go/internal/app/initializer/database"
-	"github.com/wangn-tech/bookstore-go/internal/repository"
+	"github.com/wangn-tech/bookstore-go/internal/app/constants"
+	"github.com/wangn-tech/bookstore-go/internal/app/container"
+	"github.com/wangn-tech/bookstore-go/internal/middlerware"
 	"github.com/wangn-tech/bookstore-go/internal/service"
 )
 
@@ -12,19 +13,25 @@ type CategoryRouter struct {
 	categoryService service.ICategoryService
 }
 
-func (c *CategoryRouter) InitCategoryRouter(router *gin.RouterGroup) {
-	c.categoryService = service.NewCategoryService(
-		repository.NewCategoryDao(database.DB),
-	)
-	categoryHandler := handler.NewCategoryHandler(c.categoryService)
+func (cr *CategoryRouter) InitCategoryRouter(router *gin.RouterGroup, c *container.Container) {
+	cr.categoryService = c.CategoryService
+	categoryHandler := handler.NewCategoryHandler(cr.categoryService)
+
+	// 写操作需要 category:manage 权限, 单独作用于对应路由
+	auth := middlerware.JWTAuth()
+	canManage := middlerware.RequirePermissions(constants.PermCategoryManage)
 
 	categoryGroup := router.Group("/category")
 	{
-		categoryGroup.GET("/list", categoryHandler.GetCategories)     // 获取所有分类
-		categoryGroup.GET("/:id", categoryHandler.GetCategoryByID)    // 根据 ID 获取分类详情
-		categoryGroup.POST("/create", categoryHandler.CreateCategory) // 创建分类
-		categoryGroup.PUT("/:id", categoryHandler.UpdateCategory)     // 更新分类
-		categoryGroup.DELETE("/:id", categoryHandler.DeleteCategory)  // 删除分类
+		categoryGroup.GET("/list", categoryHandler.GetCategories)                                     // 获取所有分类
+		categoryGroup.GET("/tree", categoryHandler.GetCategoryTree)                                   // 获取分类树
+		categoryGroup.GET("/:id", categoryHandler.GetCategoryByID)                                    // 根据 ID 获取分类详情
+		categoryGroup.GET("/:id/breadcrumbs", categoryHandler.GetBreadcrumbs)                         // 获取分类面包屑
+		categoryGroup.POST("/create", auth, canManage, categoryHandler.CreateCategory)                // 创建分类
+		categoryGroup.POST("/reconcile-counts", auth, canManage, categoryHandler.ReconcileBookCounts) // 校正分类图书数量
+		categoryGroup.PUT("/:id", auth, canManage, categoryHandler.UpdateCategory)                    // 更新分类
+		categoryGroup.PUT("/:id/move", auth, canManage, categoryHandler.MoveCategory)                 // 移动分类
+		categoryGroup.DELETE("/:id", auth, canManage, categoryHandler.DeleteCategory)                 // 删除分类
 	}
 
 }
@@ -0,0 +1,27 @@
+package bookstore
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/wangn-tech/bookstore-go/internal/api/handler"
+	"github.com/wangn-tech/bookstore-go/internal/app/container"
+	"github.com/wangn-tech/bookstore-go/internal/middlerware"
+	"github.com/wangn-tech/bookstore-go/internal/service"
+)
+
+type FavoriteRouter struct {
+	favoriteService service.IFavoriteService
+}
+
+func (f *FavoriteRouter) InitFavoriteRouter(router *gin.RouterGroup, c *container.Container) {
+	f.favoriteService = c.FavoriteService
+	favoriteHandler := handler.NewFavoriteHandler(f.favoriteService)
+
+	favoriteGroup := router.Group("/favorite")
+	favoriteGroup.Use(middlerware.JWTAuth())
+	{
+		favoriteGroup.GET("/list", favoriteHandler.GetFavorites)            // 获取收藏列表
+		favoriteGroup.POST("/:book_id", favoriteHandler.AddFavorite)        // 收藏图书
+		favoriteGroup.DELETE("/:book_id", favoriteHandler.RemoveFavorite)   // 取消收藏
+		favoriteGroup.GET("/:book_id/check", favoriteHandler.CheckFavorite) // 查询是否已收藏
+	}
+}
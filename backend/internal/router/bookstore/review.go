@@ -0,0 +1,32 @@
+package bookstore
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/wangn-tech/bookstore-go/internal/api/handler"
+	"github.com/wangn-tech/bookstore-go/internal/app/container"
+	"github.com/wangn-tech/bookstore-go/internal/middlerware"
+	"github.com/wangn-tech/bookstore-go/internal/service"
+)
+
+type ReviewRouter struct {
+	reviewService service.IReviewService
+}
+
+func (r *ReviewRouter) InitReviewRouter(router *gin.RouterGroup, c *container.Container) {
+	r.reviewService = c.ReviewService
+	reviewHandler := handler.NewReviewHandler(r.reviewService)
+
+	// 评价列表登录后返回投票状态, 匿名用户可正常访问; 写操作需要登录
+	auth := middlerware.JWTAuth()
+
+	reviewGroup := router.Group("/review")
+	{
+		reviewGroup.GET("/book/:book_id", middlerware.OptionalJWTAuth(), reviewHandler.GetBookReviews) // 获取图书评价列表
+		reviewGroup.GET("/book/:book_id/summary", reviewHandler.GetRatingSummary)                      // 获取图书评分概况
+		reviewGroup.POST("/book/:book_id", auth, reviewHandler.CreateReview)                           // 发表评价
+		reviewGroup.PUT("/:id", auth, reviewHandler.UpdateReview)                                      // 修改评价
+		reviewGroup.DELETE("/:id", auth, reviewHandler.DeleteReview)                                   // 删除评价
+		reviewGroup.POST("/:id/vote", auth, reviewHandler.VoteHelpful)                                 // 投有用票
+		reviewGroup.DELETE("/:id/vote", auth, reviewHandler.UnvoteHelpful)                             // 撤销有用票
+	}
+}
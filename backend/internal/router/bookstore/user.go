@@ -3,9 +3,8 @@ package bookstore
 import (
 	"github.com/gin-gonic/gin"
 	"github.com/wangn-tech/bookstore-go/internal/api/handler"
-	"github.com/wangn-tech/bookstore-go/internal/app/initializer/database"
+	"github.com/wangn-tech/bookstore-go/internal/app/container"
 	"github.com/wangn-tech/bookstore-go/internal/middlerware"
-	"github.com/wangn-tech/bookstore-go/internal/repository"
 	"github.com/wangn-tech/bookstore-go/internal/service"
 )
 
@@ -14,28 +13,33 @@ type UserRouter struct {
 	captchaService service.ICaptchaService
 }
 
-func (u *UserRouter) InitUserRouter(router *gin.RouterGroup) {
+func (u *UserRouter) InitUserRouter(router *gin.RouterGroup, c *container.Container) {
 	// 依赖注入
-	u.userService = service.NewUserService(
-		repository.NewUserDao(database.DB),
-	)
-	u.captchaService = service.NewCaptchaService()
+	u.userService = c.UserService
+	u.captchaService = c.CaptchaService
 	userHandler := handler.NewUserHandler(u.userService, u.captchaService)
+	historyHandler := handler.NewHistoryHandler(c.HistoryService)
 	captchaHandler := handler.NewCaptchaHandler(u.captchaService)
 
 	// "/user" 路由组
 	userGroup := router.Group("/user")
 	{
-		userGroup.POST("/register", userHandler.Register) // 用户注册
-		userGroup.POST("/login", userHandler.Login)       // 用户登录
+		userGroup.POST("/register", userHandler.Register)    // 用户注册
+		userGroup.POST("/login", userHandler.Login)          // 用户登录
+		userGroup.POST("/refresh", userHandler.RefreshToken) // 刷新 token
 
 		// 需要认证的路由
 		userGroup.Use(middlerware.JWTAuth())
 		{
-			userGroup.GET("/profile", userHandler.GetUserProfile)    // 获取用户信息
-			userGroup.POST("/logout", userHandler.Logout)            // 用户登出
-			userGroup.PUT("/profile", userHandler.UpdateUserProfile) // 更新用户信息
-			userGroup.PUT("/password", userHandler.ChangePassword)   // 修改密码
+			userGroup.GET("/profile", userHandler.GetUserProfile)        // 获取用户信息
+			userGroup.POST("/logout", userHandler.Logout)                // 用户登出
+			userGroup.PUT("/profile", userHandler.UpdateUserProfile)     // 更新用户信息
+			userGroup.PUT("/password", userHandler.ChangePassword)       // 修改密码
+			userGroup.GET("/sessions", userHandler.ListSessions)         // 获取登录会话列表
+			userGroup.DELETE("/sessions/:id", userHandler.RevokeSession) // 撤销单个会话
+			userGroup.DELETE("/sessions", userHandler.RevokeAllSessions) // 撤销全部会话
+			userGroup.GET("/history", historyHandler.GetHistory)         // 获取最近浏览的图书
+			userGroup.DELETE("/history", historyHandler.ClearHistory)    // 清空浏览历史
 
 		}
 	}
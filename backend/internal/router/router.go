@@ -2,6 +2,7 @@ package router
 
 import (
 	"github.com/gin-gonic/gin"
+	"github.com/wangn-tech/bookstore-go/internal/app/container"
 	"github.com/wangn-tech/bookstore-go/internal/middlerware"
 	"github.com/wangn-tech/bookstore-go/internal/router/bookstore"
 )
@@ -9,11 +10,23 @@ import (
 type RouteGroup struct {
 	bookstore.UserRouter
 	bookstore.BookRouter
+	bookstore.CategoryRouter
+	bookstore.OrderRouter
+	bookstore.CartRouter
+	bookstore.FavoriteRouter
+	bookstore.CarouselRouter
+	bookstore.ReviewRouter
+	bookstore.AdminBookRouter
+	bookstore.AdminRoleRouter
+	bookstore.AdminOrderRouter
+	bookstore.AdminCarouselRouter
+	bookstore.AdminReviewRouter
 }
 
 var AllRouter = new(RouteGroup)
 
-func InitRouter(r *gin.Engine) {
+// InitRouter 注册全部路由, 依赖由容器 c 注入
+func InitRouter(r *gin.Engine, c *container.Container) {
 
 	// 全局中间件 CORS
 	r.Use(middlerware.CORS())
@@ -28,7 +41,23 @@ func InitRouter(r *gin.Engine) {
 	// 业务路由
 	v1 := r.Group("/api/v1")
 	{
-		AllRouter.InitUserRouter(v1)
-		AllRouter.InitBookRouter(v1)
+		AllRouter.InitUserRouter(v1, c)
+		AllRouter.InitBookRouter(v1, c)
+		AllRouter.InitCategoryRouter(v1, c)
+		AllRouter.InitOrderRouter(v1, c)
+		AllRouter.InitCartRouter(v1, c)
+		AllRouter.InitFavoriteRouter(v1, c)
+		AllRouter.InitCarouselRouter(v1, c)
+		AllRouter.InitReviewRouter(v1, c)
+	}
+
+	// 管理员路由
+	admin := v1.Group("/admin")
+	{
+		AllRouter.InitAdminBookRouter(admin, c)
+		AllRouter.InitAdminRoleRouter(admin, c)
+		AllRouter.InitAdminOrderRouter(admin, c)
+		AllRouter.InitAdminCarouselRouter(admin, c)
+		AllRouter.InitAdminReviewRouter(admin, c)
 	}
 }
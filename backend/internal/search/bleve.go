@@ -0,0 +1,308 @@
+package search
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/blevesearch/bleve/v2"
+	"github.com/blevesearch/bleve/v2/analysis/analyzer/keyword"
+	"github.com/blevesearch/bleve/v2/analysis/lang/cjk"
+	"github.com/blevesearch/bleve/v2/mapping"
+	bleveSearch "github.com/blevesearch/bleve/v2/search"
+	"github.com/blevesearch/bleve/v2/search/highlight/highlighter/html"
+	"github.com/blevesearch/bleve/v2/search/query"
+	"github.com/wangn-tech/bookstore-go/common/result"
+	"github.com/wangn-tech/bookstore-go/internal/model"
+	"github.com/wangn-tech/bookstore-go/pkg/hanzi"
+)
+
+// bleveBook Bleve 索引文档, 只包含上架图书
+type bleveBook struct {
+	Title          string  `json:"title"`
+	Author         string  `json:"author"`
+	Description    string  `json:"description"`
+	Publisher      string  `json:"publisher"`
+	ISBN           string  `json:"isbn"`
+	CategoryID     string  `json:"category_id"`     // 分面、筛选用, 不分词
+	PublisherFacet string  `json:"publisher_facet"` // 分面、筛选用, 不分词
+	Language       string  `json:"language"`        // 分面、筛选用, 不分词
+	SalePrice      float64 `json:"sale_price"`      // 折后价（元）
+	SearchKeys     string  `json:"search_keys"`     // 检索键: 简体文本、全拼和拼音首字母, 不存储、不高亮
+}
+
+// searchKeysBoost 检索键 (繁简、拼音) 命中的权重
+const searchKeysBoost = 2
+
+// bleveTextFields 参与全文检索的字段及权重
+var bleveTextFields = []struct {
+	name  string
+	boost float64
+}{
+	{"title", 3},
+	{"author", 2},
+	{"publisher", 1},
+	{"isbn", 1},
+	{"description", 1},
+}
+
+// BleveEngine 基于嵌入式 Bleve 索引的检索引擎
+//
+//	// 索引文件保存在本地目录, 图书写操作后由 service 调用 Index / Delete 同步
+//	// 多实例部署时各实例的索引互相独立, 需使用 MySQL 引擎或单独的检索服务
+type BleveEngine struct {
+	index bleve.Index
+	fresh bool // 索引为本次启动新建
+}
+
+// NewBleveEngine 打开 path 下的索引, 不存在时新建
+func NewBleveEngine(path string) (*BleveEngine, error) {
+	index, err := bleve.Open(path)
+	if errors.Is(err, bleve.ErrorIndexPathDoesNotExist) {
+		index, err = bleve.New(path, newBleveMapping())
+		if err != nil {
+			return nil, err
+		}
+		return &BleveEngine{index: index, fresh: true}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &BleveEngine{index: index}, nil
+}
+
+// newBleveMapping 文本字段使用 CJK 二元组分词, 分面字段不分词
+func newBleveMapping() mapping.IndexMapping {
+	text := bleve.NewTextFieldMapping()
+	text.Analyzer = cjk.AnalyzerName
+	text.Store = true // 高亮需要原文
+	text.IncludeTermVectors = true
+
+	facet := bleve.NewTextFieldMapping()
+	facet.Analyzer = keyword.Name
+	facet.Store = false
+	facet.IncludeInAll = false
+
+	keys := bleve.NewTextFieldMapping()
+	keys.Analyzer = cjk.AnalyzerName
+	keys.Store = false
+	keys.IncludeInAll = false
+
+	price := bleve.NewNumericFieldMapping()
+	price.Store = false
+	price.IncludeInAll = false
+
+	doc := bleve.NewDocumentMapping()
+	for _, field := range bleveTextFields {
+		doc.AddFieldMappingsAt(field.name, text)
+	}
+	doc.AddFieldMappingsAt("category_id", facet)
+	doc.AddFieldMappingsAt("publisher_facet", facet)
+	doc.AddFieldMappingsAt("language", facet)
+	doc.AddFieldMappingsAt("sale_price", price)
+	doc.AddFieldMappingsAt("search_keys", keys)
+
+	indexMapping := bleve.NewIndexMapping()
+	indexMapping.DefaultMapping = doc
+	indexMapping.DefaultAnalyzer = cjk.AnalyzerName
+	return indexMapping
+}
+
+// Search 按相关度检索上架图书
+func (e *BleveEngine) Search(ctx context.Context, q *Query) (*Result, error) {
+	result.PageVerify(&q.Page, &q.PageSize)
+
+	req := bleve.NewSearchRequestOptions(e.buildQuery(q), q.PageSize, (q.Page-1)*q.PageSize, false)
+	req.Highlight = bleve.NewHighlightWithStyle(html.Name)
+	for _, field := range bleveTextFields {
+		req.Highlight.AddField(field.name)
+	}
+	req.AddFacet("category", bleve.NewFacetRequest("category_id", facetSize))
+	req.AddFacet("publisher", bleve.NewFacetRequest("publisher_facet", facetSize))
+	req.AddFacet("language", bleve.NewFacetRequest("language", facetSize))
+	priceFacet := bleve.NewFacetRequest("sale_price", len(PriceBands))
+	for _, band := range PriceBands {
+		minPrice := float64(band.Min)
+		var maxPrice *float64
+		if band.Max > 0 {
+			v := float64(band.Max)
+			maxPrice = &v
+		}
+		priceFacet.AddNumericRange(band.Key, &minPrice, maxPrice)
+	}
+	req.AddFacet("price_band", priceFacet)
+
+	res, err := e.index.SearchInContext(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	hits := make([]*Hit, 0, len(res.Hits))
+	for _, doc := range res.Hits {
+		id, err := strconv.ParseUint(doc.ID, 10, 64)
+		if err != nil {
+			continue
+		}
+		hits = append(hits, &Hit{
+			BookID:     id,
+			Score:      doc.Score,
+			Highlights: bleveHighlights(doc.Fragments),
+		})
+	}
+
+	facets := &Facets{
+		Category:  bleveTermFacet(res.Facets["category"]),
+		Publisher: bleveTermFacet(res.Facets["publisher"]),
+		Language:  bleveTermFacet(res.Facets["language"]),
+		PriceBand: make([]*FacetCount, 0),
+	}
+	if priceResult := res.Facets["price_band"]; priceResult != nil {
+		for _, band := range priceResult.NumericRanges {
+			if band.Count > 0 {
+				facets.PriceBand = append(facets.PriceBand, &FacetCount{Value: band.Name, Count: int64(band.Count)})
+			}
+		}
+	}
+	return &Result{Total: int64(res.Total), Hits: hits, Facets: facets}, nil
+}
+
+// buildQuery 关键词在各文本字段中任一命中, 且满足全部筛选条件
+func (e *BleveEngine) buildQuery(q *Query) query.Query {
+	matches := make([]query.Query, 0, len(bleveTextFields))
+	for _, field := range bleveTextFields {
+		match := bleve.NewMatchQuery(q.Keyword)
+		match.SetField(field.name)
+		match.SetBoost(field.boost)
+		matches = append(matches, match)
+	}
+	if keys := hanzi.CompactPinyin(hanzi.Normalize(q.Keyword)); keys != "" {
+		match := bleve.NewMatchQuery(keys)
+		match.SetField("search_keys")
+		match.SetBoost(searchKeysBoost)
+		matches = append(matches, match)
+		// 拼音输入过程中的前缀, 如 "liuci" 命中 "liucixin"
+		if isASCII(keys) && len(keys) >= 2 {
+			prefix := bleve.NewPrefixQuery(keys)
+			prefix.SetField("search_keys")
+			matches = append(matches, prefix)
+		}
+	}
+	must := []query.Query{bleve.NewDisjunctionQuery(matches...)}
+
+	if len(q.CategoryIDs) > 0 {
+		categories := make([]query.Query, 0, len(q.CategoryIDs))
+		for _, id := range q.CategoryIDs {
+			term := bleve.NewTermQuery(strconv.FormatUint(id, 10))
+			term.SetField("category_id")
+			categories = append(categories, term)
+		}
+		must = append(must, bleve.NewDisjunctionQuery(categories...))
+	}
+	if q.Publisher != "" {
+		term := bleve.NewTermQuery(q.Publisher)
+		term.SetField("publisher_facet")
+		must = append(must, term)
+	}
+	if q.Language != "" {
+		term := bleve.NewTermQuery(q.Language)
+		term.SetField("language")
+		must = append(must, term)
+	}
+	if band, ok := findPriceBand(q.PriceBand); ok {
+		minPrice := float64(band.Min)
+		var maxPrice *float64
+		if band.Max > 0 {
+			v := float64(band.Max)
+			maxPrice = &v
+		}
+		inclusive, exclusive := true, false
+		priceRange := bleve.NewNumericRangeInclusiveQuery(&minPrice, maxPrice, &inclusive, &exclusive)
+		priceRange.SetField("sale_price")
+		must = append(must, priceRange)
+	}
+	return bleve.NewConjunctionQuery(must...)
+}
+
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= utf8.RuneSelf {
+			return false
+		}
+	}
+	return true
+}
+
+// bleveHighlights 只保留包含高亮标签的片段, Bleve 会为未命中的存储字段返回原文
+func bleveHighlights(fragments bleveSearch.FieldFragmentMap) map[string][]string {
+	highlights := make(map[string][]string)
+	for field, values := range fragments {
+		for _, value := range values {
+			if strings.Contains(value, HighlightPre) {
+				highlights[field] = append(highlights[field], value)
+			}
+		}
+	}
+	return highlights
+}
+
+// bleveTermFacet 转换词项分面结果
+func bleveTermFacet(facet *bleveSearch.FacetResult) []*FacetCount {
+	counts := make([]*FacetCount, 0)
+	if facet == nil {
+		return counts
+	}
+	for _, term := range facet.Terms.Terms() {
+		counts = append(counts, &FacetCount{Value: term.Term, Count: int64(term.Count)})
+	}
+	return counts
+}
+
+// Index 写入或更新图书索引, 下架图书从索引中删除
+func (e *BleveEngine) Index(ctx context.Context, books ...*model.Book) error {
+	batch := e.index.NewBatch()
+	for _, book := range books {
+		id := strconv.FormatUint(book.ID, 10)
+		if book.Status != 1 {
+			batch.Delete(id)
+			continue
+		}
+		doc := &bleveBook{
+			Title:          book.Title,
+			Author:         book.Author,
+			Description:    book.Description,
+			Publisher:      book.Publisher,
+			ISBN:           book.ISBN,
+			PublisherFacet: book.Publisher,
+			Language:       book.Language,
+			SalePrice:      float64(book.SalePriceInCents()) / 100,
+			SearchKeys:     book.SearchKeys,
+		}
+		if book.CategoryID != 0 {
+			doc.CategoryID = strconv.FormatUint(book.CategoryID, 10)
+		}
+		if err := batch.Index(id, doc); err != nil {
+			return err
+		}
+	}
+	return e.index.Batch(batch)
+}
+
+// Delete 从索引中删除图书
+func (e *BleveEngine) Delete(ctx context.Context, ids ...uint64) error {
+	batch := e.index.NewBatch()
+	for _, id := range ids {
+		batch.Delete(strconv.FormatUint(id, 10))
+	}
+	return e.index.Batch(batch)
+}
+
+// NeedsReindex 索引为新建时需要从数据库全量构建
+func (e *BleveEngine) NeedsReindex() bool {
+	return e.fresh
+}
+
+func (e *BleveEngine) Close() error {
+	return e.index.Close()
+}
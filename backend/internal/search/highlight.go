@@ -0,0 +1,100 @@
+package search
+
+import (
+	"html"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// highlightTerms 将关键词拆分为高亮词
+//
+//	// 除按空白拆分的完整词外, 长度超过 2 的词再拆为二元组, 与 ngram / CJK bigram 的匹配粒度一致
+func highlightTerms(keyword string) []string {
+	seen := make(map[string]bool)
+	var terms []string
+	add := func(term string) {
+		if term != "" && !seen[term] {
+			seen[term] = true
+			terms = append(terms, term)
+		}
+	}
+	for _, field := range strings.Fields(strings.ToLower(keyword)) {
+		add(field)
+		runes := []rune(field)
+		if len(runes) > 2 {
+			for i := 0; i+2 <= len(runes); i++ {
+				add(string(runes[i : i+2]))
+			}
+		}
+	}
+	// 长词优先匹配, 避免被其二元组拆散
+	sort.SliceStable(terms, func(i, j int) bool {
+		return utf8.RuneCountInString(terms[i]) > utf8.RuneCountInString(terms[j])
+	})
+	return terms
+}
+
+// highlightText 用高亮标签包裹 text 中全部命中的词, 没有命中时返回空串
+//
+//	// maxRunes > 0 时截取以首个命中位置为中心的片段; 结果已做 HTML 转义, 可直接渲染
+func highlightText(text string, terms []string, maxRunes int) string {
+	if text == "" || len(terms) == 0 {
+		return ""
+	}
+	runes := []rune(text)
+	lower := []rune(strings.ToLower(text))
+	// 大小写转换改变了长度时无法按位置对应, 放弃高亮
+	if len(lower) != len(runes) {
+		return ""
+	}
+
+	// 标记命中区间
+	marked := make([]bool, len(runes))
+	first := -1
+	for _, term := range terms {
+		t := []rune(term)
+		for i := 0; i+len(t) <= len(lower); i++ {
+			if string(lower[i:i+len(t)]) != term {
+				continue
+			}
+			for k := i; k < i+len(t); k++ {
+				marked[k] = true
+			}
+			if first < 0 || i < first {
+				first = i
+			}
+		}
+	}
+	if first < 0 {
+		return ""
+	}
+
+	start, end := 0, len(runes)
+	if maxRunes > 0 && len(runes) > maxRunes {
+		start = max(first-maxRunes/4, 0)
+		end = min(start+maxRunes, len(runes))
+	}
+
+	var sb strings.Builder
+	if start > 0 {
+		sb.WriteString("…")
+	}
+	for i := start; i < end; {
+		j := i
+		for j < end && marked[j] == marked[i] {
+			j++
+		}
+		segment := html.EscapeString(string(runes[i:j]))
+		if marked[i] {
+			sb.WriteString(HighlightPre + segment + HighlightPost)
+		} else {
+			sb.WriteString(segment)
+		}
+		i = j
+	}
+	if end < len(runes) {
+		sb.WriteString("…")
+	}
+	return sb.String()
+}
@@ -0,0 +1,252 @@
+package search
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/wangn-tech/bookstore-go/common/result"
+	"github.com/wangn-tech/bookstore-go/internal/model"
+	"github.com/wangn-tech/bookstore-go/pkg/hanzi"
+	"gorm.io/gorm"
+)
+
+// matchExpr 全文匹配表达式, 列顺序需与 books 表 FULLTEXT 索引 ft_books_search 一致
+const matchExpr = "MATCH(title, author, description, publisher, isbn) AGAINST (? IN NATURAL LANGUAGE MODE)"
+
+// keysMatchExpr 检索键匹配表达式, 对应 FULLTEXT 索引 ft_books_search_keys, 以短语方式匹配简体文本或拼音
+const keysMatchExpr = "MATCH(search_keys) AGAINST (? IN BOOLEAN MODE)"
+
+// ngramTokenSize MySQL ngram 分词长度 (ngram_token_size 默认值)
+const ngramTokenSize = 2
+
+// salePriceExpr 折后价（分）
+const salePriceExpr = "price * (100 - discount)"
+
+// descriptionSnippetRunes 描述高亮片段长度
+const descriptionSnippetRunes = 80
+
+// MySQLEngine 基于 MySQL FULLTEXT 索引 (ngram parser) 的检索引擎
+//
+//	// 索引由 MySQL 随 books 表自动维护, Index / Delete 为空操作
+type MySQLEngine struct {
+	db *gorm.DB
+}
+
+func NewMySQLEngine(db *gorm.DB) *MySQLEngine {
+	return &MySQLEngine{db: db}
+}
+
+// mysqlHit 命中行, 只查询参与检索和高亮的列
+type mysqlHit struct {
+	ID          uint64
+	Title       string
+	Author      string
+	Description string
+	Publisher   string
+	ISBN        string
+	Score       float64
+}
+
+// filtered 构建带关键词匹配和筛选条件的查询
+//
+//	// 关键词同时匹配原文全文索引和检索键 (繁体转简体、拼音), 任一命中即可
+//	// 关键词短于 ngram 分词长度时全文索引无法命中, 退化为标题、作者、ISBN 和检索键的 LIKE 匹配
+func (e *MySQLEngine) filtered(ctx context.Context, q *Query) *gorm.DB {
+	query := e.db.WithContext(ctx).Table("books").Where("status = ?", 1)
+	keys := compactKeyword(q.Keyword)
+	if e.useFullText(q.Keyword) {
+		if keys != "" {
+			query = query.Where("("+matchExpr+" OR "+keysMatchExpr+")", q.Keyword, keysPhrase(keys))
+		} else {
+			query = query.Where(matchExpr, q.Keyword)
+		}
+	} else {
+		like := "%" + escapeLike(q.Keyword) + "%"
+		// 关键词只有标点或符号时检索键为空, 不能参与匹配, 否则 LIKE '%%' 会命中全部图书
+		if keys != "" {
+			query = query.Where("(title LIKE ? OR author LIKE ? OR isbn LIKE ? OR search_keys LIKE ?)",
+				like, like, like, "%"+keys+"%")
+		} else {
+			query = query.Where("(title LIKE ? OR author LIKE ? OR isbn LIKE ?)", like, like, like)
+		}
+	}
+	if len(q.CategoryIDs) > 0 {
+		query = query.Where("category_id IN ?", q.CategoryIDs)
+	}
+	if q.Publisher != "" {
+		query = query.Where("publisher = ?", q.Publisher)
+	}
+	if q.Language != "" {
+		query = query.Where("language = ?", q.Language)
+	}
+	if band, ok := findPriceBand(q.PriceBand); ok {
+		query = query.Where(salePriceExpr+" >= ?", band.Min*100)
+		if band.Max > 0 {
+			query = query.Where(salePriceExpr+" < ?", band.Max*100)
+		}
+	}
+	return query
+}
+
+func (e *MySQLEngine) useFullText(keyword string) bool {
+	return utf8.RuneCountInString(strings.TrimSpace(keyword)) >= ngramTokenSize
+}
+
+// compactKeyword 将关键词归一化为检索键格式: 繁体转简体、小写、去除空白和标点
+func compactKeyword(keyword string) string {
+	return hanzi.CompactPinyin(hanzi.Normalize(keyword))
+}
+
+// likeEscaper 转义 LIKE 通配符, 使用 MySQL 默认转义符 \
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// escapeLike 转义关键词中的 LIKE 通配符, 使其按字面匹配
+func escapeLike(s string) string {
+	return likeEscaper.Replace(s)
+}
+
+// keysPhrase 布尔模式下的短语查询, keys 已去除引号等运算符
+func keysPhrase(keys string) string {
+	return `"` + keys + `"`
+}
+
+// Search 按相关度检索上架图书
+func (e *MySQLEngine) Search(ctx context.Context, q *Query) (*Result, error) {
+	var total int64
+	if err := e.filtered(ctx, q).Count(&total).Error; err != nil {
+		return nil, err
+	}
+
+	var rows []*mysqlHit
+	query := e.filtered(ctx, q)
+	if keys := compactKeyword(q.Keyword); e.useFullText(q.Keyword) && keys != "" {
+		query = query.Select("id, title, author, description, publisher, isbn, "+matchExpr+" + "+keysMatchExpr+" AS score",
+			q.Keyword, keysPhrase(keys))
+	} else if e.useFullText(q.Keyword) {
+		query = query.Select("id, title, author, description, publisher, isbn, "+matchExpr+" AS score", q.Keyword)
+	} else {
+		query = query.Select("id, title, author, description, publisher, isbn, 0 AS score")
+	}
+	err := query.Order("score DESC, sale DESC, id DESC").
+		Scopes(result.Paginate(&q.Page, &q.PageSize)).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	// 繁体关键词经检索键命中简体书名时, 按简体关键词高亮
+	terms := highlightTerms(q.Keyword + " " + hanzi.Normalize(q.Keyword))
+	hits := make([]*Hit, 0, len(rows))
+	for _, row := range rows {
+		hits = append(hits, &Hit{
+			BookID:     row.ID,
+			Score:      row.Score,
+			Highlights: mysqlHighlights(row, terms),
+		})
+	}
+
+	facets, err := e.facets(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+	return &Result{Total: total, Hits: hits, Facets: facets}, nil
+}
+
+// mysqlHighlights 生成各字段的高亮片段, 只返回有命中的字段
+func mysqlHighlights(row *mysqlHit, terms []string) map[string][]string {
+	highlights := make(map[string][]string)
+	fields := []struct {
+		name     string
+		text     string
+		maxRunes int
+	}{
+		{"title", row.Title, 0},
+		{"author", row.Author, 0},
+		{"publisher", row.Publisher, 0},
+		{"isbn", row.ISBN, 0},
+		{"description", row.Description, descriptionSnippetRunes},
+	}
+	for _, field := range fields {
+		if fragment := highlightText(field.text, terms, field.maxRunes); fragment != "" {
+			highlights[field.name] = []string{fragment}
+		}
+	}
+	return highlights
+}
+
+// facets 统计命中结果的分类、出版社、语言和价格区间分布
+func (e *MySQLEngine) facets(ctx context.Context, q *Query) (*Facets, error) {
+	facets := &Facets{}
+	var err error
+	if facets.Category, err = e.termFacet(ctx, q, "category_id"); err != nil {
+		return nil, err
+	}
+	if facets.Publisher, err = e.termFacet(ctx, q, "publisher"); err != nil {
+		return nil, err
+	}
+	if facets.Language, err = e.termFacet(ctx, q, "language"); err != nil {
+		return nil, err
+	}
+	if facets.PriceBand, err = e.termFacet(ctx, q, priceBandExpr()); err != nil {
+		return nil, err
+	}
+	return facets, nil
+}
+
+// termFacet 按列或表达式分组计数, 忽略空值
+func (e *MySQLEngine) termFacet(ctx context.Context, q *Query, expr string) ([]*FacetCount, error) {
+	counts := make([]*FacetCount, 0)
+	err := e.filtered(ctx, q).
+		Select(expr + " AS value, COUNT(*) AS count").
+		Where(expr + " IS NOT NULL").
+		Group("value").
+		Order("count DESC, value ASC").
+		Limit(facetSize).
+		Scan(&counts).Error
+	if err != nil {
+		return nil, err
+	}
+	// 空字符串不作为分面项返回
+	filtered := counts[:0]
+	for _, count := range counts {
+		if count.Value != "" {
+			filtered = append(filtered, count)
+		}
+	}
+	return filtered, nil
+}
+
+// priceBandExpr 将折后价映射为 PriceBands 中 Key 的 CASE 表达式
+func priceBandExpr() string {
+	var sb strings.Builder
+	sb.WriteString("CASE")
+	for _, band := range PriceBands {
+		if band.Max == 0 {
+			continue
+		}
+		fmt.Fprintf(&sb, " WHEN %s < %d THEN '%s'", salePriceExpr, band.Max*100, band.Key)
+	}
+	fmt.Fprintf(&sb, " ELSE '%s' END", PriceBands[len(PriceBands)-1].Key)
+	return sb.String()
+}
+
+// Index MySQL 全文索引随 books 表自动更新
+func (e *MySQLEngine) Index(ctx context.Context, books ...*model.Book) error {
+	return nil
+}
+
+// Delete MySQL 全文索引随 books 表自动更新
+func (e *MySQLEngine) Delete(ctx context.Context, ids ...uint64) error {
+	return nil
+}
+
+// NeedsReindex MySQL 全文索引无需重建
+func (e *MySQLEngine) NeedsReindex() bool {
+	return false
+}
+
+func (e *MySQLEngine) Close() error {
+	return nil
+}
@@ -0,0 +1,74 @@
+package search
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+)
+
+// dryRunEngine 只生成 SQL 不连接数据库
+func dryRunEngine(t *testing.T) *MySQLEngine {
+	t.Helper()
+	db, err := gorm.Open(mysql.New(mysql.Config{
+		DSN:                       "user:pass@tcp(127.0.0.1:3306)/bookstore",
+		SkipInitializeWithVersion: true,
+	}), &gorm.Config{DryRun: true, DisableAutomaticPing: true})
+	if err != nil {
+		t.Fatalf("gorm.Open: %v", err)
+	}
+	return NewMySQLEngine(db)
+}
+
+func TestFilteredLikeFallback(t *testing.T) {
+	e := dryRunEngine(t)
+	tests := []struct {
+		keyword  string
+		wantVars []any
+		wantKeys bool
+	}{
+		// 单个汉字同时匹配检索键
+		{"體", []any{1, "%體%", "%體%", "%體%", "%体%"}, true},
+		// 只有标点时检索键为空, 不匹配检索键
+		{"?", []any{1, "%?%", "%?%", "%?%"}, false},
+		{"·", []any{1, "%·%", "%·%", "%·%"}, false},
+		// LIKE 通配符按字面匹配
+		{"%", []any{1, `%\%%`, `%\%%`, `%\%%`}, false},
+		{"_", []any{1, `%\_%`, `%\_%`, `%\_%`}, false},
+	}
+	for _, tt := range tests {
+		stmt := e.filtered(context.Background(), &Query{Keyword: tt.keyword}).Find(&[]mysqlHit{}).Statement
+		sql := stmt.SQL.String()
+		if got := strings.Contains(sql, "search_keys LIKE"); got != tt.wantKeys {
+			t.Errorf("filtered(%q) matches search_keys = %v, want %v: %s", tt.keyword, got, tt.wantKeys, sql)
+		}
+		if len(stmt.Vars) != len(tt.wantVars) {
+			t.Errorf("filtered(%q) vars = %v, want %v", tt.keyword, stmt.Vars, tt.wantVars)
+			continue
+		}
+		for i := range tt.wantVars {
+			if stmt.Vars[i] != tt.wantVars[i] {
+				t.Errorf("filtered(%q) vars = %v, want %v", tt.keyword, stmt.Vars, tt.wantVars)
+				break
+			}
+		}
+	}
+}
+
+func TestEscapeLike(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"三体", "三体"},
+		{"100%", `100\%`},
+		{"a_b", `a\_b`},
+		{`C:\`, `C:\\`},
+	}
+	for _, tt := range tests {
+		if got := escapeLike(tt.in); got != tt.want {
+			t.Errorf("escapeLike(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
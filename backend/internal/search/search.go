@@ -0,0 +1,138 @@
+// Package search 图书全文检索
+//
+//	// Engine 屏蔽具体检索实现: MySQL FULLTEXT (ngram 分词) 或嵌入式 Bleve 索引, 由配置 search.engine 选择
+//	// 检索只返回命中的图书 ID、相关度、高亮片段和分面统计, 图书详情由调用方回表查询
+package search
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/wangn-tech/bookstore-go/internal/model"
+	"gorm.io/gorm"
+)
+
+// 检索引擎类型
+const (
+	EngineMySQL = "mysql"
+	EngineBleve = "bleve"
+)
+
+// 高亮标签, 各引擎统一使用 <mark>
+const (
+	HighlightPre  = "<mark>"
+	HighlightPost = "</mark>"
+)
+
+// Engine 图书检索引擎
+type Engine interface {
+	// Search 按相关度检索上架图书
+	Search(ctx context.Context, q *Query) (*Result, error)
+	// Index 写入或更新图书索引, 下架图书从索引中删除; 索引由数据库自身维护的引擎为空操作
+	Index(ctx context.Context, books ...*model.Book) error
+	// Delete 从索引中删除图书
+	Delete(ctx context.Context, ids ...uint64) error
+	// NeedsReindex 索引为新建、需要从数据库全量构建时返回 true
+	NeedsReindex() bool
+	// Close 释放索引资源
+	Close() error
+}
+
+// Query 检索条件
+type Query struct {
+	Keyword  string
+	Page     int
+	PageSize int
+
+	// 筛选条件, 同时作用于命中结果和分面统计
+	CategoryIDs []uint64 // 分类 ID (调用方展开子分类)
+	Publisher   string
+	Language    string
+	PriceBand   string // PriceBands 中的 Key
+}
+
+// Hit 单条命中结果
+type Hit struct {
+	BookID     uint64              `json:"book_id"`
+	Score      float64             `json:"score"`      // 相关度, 不同引擎之间不可比较
+	Highlights map[string][]string `json:"highlights"` // 字段 -> 高亮片段
+}
+
+// FacetCount 分面统计项
+type FacetCount struct {
+	Value string `json:"value"`
+	Label string `json:"label,omitempty"` // 展示名称, 如分类名, 由调用方填充
+	Count int64  `json:"count"`
+}
+
+// Facets 分面统计
+type Facets struct {
+	Category  []*FacetCount `json:"category"`
+	Publisher []*FacetCount `json:"publisher"`
+	Language  []*FacetCount `json:"language"`
+	PriceBand []*FacetCount `json:"price_band"`
+}
+
+// Result 检索结果, Hits 已按相关度排序并分页
+type Result struct {
+	Total  int64
+	Hits   []*Hit
+	Facets *Facets
+}
+
+// PriceBand 价格区间, 按折后价（元）划分, 左闭右开; Max 为 0 表示无上限
+type PriceBand struct {
+	Key string
+	Min int
+	Max int
+}
+
+// PriceBands 分面统计使用的价格区间
+var PriceBands = []PriceBand{
+	{Key: "0-30", Min: 0, Max: 30},
+	{Key: "30-60", Min: 30, Max: 60},
+	{Key: "60-100", Min: 60, Max: 100},
+	{Key: "100+", Min: 100},
+}
+
+// findPriceBand 根据 Key 查找价格区间
+func findPriceBand(key string) (PriceBand, bool) {
+	for _, band := range PriceBands {
+		if band.Key == key {
+			return band, true
+		}
+	}
+	return PriceBand{}, false
+}
+
+// facetSize 每个分面最多返回的统计项数
+const facetSize = 20
+
+// Config 检索引擎配置
+type Config struct {
+	Engine    string // mysql 或 bleve, 为空时使用 mysql
+	BlevePath string // Bleve 索引目录
+}
+
+// ValidatePriceBand 校验价格区间 Key, 为空表示不筛选
+func ValidatePriceBand(key string) error {
+	if key == "" {
+		return nil
+	}
+	if _, ok := findPriceBand(key); !ok {
+		return fmt.Errorf("未知的价格区间: %s", key)
+	}
+	return nil
+}
+
+// NewEngine 根据配置创建检索引擎
+func NewEngine(conf Config, db *gorm.DB) (Engine, error) {
+	switch conf.Engine {
+	case "", EngineMySQL:
+		return NewMySQLEngine(db), nil
+	case EngineBleve:
+		return NewBleveEngine(conf.BlevePath)
+	default:
+		return nil, fmt.Errorf("未知的检索引擎: %s", conf.Engine)
+	}
+}
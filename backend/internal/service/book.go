@@ -2,44 +2,135 @@ package service
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
 
 	"github.com/wangn-tech/bookstore-go/common/result"
 	"github.com/wangn-tech/bookstore-go/internal/api/request"
+	"github.com/wangn-tech/bookstore-go/internal/api/response"
+	"github.com/wangn-tech/bookstore-go/internal/app/initializer/database"
 	"github.com/wangn-tech/bookstore-go/internal/model"
 	"github.com/wangn-tech/bookstore-go/internal/repository"
+	"github.com/wangn-tech/bookstore-go/internal/search"
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrBookNotFound 图书不存在
+	ErrBookNotFound = errors.New("图书不存在")
+	// ErrBookHasOrders 图书已存在订单记录，不能删除
+	ErrBookHasOrders = errors.New("图书已存在订单记录，请改为下架")
+	// ErrBookOffShelf 图书已下架
+	ErrBookOffShelf = errors.New("图书已下架")
+	// ErrStockNotEnough 可售库存不足
+	ErrStockNotEnough = errors.New("库存不足")
+	// ErrStockBelowReserved 库存低于待支付订单已预占的数量
+	ErrStockBelowReserved = errors.New("库存不能小于已预占数量")
+	// ErrISBNExists ISBN 与其他图书重复 (按 ISBN-13 比较)
+	ErrISBNExists = errors.New("ISBN 已被其他图书使用")
 )
 
 type IBookService interface {
+	// GetBooksByPage 按条件分页获取上架图书
 	GetBooksByPage(ctx context.Context, dto *request.BooksPageDTO) (*result.PageResult[*model.Book], error)
 	GetBookByID(ctx context.Context, id uint64) (*model.Book, error)
-	SearchBooksWithPagination(ctx context.Context, keyword string, pageReq *request.BooksPageDTO) (*result.PageResult[*model.Book], error)
-	GetBooksByType(ctx context.Context, bookType string) ([]*model.Book, error)
-	GetHotBooks(ctx context.Context, limit int) ([]*model.Book, error)
+	// GetBookByISBN 根据 ISBN-13 获取上架图书
+	GetBookByISBN(ctx context.Context, isbn13 string) (*model.Book, error)
+	// SearchBooks 全文检索上架图书, 返回相关度排序的结果、高亮片段和分面统计
+	SearchBooks(ctx context.Context, dto *request.BookSearchDTO) (*response.BookSearchVO, error)
+	GetBooksByCategory(ctx context.Context, category string) ([]*model.Book, error)
 	GetNewBooks(ctx context.Context, limit int) ([]*model.Book, error)
+
+	// 管理员接口
+	GetBookByIDForAdmin(ctx context.Context, id uint64) (*model.Book, error)
+	GetBooksByPageForAdmin(ctx context.Context, dto *request.BooksPageDTO) (*result.PageResult[*model.Book], error)
+	CreateBook(ctx context.Context, dto *request.AdminBookDTO) (*model.Book, error)
+	UpdateBook(ctx context.Context, id uint64, dto *request.AdminBookDTO) (*model.Book, error)
+	UpdateBookStatus(ctx context.Context, id uint64, status int) (*model.Book, error)
+	DeleteBook(ctx context.Context, id uint64) error
+	// RebuildSearchIndex 从数据库全量重建检索索引, 返回处理的图书数
+	RebuildSearchIndex(ctx context.Context) (int, error)
+	// BackfillSearchKeys 为检索键缺失或过期的图书重新生成检索键, 返回更新的图书数
+	BackfillSearchKeys(ctx context.Context) (int, error)
+	// CheckISBNs 规范化存量图书的 ISBN 并回填 ISBN-13, 报告无效和重复的 ISBN
+	CheckISBNs(ctx context.Context, dryRun bool) (*response.ISBNCheckVO, error)
+	// ImportBooks 从目录文件批量导入图书, 按 ISBN 新建或更新; 单条记录的错误计入导入结果, 不中断导入
+	ImportBooks(ctx context.Context, format string, r io.Reader, dryRun bool) (*response.BookImportVO, error)
+	// ExportBooks 将全部图书按目录格式写出, 返回导出的图书数
+	ExportBooks(ctx context.Context, format string, w io.Writer) (int, error)
 }
 
 type BookServiceImpl struct {
-	bookDao *repository.BookDao
+	bookDao        *repository.BookDao
+	categoryDao    *repository.CategoryDao
+	searchEngine   search.Engine
+	suggestService ISuggestService
 }
 
-func NewBookService(bookDao *repository.BookDao) IBookService {
+func NewBookService(bookDao *repository.BookDao, categoryDao *repository.CategoryDao, searchEngine search.Engine, suggestService ISuggestService) IBookService {
 	return &BookServiceImpl{
-		bookDao: bookDao,
+		bookDao:        bookDao,
+		categoryDao:    categoryDao,
+		searchEngine:   searchEngine,
+		suggestService: suggestService,
 	}
 }
 
-// GetBooksByPage 分页获取书籍列表
+// GetBooksByPage 按条件分页获取书籍列表, 只返回上架图书
 func (b *BookServiceImpl) GetBooksByPage(ctx context.Context, dto *request.BooksPageDTO) (*result.PageResult[*model.Book], error) {
-	pageResult, err := b.bookDao.GetBooksByPage(ctx, dto.Page, dto.PageSize)
+	filter, err := b.buildBookFilter(ctx, dto)
 	if err != nil {
 		return nil, err
 	}
-	return pageResult, nil
+	// 前台列表忽略请求中的 status, 始终排除下架图书
+	onShelf := 1
+	filter.Status = &onShelf
+	return b.bookDao.GetBooksByPage(ctx, filter, dto.Page, dto.PageSize)
 }
 
-// GetHotBooks 获取热销图书
-func (b *BookServiceImpl) GetHotBooks(ctx context.Context, limit int) ([]*model.Book, error) {
-	return b.bookDao.GetHotBooks(ctx, limit)
+// GetBooksByPageForAdmin 按条件分页获取书籍列表（管理员用）, 可按 status 筛选, 默认包含下架图书
+func (b *BookServiceImpl) GetBooksByPageForAdmin(ctx context.Context, dto *request.BooksPageDTO) (*result.PageResult[*model.Book], error) {
+	filter, err := b.buildBookFilter(ctx, dto)
+	if err != nil {
+		return nil, err
+	}
+	filter.Status = dto.Status
+	return b.bookDao.GetBooksByPage(ctx, filter, dto.Page, dto.PageSize)
+}
+
+// buildBookFilter 将列表请求参数转换为查询条件
+//
+//	// 价格参数单位为元, 转换为分后与折后价比较; 分类筛选包含全部子分类
+func (b *BookServiceImpl) buildBookFilter(ctx context.Context, dto *request.BooksPageDTO) (*repository.BookFilter, error) {
+	filter := &repository.BookFilter{
+		MinDiscount:     dto.MinDiscount,
+		Language:        dto.Language,
+		Format:          dto.Format,
+		Publisher:       dto.Publisher,
+		PublishDateFrom: dto.PublishDateFrom,
+		PublishDateTo:   dto.PublishDateTo,
+		InStock:         dto.InStock,
+		Sort:            dto.Sort,
+		Desc:            dto.Order == "desc",
+	}
+	if dto.MinPrice != nil {
+		cents := *dto.MinPrice * 100
+		filter.MinPriceCents = &cents
+	}
+	if dto.MaxPrice != nil {
+		cents := *dto.MaxPrice * 100
+		filter.MaxPriceCents = &cents
+	}
+	if dto.CategoryID != 0 {
+		categories, err := b.categoryDao.GetAllCategories(ctx)
+		if err != nil {
+			return nil, err
+		}
+		filter.CategoryIDs = newCategoryTree(categories).descendantIDs(dto.CategoryID)
+	}
+	return filter, nil
 }
 
 // GetNewBooks 获取新书
@@ -52,12 +143,245 @@ func (b *BookServiceImpl) GetBookByID(ctx context.Context, id uint64) (*model.Bo
 	return b.bookDao.GetBookByID(ctx, id)
 }
 
-// SearchBooksWithPagination 根据关键词搜索书籍，支持分页
-func (b *BookServiceImpl) SearchBooksWithPagination(ctx context.Context, keyword string, pageReq *request.BooksPageDTO) (*result.PageResult[*model.Book], error) {
-	return b.bookDao.SearchBooksWithPagination(ctx, keyword, pageReq.Page, pageReq.PageSize)
+// GetBookByISBN 根据 ISBN-13 获取上架图书
+func (b *BookServiceImpl) GetBookByISBN(ctx context.Context, isbn13 string) (*model.Book, error) {
+	book, err := b.bookDao.GetBookByISBN13(ctx, isbn13)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrBookNotFound
+		}
+		return nil, err
+	}
+	return book, nil
 }
 
-// GetBooksByCategory 根据分类获取书籍列表
-func (b *BookServiceImpl) GetBooksByType(ctx context.Context, bookType string) ([]*model.Book, error) {
-	return b.bookDao.GetBooksByType(ctx, bookType)
+// GetBooksByCategory 根据分类获取书籍列表, 包含全部子分类下的图书
+//
+//	// category 可以是分类 ID 或分类名称; 都匹配不到分类时按旧的 type 字段查询, 兼容旧链接
+func (b *BookServiceImpl) GetBooksByCategory(ctx context.Context, category string) ([]*model.Book, error) {
+	var target *model.Category
+	var err error
+	if id, parseErr := strconv.ParseUint(category, 10, 64); parseErr == nil {
+		target, err = b.categoryDao.GetCategoryByID(ctx, id)
+	} else {
+		target, err = b.categoryDao.GetCategoryByName(ctx, category)
+	}
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return b.bookDao.GetBooksByType(ctx, category)
+		}
+		return nil, err
+	}
+
+	categories, err := b.categoryDao.GetAllCategories(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return b.bookDao.GetBooksByCategoryIDs(ctx, newCategoryTree(categories).descendantIDs(target.ID))
+}
+
+// GetBookByIDForAdmin 根据ID获取书籍信息（管理员用）, 不过滤 Status
+func (b *BookServiceImpl) GetBookByIDForAdmin(ctx context.Context, id uint64) (*model.Book, error) {
+	book, err := b.bookDao.GetBookByIDForAdmin(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrBookNotFound
+		}
+		return nil, err
+	}
+	return book, nil
+}
+
+// CreateBook 创建图书
+func (b *BookServiceImpl) CreateBook(ctx context.Context, dto *request.AdminBookDTO) (*model.Book, error) {
+	book := &model.Book{
+		Status: 1, // 默认上架
+	}
+	applyAdminBookDTO(book, dto)
+	err := database.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := b.checkISBNTx(ctx, tx, book); err != nil {
+			return err
+		}
+		if err := b.bookDao.CreateBookTx(ctx, tx, book); err != nil {
+			return err
+		}
+		return b.adjustCategoryCountTx(ctx, tx, nil, book)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return b.reloadAndIndex(ctx, book.ID, nil)
+}
+
+// UpdateBook 更新图书信息
+func (b *BookServiceImpl) UpdateBook(ctx context.Context, id uint64, dto *request.AdminBookDTO) (*model.Book, error) {
+	var before model.Book
+	err := database.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		book, err := b.lockBookTx(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+		// 在库库存不能低于待支付订单已预占的数量
+		if dto.Stock < book.Reserved {
+			return fmt.Errorf("%w: 当前已预占 %d 本", ErrStockBelowReserved, book.Reserved)
+		}
+		before = *book
+		applyAdminBookDTO(book, dto)
+		if err := b.checkISBNTx(ctx, tx, book); err != nil {
+			return err
+		}
+		if err := b.bookDao.UpdateBookTx(ctx, tx, book); err != nil {
+			return err
+		}
+		return b.adjustCategoryCountTx(ctx, tx, &before, book)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return b.reloadAndIndex(ctx, id, &before)
+}
+
+// UpdateBookStatus 图书上架/下架
+func (b *BookServiceImpl) UpdateBookStatus(ctx context.Context, id uint64, status int) (*model.Book, error) {
+	var before model.Book
+	err := database.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		book, err := b.lockBookTx(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+		before = *book
+		if err := b.bookDao.UpdateBookStatusTx(ctx, tx, id, status); err != nil {
+			return err
+		}
+		book.Status = status
+		return b.adjustCategoryCountTx(ctx, tx, &before, book)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return b.reloadAndIndex(ctx, id, &before)
+}
+
+// DeleteBook 删除图书
+//
+//	// order_items 对 books 为级联删除, 已产生订单的图书直接删除会丢失订单明细, 只允许下架
+func (b *BookServiceImpl) DeleteBook(ctx context.Context, id uint64) error {
+	var book *model.Book
+	err := database.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var err error
+		book, err = b.lockBookTx(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+		hasOrders, err := b.bookDao.HasOrderItems(ctx, id)
+		if err != nil {
+			return err
+		}
+		if hasOrders {
+			return ErrBookHasOrders
+		}
+		if err := b.bookDao.DeleteBookTx(ctx, tx, id); err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrBookNotFound
+			}
+			return err
+		}
+		return b.adjustCategoryCountTx(ctx, tx, book, nil)
+	})
+	if err != nil {
+		return err
+	}
+	b.removeSearchIndex(ctx, id)
+	b.syncSuggestIndex(ctx, book, nil)
+	return nil
+}
+
+// reloadAndIndex 写操作提交后重新读取图书并同步检索索引和联想索引, before 为变更前的图书 (新建时为 nil)
+func (b *BookServiceImpl) reloadAndIndex(ctx context.Context, id uint64, before *model.Book) (*model.Book, error) {
+	book, err := b.GetBookByIDForAdmin(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	b.syncSearchIndex(ctx, book)
+	b.syncSuggestIndex(ctx, before, book)
+	return book, nil
+}
+
+// lockBookTx 在事务中锁定图书
+func (b *BookServiceImpl) lockBookTx(ctx context.Context, tx *gorm.DB, id uint64) (*model.Book, error) {
+	book, err := b.bookDao.GetBookForUpdateTx(ctx, tx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrBookNotFound
+		}
+		return nil, err
+	}
+	return book, nil
+}
+
+// checkISBNTx 检查图书的 ISBN-13 是否已被其他图书使用, ISBN 无效时不检查
+func (b *BookServiceImpl) checkISBNTx(ctx context.Context, tx *gorm.DB, book *model.Book) error {
+	_, isbn13 := model.NormalizeISBN(book.ISBN)
+	if isbn13 == nil {
+		return nil
+	}
+	id, err := b.bookDao.GetBookIDByISBN13Tx(ctx, tx, *isbn13)
+	if err != nil {
+		return err
+	}
+	if id != 0 && id != book.ID {
+		return fmt.Errorf("%w: 图书 %d", ErrISBNExists, id)
+	}
+	return nil
+}
+
+// adjustCategoryCountTx 根据图书变更前后的分类和上下架状态调整分类图书数量
+//
+//	// Category.BookCount 只统计直属该分类的上架图书; before 为 nil 表示新建, after 为 nil 表示删除
+func (b *BookServiceImpl) adjustCategoryCountTx(ctx context.Context, tx *gorm.DB, before, after *model.Book) error {
+	counted := func(book *model.Book) (uint64, bool) {
+		if book == nil || book.Status != 1 || book.CategoryID == 0 {
+			return 0, false
+		}
+		return book.CategoryID, true
+	}
+	oldID, oldCounted := counted(before)
+	newID, newCounted := counted(after)
+	if oldCounted && newCounted && oldID == newID {
+		return nil
+	}
+	if oldCounted {
+		if err := b.categoryDao.IncrBookCountTx(ctx, tx, oldID, -1); err != nil {
+			return err
+		}
+	}
+	if newCounted {
+		if err := b.categoryDao.IncrBookCountTx(ctx, tx, newID, 1); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// applyAdminBookDTO 将管理员请求参数写入图书模型
+func applyAdminBookDTO(book *model.Book, dto *request.AdminBookDTO) {
+	book.Title = dto.Title
+	book.Author = dto.Author
+	book.Series = dto.Series
+	book.Price = dto.Price
+	book.Discount = dto.Discount
+	book.Type = dto.Type
+	book.Stock = dto.Stock
+	if dto.Status != nil {
+		book.Status = *dto.Status
+	}
+	book.Description = dto.Description
+	book.CoverURL = dto.CoverURL
+	book.ISBN = dto.ISBN
+	book.Publisher = dto.Publisher
+	book.PublishDate = dto.PublishDate
+	book.Pages = dto.Pages
+	book.Language = dto.Language
+	book.Format = dto.Format
+	book.CategoryID = dto.CategoryID
 }
@@ -0,0 +1,325 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/wangn-tech/bookstore-go/internal/api/response"
+	"github.com/wangn-tech/bookstore-go/internal/app/initializer/database"
+	"github.com/wangn-tech/bookstore-go/internal/catalog"
+	"github.com/wangn-tech/bookstore-go/internal/model"
+	"github.com/wangn-tech/bookstore-go/pkg/isbn"
+	"github.com/wangn-tech/bookstore-go/pkg/logger"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// ErrCatalogFile 目录文件无法解析 (表头错误、XML 结构错误等), 整个文件不能导入
+var ErrCatalogFile = errors.New("目录文件格式错误")
+
+// importMaxErrors 导入结果中最多返回的错误明细数, 失败数仍完整统计
+const importMaxErrors = 1000
+
+// catalogCategories 导入导出时使用的分类索引
+type catalogCategories struct {
+	byID   map[uint64]*model.Category
+	byName map[string]*model.Category
+}
+
+func (b *BookServiceImpl) loadCatalogCategories(ctx context.Context) (*catalogCategories, error) {
+	categories, err := b.categoryDao.GetAllCategories(ctx)
+	if err != nil {
+		return nil, err
+	}
+	c := &catalogCategories{
+		byID:   make(map[uint64]*model.Category, len(categories)),
+		byName: make(map[string]*model.Category, len(categories)),
+	}
+	for _, category := range categories {
+		c.byID[category.ID] = category
+		c.byName[category.Name] = category
+	}
+	return c, nil
+}
+
+// resolve 解析记录中的分类, 分类 ID 优先于名称; 两者都未提供时 ok 为 false
+func (c *catalogCategories) resolve(record *catalog.Record) (id uint64, ok bool, err error) {
+	if record.CategoryID != nil {
+		if _, exists := c.byID[*record.CategoryID]; !exists {
+			return 0, false, fmt.Errorf("分类 %d 不存在", *record.CategoryID)
+		}
+		return *record.CategoryID, true, nil
+	}
+	if record.Category != nil {
+		name := strings.TrimSpace(*record.Category)
+		category, exists := c.byName[name]
+		if !exists {
+			return 0, false, fmt.Errorf("分类 %q 不存在", name)
+		}
+		return category.ID, true, nil
+	}
+	return 0, false, nil
+}
+
+func (c *catalogCategories) name(id uint64) string {
+	if category := c.byID[id]; category != nil {
+		return category.Name
+	}
+	return ""
+}
+
+// ImportBooks 批量导入图书
+//
+//	// 以 ISBN-13 匹配已有图书 (ISBN-10 转换为 ISBN-13 后比较, 见 loadISBNIndex): 已存在则更新记录中提供的字段, 否则新建
+//	// 每条记录单独校验并在独立事务中写入, 失败的记录不影响其他记录; 同一文件中重复的 ISBN 只导入第一条
+//	// dryRun 为 true 时只做校验, 不写入数据库
+func (b *BookServiceImpl) ImportBooks(ctx context.Context, format string, r io.Reader, dryRun bool) (*response.BookImportVO, error) {
+	reader, err := catalog.NewReader(format, r)
+	if err != nil {
+		if errors.Is(err, catalog.ErrUnknownFormat) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("%w: %v", ErrCatalogFile, err)
+	}
+	categories, err := b.loadCatalogCategories(ctx)
+	if err != nil {
+		return nil, err
+	}
+	bookIDs, err := b.loadISBNIndex(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &response.BookImportVO{DryRun: dryRun, Errors: []*response.BookImportErrorVO{}}
+	fail := func(line int, rawISBN string, err error) {
+		report.Failed++
+		if len(report.Errors) < importMaxErrors {
+			report.Errors = append(report.Errors, &response.BookImportErrorVO{Line: line, ISBN: rawISBN, Message: err.Error()})
+		}
+	}
+	seen := make(map[string]int)
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			var rowErr *catalog.RowError
+			if errors.As(err, &rowErr) {
+				report.Total++
+				fail(rowErr.Line, "", rowErr.Err)
+				continue
+			}
+			return nil, fmt.Errorf("%w: %v", ErrCatalogFile, err)
+		}
+		report.Total++
+
+		isbn13, err := isbn.To13(record.ISBN)
+		if err != nil {
+			fail(record.Line, record.ISBN, err)
+			continue
+		}
+		if line, ok := seen[isbn13]; ok {
+			fail(record.Line, record.ISBN, fmt.Errorf("与第 %d 行的 ISBN 重复", line))
+			continue
+		}
+		seen[isbn13] = record.Line
+
+		if id, ok := bookIDs[isbn13]; ok {
+			if err := b.importUpdate(ctx, id, record, categories, dryRun); err != nil {
+				fail(record.Line, record.ISBN, err)
+				continue
+			}
+			report.Updated++
+			continue
+		}
+		if err := b.importCreate(ctx, record, categories, dryRun); err != nil {
+			fail(record.Line, record.ISBN, err)
+			continue
+		}
+		report.Created++
+	}
+	return report, nil
+}
+
+// loadISBNIndex 建立 ISBN-13 → 图书 ID 的索引
+//
+//	// 优先使用 isbn13 列; 尚未运行 check-isbns 回填的图书 (isbn13 为空) 按 isbn 列换算为 ISBN-13 匹配,
+//	// 多本图书换算结果相同时匹配已占用 isbn13 的图书, 否则匹配 ID 最小的图书
+func (b *BookServiceImpl) loadISBNIndex(ctx context.Context) (map[string]uint64, error) {
+	rows, err := b.bookDao.GetBookISBNs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	bookIDs := make(map[string]uint64, len(rows))
+	for _, row := range rows {
+		if row.ISBN13 != nil {
+			bookIDs[*row.ISBN13] = row.ID
+		}
+	}
+	for _, row := range rows {
+		if row.ISBN13 != nil {
+			continue
+		}
+		isbn13, err := isbn.To13(row.ISBN)
+		if err != nil {
+			continue
+		}
+		if _, ok := bookIDs[isbn13]; !ok {
+			bookIDs[isbn13] = row.ID
+		}
+	}
+	return bookIDs, nil
+}
+
+// importCreate 按记录新建图书, 未提供的字段使用与管理员创建图书相同的默认值
+func (b *BookServiceImpl) importCreate(ctx context.Context, record *catalog.Record, categories *catalogCategories, dryRun bool) error {
+	book := &model.Book{
+		Status: 1, // 默认上架
+		ISBN:   isbn.Normalize(record.ISBN),
+	}
+	if err := applyCatalogRecord(book, record, categories); err != nil {
+		return err
+	}
+	if dryRun {
+		return nil
+	}
+	err := database.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := b.checkISBNTx(ctx, tx, book); err != nil {
+			return err
+		}
+		if err := b.bookDao.CreateBookTx(ctx, tx, book); err != nil {
+			return err
+		}
+		return b.adjustCategoryCountTx(ctx, tx, nil, book)
+	})
+	if err != nil {
+		return err
+	}
+	b.importReindex(ctx, book.ID, nil)
+	return nil
+}
+
+// importUpdate 按记录更新已有图书, 只覆盖记录中提供的字段
+func (b *BookServiceImpl) importUpdate(ctx context.Context, id uint64, record *catalog.Record, categories *catalogCategories, dryRun bool) error {
+	if dryRun {
+		book, err := b.GetBookByIDForAdmin(ctx, id)
+		if err != nil {
+			return err
+		}
+		return applyCatalogRecord(book, record, categories)
+	}
+	var before model.Book
+	err := database.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		book, err := b.lockBookTx(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+		before = *book
+		if err := applyCatalogRecord(book, record, categories); err != nil {
+			return err
+		}
+		if err := b.checkISBNTx(ctx, tx, book); err != nil {
+			return err
+		}
+		if err := b.bookDao.UpdateBookTx(ctx, tx, book); err != nil {
+			return err
+		}
+		return b.adjustCategoryCountTx(ctx, tx, &before, book)
+	})
+	if err != nil {
+		return err
+	}
+	b.importReindex(ctx, id, &before)
+	return nil
+}
+
+// importReindex 记录已提交, 重新读取失败时只记录日志, 检索索引可通过 reindex-search 修复
+func (b *BookServiceImpl) importReindex(ctx context.Context, id uint64, before *model.Book) {
+	if _, err := b.reloadAndIndex(ctx, id, before); err != nil {
+		logger.Log.Warn("ImportBooks: 同步检索索引失败", zap.Uint64("id", id), zap.Error(err))
+	}
+}
+
+// applyCatalogRecord 将记录写入图书并校验, 校验规则与 request.AdminBookDTO 一致
+func applyCatalogRecord(book *model.Book, record *catalog.Record, categories *catalogCategories) error {
+	record.Apply(book)
+	categoryID, ok, err := categories.resolve(record)
+	if err != nil {
+		return err
+	}
+	if ok {
+		book.CategoryID = categoryID
+	}
+
+	if book.Title == "" {
+		return errors.New("title 不能为空")
+	}
+	for _, field := range []struct {
+		name  string
+		value string
+		max   int
+	}{
+		{"title", book.Title, 255},
+		{"author", book.Author, 100},
+		{"series", book.Series, 100},
+		{"type", book.Type, 50},
+		{"cover_url", book.CoverURL, 255},
+		{"publisher", book.Publisher, 100},
+		{"publish_date", book.PublishDate, 50},
+		{"language", book.Language, 20},
+		{"format", book.Format, 20},
+	} {
+		if utf8.RuneCountInString(field.value) > field.max {
+			return fmt.Errorf("%s 长度不能超过 %d", field.name, field.max)
+		}
+	}
+	switch {
+	case book.Price <= 0:
+		return errors.New("price 必须大于 0")
+	case book.Discount < 0 || book.Discount > 100:
+		return errors.New("discount 必须在 0 到 100 之间")
+	case book.Stock < 0:
+		return errors.New("stock 不能为负数")
+	case book.Status != 0 && book.Status != 1:
+		return errors.New("status 只能为 0 或 1")
+	case book.Pages < 0:
+		return errors.New("pages 不能为负数")
+	case book.CategoryID == 0:
+		return errors.New("缺少分类, 请提供 category_id 或 category")
+	case book.Stock < book.Reserved:
+		// 在库库存不能低于待支付订单已预占的数量
+		return fmt.Errorf("%w: 当前已预占 %d 本", ErrStockBelowReserved, book.Reserved)
+	}
+	return nil
+}
+
+// ExportBooks 按格式导出全部图书 (含下架图书), 返回导出的图书数
+func (b *BookServiceImpl) ExportBooks(ctx context.Context, format string, w io.Writer) (int, error) {
+	categories, err := b.loadCatalogCategories(ctx)
+	if err != nil {
+		return 0, err
+	}
+	writer, err := catalog.NewWriter(format, w)
+	if err != nil {
+		return 0, err
+	}
+	count := 0
+	err = b.bookDao.ScanBooks(ctx, reindexBatchSize, func(books []*model.Book) error {
+		for _, book := range books {
+			if err := writer.Write(catalog.FromBook(book, categories.name(book.CategoryID))); err != nil {
+				return err
+			}
+			count++
+		}
+		return nil
+	})
+	if err != nil {
+		return count, err
+	}
+	return count, writer.Close()
+}
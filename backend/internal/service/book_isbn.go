@@ -0,0 +1,106 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/wangn-tech/bookstore-go/internal/api/response"
+	"github.com/wangn-tech/bookstore-go/internal/model"
+	"github.com/wangn-tech/bookstore-go/pkg/isbn"
+)
+
+// isbnFix 需要写回的 ISBN
+type isbnFix struct {
+	book   *model.Book
+	isbn   string
+	isbn13 *string
+}
+
+// CheckISBNs 规范化存量图书的 ISBN 并回填 ISBN-13
+//
+//	// ISBN 由图书写入时的钩子规范化, 此方法用于处理唯一索引上线前的存量数据和手工改库的数据
+//	// 多本图书的 ISBN-13 相同时, 已占用该 ISBN-13 的图书优先, 否则 ID 最小的图书优先, 其余报告为重复
+//	// 先清空需要变更的 ISBN-13 再写入新值, 避免互换时违反唯一索引
+func (b *BookServiceImpl) CheckISBNs(ctx context.Context, dryRun bool) (*response.ISBNCheckVO, error) {
+	var books []*model.Book
+	err := b.bookDao.ScanBooks(ctx, reindexBatchSize, func(batch []*model.Book) error {
+		books = append(books, batch...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	owners := make(map[string]uint64)
+	for _, book := range books {
+		if isbn13, err := isbn.To13(book.ISBN); err == nil && book.ISBN13 != nil && *book.ISBN13 == isbn13 {
+			owners[isbn13] = book.ID
+		}
+	}
+
+	report := &response.ISBNCheckVO{
+		DryRun:  dryRun,
+		Checked: len(books),
+		Invalid: make([]*response.InvalidISBN, 0),
+	}
+	var fixes []*isbnFix
+	for _, book := range books {
+		fix := &isbnFix{book: book, isbn: isbn.Normalize(book.ISBN)}
+		isbn13, err := isbn.To13(fix.isbn)
+		if err != nil {
+			report.Invalid = append(report.Invalid, &response.InvalidISBN{ID: book.ID, Title: book.Title, ISBN: book.ISBN, Reason: err.Error()})
+		} else if owner, ok := owners[isbn13]; ok && owner != book.ID {
+			report.Invalid = append(report.Invalid, &response.InvalidISBN{ID: book.ID, Title: book.Title, ISBN: book.ISBN, Reason: fmt.Sprintf("与图书 %d 的 ISBN 重复", owner)})
+		} else {
+			owners[isbn13] = book.ID
+			fix.isbn13 = &isbn13
+		}
+		if fix.isbn == book.ISBN && equalStringPtr(fix.isbn13, book.ISBN13) {
+			continue
+		}
+		fixes = append(fixes, fix)
+	}
+	report.Normalized = len(fixes)
+	if dryRun {
+		return report, nil
+	}
+
+	for _, fix := range fixes {
+		if err := b.bookDao.UpdateISBN(ctx, fix.book.ID, fix.isbn, nil); err != nil {
+			return nil, err
+		}
+	}
+	// ISBN 参与全文检索, 规范化后的图书同步更新检索索引
+	reindex := make([]*model.Book, 0, reindexBatchSize)
+	for _, fix := range fixes {
+		if fix.isbn13 != nil {
+			if err := b.bookDao.UpdateISBN(ctx, fix.book.ID, fix.isbn, fix.isbn13); err != nil {
+				return nil, err
+			}
+		}
+		if fix.book.ISBN == fix.isbn {
+			continue
+		}
+		fix.book.ISBN, fix.book.ISBN13 = fix.isbn, fix.isbn13
+		reindex = append(reindex, fix.book)
+		if len(reindex) == reindexBatchSize {
+			if err := b.searchEngine.Index(ctx, reindex...); err != nil {
+				return nil, err
+			}
+			reindex = reindex[:0]
+		}
+	}
+	if len(reindex) > 0 {
+		if err := b.searchEngine.Index(ctx, reindex...); err != nil {
+			return nil, err
+		}
+	}
+	return report, nil
+}
+
+func equalStringPtr(a, b *string) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
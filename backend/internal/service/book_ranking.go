@@ -0,0 +1,323 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	goredis "github.com/go-redis/redis/v8"
+	"github.com/wangn-tech/bookstore-go/internal/app/initializer/redis"
+	"github.com/wangn-tech/bookstore-go/internal/model"
+	"github.com/wangn-tech/bookstore-go/internal/repository"
+	"github.com/wangn-tech/bookstore-go/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// IBookRankingService 畅销榜服务接口
+type IBookRankingService interface {
+	// GetHotBooks 获取畅销榜, period 为 day/week/month 时按近 1/7/30 天销量, 其他值按累计销量;
+	// categoryID 不为 0 时只统计该分类及其子分类下的图书
+	GetHotBooks(ctx context.Context, period string, categoryID uint64, limit int) ([]*model.Book, error)
+	// RecordPaid 订单支付后计入支付当日的日榜
+	RecordPaid(ctx context.Context, order *model.Order) error
+	// RecordRefunded 订单退款后从支付当日的日榜中扣除
+	RecordRefunded(ctx context.Context, order *model.Order) error
+	// RankingExists 日榜是否已构建
+	RankingExists(ctx context.Context) (bool, error)
+	// RebuildRankings 根据已支付订单重建保留期内 (31 天) 的日榜, 返回处理的 (日期, 图书) 记录数
+	RebuildRankings(ctx context.Context) (int, error)
+}
+
+// 畅销榜统计周期
+const (
+	RankPeriodDay   = "day"
+	RankPeriodWeek  = "week"
+	RankPeriodMonth = "month"
+	RankPeriodAll   = "all"
+)
+
+// rankPeriodDays 各统计周期包含的天数 (含当天)
+var rankPeriodDays = map[string]int{
+	RankPeriodDay:   1,
+	RankPeriodWeek:  7,
+	RankPeriodMonth: 30,
+}
+
+// 畅销榜存储结构
+//
+//	// rank:sale:{yyyymmdd}                   zset: 图书 ID → 当日销量
+//	// rank:sale:{yyyymmdd}:c:{分类ID}         zset: 直属该分类的图书 ID → 当日销量
+//	// rank:sale:{周期}:{yyyymmdd}[:c:{分类ID}] 多日、多分类合并 (ZUNIONSTORE) 的结果, 短时间缓存
+//	// rank:sale:built                        日榜已构建的标记
+//	// 日榜按支付日期统计, 退款时从支付当日扣除; 过期时间覆盖最长的统计周期
+const (
+	rankSaleKeyPrefix = "rank:sale:"
+	rankBuiltKey      = "rank:sale:built"
+
+	// rankRetentionDays 日榜保留天数, 比最长的统计周期多一天, 避免跨零点时缺少最早一天
+	rankRetentionDays = 31
+	// rankUnionTTL 合并结果的缓存时间, 期间的新增销量在缓存过期后体现
+	rankUnionTTL = 5 * time.Minute
+	// rankOverfetch 多取的候选数, 补足已下架或已删除的图书
+	rankOverfetch = 20
+	// rankDayLayout 日榜键中的日期格式, 与 repository.BookDailySale.Day 一致
+	rankDayLayout = "20060102"
+)
+
+// rankDayKey 全站日榜
+func rankDayKey(day string) string {
+	return rankSaleKeyPrefix + day
+}
+
+// rankCategoryDayKey 分类日榜
+func rankCategoryDayKey(day string, categoryID uint64) string {
+	return fmt.Sprintf("%s%s:c:%d", rankSaleKeyPrefix, day, categoryID)
+}
+
+// startOfDay 当天零点 (本地时区)
+func startOfDay(t time.Time) time.Time {
+	year, month, day := t.Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, t.Location())
+}
+
+// BookRankingServiceImpl 畅销榜服务实现
+//
+//	// 日榜保存在 Redis, 订单支付和退款后增量更新; Redis 数据丢失时启动时或通过命令行从订单重建
+type BookRankingServiceImpl struct {
+	bookDao     *repository.BookDao
+	categoryDao *repository.CategoryDao
+	orderDao    *repository.OrderDao
+}
+
+func NewBookRankingService(bookDao *repository.BookDao, categoryDao *repository.CategoryDao, orderDao *repository.OrderDao) IBookRankingService {
+	return &BookRankingServiceImpl{
+		bookDao:     bookDao,
+		categoryDao: categoryDao,
+		orderDao:    orderDao,
+	}
+}
+
+// GetHotBooks 获取畅销榜
+func (r *BookRankingServiceImpl) GetHotBooks(ctx context.Context, period string, categoryID uint64, limit int) ([]*model.Book, error) {
+	var categoryIDs []uint64
+	if categoryID != 0 {
+		categories, err := r.categoryDao.GetAllCategories(ctx)
+		if err != nil {
+			return nil, err
+		}
+		tree := newCategoryTree(categories)
+		if tree.byID[categoryID] == nil {
+			return nil, ErrCategoryNotFound
+		}
+		categoryIDs = tree.descendantIDs(categoryID)
+	}
+
+	days, ok := rankPeriodDays[period]
+	if !ok {
+		return r.bookDao.GetHotBooks(ctx, categoryIDs, limit)
+	}
+
+	key, err := r.rankingKey(ctx, period, days, categoryID, categoryIDs)
+	if err != nil {
+		return nil, err
+	}
+	members, err := redis.RedisClient.ZRevRangeByScore(ctx, key, &goredis.ZRangeBy{
+		Min:   "(0",
+		Max:   "+inf",
+		Count: int64(limit + rankOverfetch),
+	}).Result()
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]uint64, 0, len(members))
+	for _, member := range members {
+		id, err := strconv.ParseUint(member, 10, 64)
+		if err != nil {
+			continue
+		}
+		ids = append(ids, id)
+	}
+
+	books, err := r.bookDao.GetBooksByIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[uint64]*model.Book, len(books))
+	for _, book := range books {
+		byID[book.ID] = book
+	}
+	hot := make([]*model.Book, 0, limit)
+	for _, id := range ids {
+		// 上榜后下架或删除的图书不展示
+		if book := byID[id]; book != nil && book.Status == 1 {
+			hot = append(hot, book)
+			if len(hot) >= limit {
+				break
+			}
+		}
+	}
+	return hot, nil
+}
+
+// rankingKey 返回统计周期和分类对应的榜单键
+//
+//	// 全站日榜直接读取; 其余情况将各天、各子分类的日榜合并到缓存键, 缓存未过期时直接复用
+func (r *BookRankingServiceImpl) rankingKey(ctx context.Context, period string, days int, categoryID uint64, categoryIDs []uint64) (string, error) {
+	today := startOfDay(time.Now())
+	if days == 1 && categoryID == 0 {
+		return rankDayKey(today.Format(rankDayLayout)), nil
+	}
+
+	dest := rankSaleKeyPrefix + period + ":" + today.Format(rankDayLayout)
+	if categoryID != 0 {
+		dest += fmt.Sprintf(":c:%d", categoryID)
+	}
+	n, err := redis.RedisClient.Exists(ctx, dest).Result()
+	if err != nil {
+		return "", err
+	}
+	if n > 0 {
+		return dest, nil
+	}
+
+	var keys []string
+	for i := 0; i < days; i++ {
+		day := today.AddDate(0, 0, -i).Format(rankDayLayout)
+		if categoryID == 0 {
+			keys = append(keys, rankDayKey(day))
+			continue
+		}
+		for _, id := range categoryIDs {
+			keys = append(keys, rankCategoryDayKey(day, id))
+		}
+	}
+	// 所有日榜均不存在时 ZUNIONSTORE 不会创建 dest, 下次请求重新合并
+	_, err = redis.RedisClient.TxPipelined(ctx, func(pipe goredis.Pipeliner) error {
+		pipe.ZUnionStore(ctx, dest, &goredis.ZStore{Keys: keys})
+		pipe.Expire(ctx, dest, rankUnionTTL)
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	return dest, nil
+}
+
+// RecordPaid 订单支付后计入日榜
+func (r *BookRankingServiceImpl) RecordPaid(ctx context.Context, order *model.Order) error {
+	return r.recordSales(ctx, order, 1)
+}
+
+// RecordRefunded 订单退款后从日榜中扣除
+func (r *BookRankingServiceImpl) RecordRefunded(ctx context.Context, order *model.Order) error {
+	return r.recordSales(ctx, order, -1)
+}
+
+// recordSales 按订单支付日期调整日榜销量, sign 为 1 表示支付, -1 表示退款
+func (r *BookRankingServiceImpl) recordSales(ctx context.Context, order *model.Order, sign int) error {
+	if order.PaymentTime == nil || len(order.OrderItems) == 0 {
+		return nil
+	}
+	day := startOfDay(*order.PaymentTime)
+	expireAt := day.AddDate(0, 0, rankRetentionDays)
+	// 支付日期已超出保留期, 日榜已过期
+	if !time.Now().Before(expireAt) {
+		return nil
+	}
+
+	ids := make([]uint64, 0, len(order.OrderItems))
+	for _, item := range order.OrderItems {
+		ids = append(ids, item.BookID)
+	}
+	books, err := r.bookDao.GetBooksByIDs(ctx, ids)
+	if err != nil {
+		return err
+	}
+	categoryOf := make(map[uint64]uint64, len(books))
+	for _, book := range books {
+		categoryOf[book.ID] = book.CategoryID
+	}
+
+	dayStr := day.Format(rankDayLayout)
+	dayKey := rankDayKey(dayStr)
+	pipe := redis.RedisClient.TxPipeline()
+	for _, item := range order.OrderItems {
+		member := strconv.FormatUint(item.BookID, 10)
+		qty := float64(sign * item.Quantity)
+		pipe.ZIncrBy(ctx, dayKey, qty, member)
+		if categoryID := categoryOf[item.BookID]; categoryID != 0 {
+			categoryKey := rankCategoryDayKey(dayStr, categoryID)
+			pipe.ZIncrBy(ctx, categoryKey, qty, member)
+			pipe.ExpireAt(ctx, categoryKey, expireAt)
+		}
+	}
+	pipe.ExpireAt(ctx, dayKey, expireAt)
+	_, err = pipe.Exec(ctx)
+	return err
+}
+
+// RankingExists 日榜是否已构建
+func (r *BookRankingServiceImpl) RankingExists(ctx context.Context) (bool, error) {
+	n, err := redis.RedisClient.Exists(ctx, rankBuiltKey).Result()
+	if err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
+
+// RebuildRankings 根据已支付订单重建日榜
+//
+//	// 每天的日榜 (含各分类) 在一个事务中删除后重新写入, 重建期间读取到的是完整的旧榜或新榜;
+//	// 分类按图书当前所属分类统计; 重建期间支付的订单可能被重复计入或遗漏, 下次重建时修正
+func (r *BookRankingServiceImpl) RebuildRankings(ctx context.Context) (int, error) {
+	today := startOfDay(time.Now())
+	since := today.AddDate(0, 0, -(rankRetentionDays - 1))
+	sales, err := r.orderDao.GetDailyBookSales(ctx, since)
+	if err != nil {
+		return 0, err
+	}
+	salesByDay := make(map[string][]*repository.BookDailySale)
+	for _, sale := range sales {
+		salesByDay[sale.Day] = append(salesByDay[sale.Day], sale)
+	}
+
+	for i := 0; i < rankRetentionDays; i++ {
+		day := since.AddDate(0, 0, i)
+		dayStr := day.Format(rankDayLayout)
+		expireAt := day.AddDate(0, 0, rankRetentionDays)
+
+		// 找出当天已有的分类日榜, 没有销量的分类也需要删除
+		stale := []string{rankDayKey(dayStr)}
+		iter := redis.RedisClient.Scan(ctx, 0, rankDayKey(dayStr)+":c:*", 100).Iterator()
+		for iter.Next(ctx) {
+			stale = append(stale, iter.Val())
+		}
+		if err := iter.Err(); err != nil {
+			return 0, err
+		}
+
+		_, err := redis.RedisClient.TxPipelined(ctx, func(pipe goredis.Pipeliner) error {
+			pipe.Del(ctx, stale...)
+			for _, sale := range salesByDay[dayStr] {
+				member := strconv.FormatUint(sale.BookID, 10)
+				pipe.ZIncrBy(ctx, rankDayKey(dayStr), float64(sale.Quantity), member)
+				if sale.CategoryID != 0 {
+					pipe.ZIncrBy(ctx, rankCategoryDayKey(dayStr, sale.CategoryID), float64(sale.Quantity), member)
+					pipe.ExpireAt(ctx, rankCategoryDayKey(dayStr, sale.CategoryID), expireAt)
+				}
+			}
+			pipe.ExpireAt(ctx, rankDayKey(dayStr), expireAt)
+			return nil
+		})
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	if err := redis.RedisClient.Set(ctx, rankBuiltKey, time.Now().Format(time.RFC3339), 0).Err(); err != nil {
+		return 0, err
+	}
+	logger.Log.Info("RebuildRankings: 畅销榜重建完成", zap.Int("records", len(sales)))
+	return len(sales), nil
+}
@@ -0,0 +1,182 @@
+package service
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/wangn-tech/bookstore-go/internal/api/request"
+	"github.com/wangn-tech/bookstore-go/internal/api/response"
+	"github.com/wangn-tech/bookstore-go/internal/model"
+	"github.com/wangn-tech/bookstore-go/internal/repository"
+	"github.com/wangn-tech/bookstore-go/internal/search"
+	"github.com/wangn-tech/bookstore-go/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// reindexBatchSize 重建索引时每批读取的图书数
+const reindexBatchSize = 100
+
+// SearchBooks 全文检索上架图书
+//
+//	// 检索引擎只返回命中的图书 ID, 图书详情回表查询, 保证价格、库存等字段为最新值
+//	// 索引同步存在延迟时可能命中已下架或已删除的图书, 回表后过滤
+func (b *BookServiceImpl) SearchBooks(ctx context.Context, dto *request.BookSearchDTO) (*response.BookSearchVO, error) {
+	categories, err := b.categoryDao.GetAllCategories(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	q := &search.Query{
+		Keyword:   dto.Keyword,
+		Page:      dto.Page,
+		PageSize:  dto.PageSize,
+		Publisher: dto.Publisher,
+		Language:  dto.Language,
+		PriceBand: dto.PriceBand,
+	}
+	if dto.CategoryID != 0 {
+		q.CategoryIDs = newCategoryTree(categories).descendantIDs(dto.CategoryID)
+	}
+	res, err := b.searchEngine.Search(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+	// 有结果的搜索词计入热门搜索, 翻页不重复计数
+	if res.Total > 0 && q.Page <= 1 {
+		if err := b.suggestService.RecordQuery(ctx, dto.Keyword); err != nil {
+			logger.Log.Warn("SearchBooks: 记录搜索词失败", zap.String("keyword", dto.Keyword), zap.Error(err))
+		}
+	}
+
+	ids := make([]uint64, 0, len(res.Hits))
+	for _, hit := range res.Hits {
+		ids = append(ids, hit.BookID)
+	}
+	books, err := b.bookDao.GetBooksByIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[uint64]*model.Book, len(books))
+	for _, book := range books {
+		byID[book.ID] = book
+	}
+
+	// 按命中顺序 (相关度) 输出
+	vo := &response.BookSearchVO{
+		BooksPageVO: response.BooksPageVO{
+			Books:    make([]*model.Book, 0, len(res.Hits)),
+			Total:    res.Total,
+			Page:     q.Page,
+			PageSize: q.PageSize,
+		},
+		Highlights: make(map[uint64]map[string][]string, len(res.Hits)),
+		Facets:     res.Facets,
+	}
+	if q.PageSize > 0 {
+		vo.TotalPage = (res.Total + int64(q.PageSize) - 1) / int64(q.PageSize)
+	}
+	for _, hit := range res.Hits {
+		book, ok := byID[hit.BookID]
+		if !ok || book.Status != 1 {
+			continue
+		}
+		vo.Books = append(vo.Books, book)
+		if len(hit.Highlights) > 0 {
+			vo.Highlights[book.ID] = hit.Highlights
+		}
+	}
+
+	// 分类分面补充分类名称
+	names := make(map[string]string, len(categories))
+	for _, category := range categories {
+		names[strconv.FormatUint(category.ID, 10)] = category.Name
+	}
+	for _, count := range vo.Facets.Category {
+		count.Label = names[count.Value]
+	}
+	return vo, nil
+}
+
+// RebuildSearchIndex 从数据库全量重建检索索引
+//
+//	// 遍历全部图书, 上架图书写入索引, 下架图书从索引中删除
+func (b *BookServiceImpl) RebuildSearchIndex(ctx context.Context) (int, error) {
+	filter := &repository.BookFilter{}
+	total := 0
+	for page := 1; ; page++ {
+		pageResult, err := b.bookDao.GetBooksByPage(ctx, filter, page, reindexBatchSize)
+		if err != nil {
+			return total, err
+		}
+		if err := b.searchEngine.Index(ctx, pageResult.Records...); err != nil {
+			return total, err
+		}
+		total += len(pageResult.Records)
+		if len(pageResult.Records) < reindexBatchSize {
+			return total, nil
+		}
+	}
+}
+
+// BackfillSearchKeys 为检索键缺失或过期的图书重新生成检索键
+//
+//	// 用于上线拼音/繁简检索前的存量数据, 以及检索键生成规则变化后的批量刷新
+//	// 更新后的图书同步写入检索索引, 无需再单独重建
+func (b *BookServiceImpl) BackfillSearchKeys(ctx context.Context) (int, error) {
+	filter := &repository.BookFilter{}
+	updated := 0
+	for page := 1; ; page++ {
+		pageResult, err := b.bookDao.GetBooksByPage(ctx, filter, page, reindexBatchSize)
+		if err != nil {
+			return updated, err
+		}
+		changed := make([]*model.Book, 0, len(pageResult.Records))
+		for _, book := range pageResult.Records {
+			keys := book.BuildSearchKeys()
+			if keys == book.SearchKeys {
+				continue
+			}
+			if err := b.bookDao.UpdateSearchKeys(ctx, book.ID, keys); err != nil {
+				return updated, err
+			}
+			book.SearchKeys = keys
+			changed = append(changed, book)
+		}
+		if len(changed) > 0 {
+			if err := b.searchEngine.Index(ctx, changed...); err != nil {
+				return updated, err
+			}
+		}
+		updated += len(changed)
+		if len(pageResult.Records) < reindexBatchSize {
+			return updated, nil
+		}
+	}
+}
+
+// syncSearchIndex 图书写操作提交后同步检索索引, 失败时只记录日志, 可通过重建索引修复
+func (b *BookServiceImpl) syncSearchIndex(ctx context.Context, book *model.Book) {
+	if err := b.searchEngine.Index(ctx, book); err != nil {
+		logger.Log.Warn("syncSearchIndex: 同步检索索引失败", zap.Uint64("bookID", book.ID), zap.Error(err))
+	}
+}
+
+// removeSearchIndex 图书删除后从检索索引中移除
+func (b *BookServiceImpl) removeSearchIndex(ctx context.Context, id uint64) {
+	if err := b.searchEngine.Delete(ctx, id); err != nil {
+		logger.Log.Warn("removeSearchIndex: 删除检索索引失败", zap.Uint64("bookID", id), zap.Error(err))
+	}
+}
+
+// syncSuggestIndex 图书写操作提交后增量更新联想索引, 失败时只记录日志, 可通过重建索引修复
+func (b *BookServiceImpl) syncSuggestIndex(ctx context.Context, before, after *model.Book) {
+	if err := b.suggestService.IndexBook(ctx, before, after); err != nil {
+		var id uint64
+		if after != nil {
+			id = after.ID
+		} else if before != nil {
+			id = before.ID
+		}
+		logger.Log.Warn("syncSuggestIndex: 更新联想索引失败", zap.Uint64("bookID", id), zap.Error(err))
+	}
+}
@@ -0,0 +1,159 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/wangn-tech/bookstore-go/internal/api/request"
+	"github.com/wangn-tech/bookstore-go/internal/model"
+	"github.com/wangn-tech/bookstore-go/internal/repository"
+	"gorm.io/gorm"
+)
+
+// ICarouselService 轮播图服务接口
+type ICarouselService interface {
+	// GetActiveCarousels 获取当前应展示的轮播图
+	GetActiveCarousels(ctx context.Context) ([]*model.Carousel, error)
+
+	// 管理员接口
+	GetAllCarousels(ctx context.Context) ([]*model.Carousel, error)
+	GetCarouselByID(ctx context.Context, id uint64) (*model.Carousel, error)
+	CreateCarousel(ctx context.Context, dto *request.CarouselDTO) (*model.Carousel, error)
+	UpdateCarousel(ctx context.Context, id uint64, dto *request.CarouselDTO) (*model.Carousel, error)
+	DeleteCarousel(ctx context.Context, id uint64) error
+	ReorderCarousels(ctx context.Context, ids []uint64) ([]*model.Carousel, error)
+}
+
+var (
+	// ErrCarouselNotFound 轮播图不存在
+	ErrCarouselNotFound = errors.New("轮播图不存在")
+	// ErrInvalidCarouselWindow 展示时间窗口不合法
+	ErrInvalidCarouselWindow = errors.New("展示结束时间必须晚于开始时间")
+	// ErrInvalidCarouselOrder 排序参数不合法
+	ErrInvalidCarouselOrder = errors.New("排序参数不合法")
+)
+
+type CarouselServiceImpl struct {
+	carouselDao *repository.CarouselDao
+}
+
+func NewCarouselService(carouselDao *repository.CarouselDao) ICarouselService {
+	return &CarouselServiceImpl{
+		carouselDao: carouselDao,
+	}
+}
+
+// GetActiveCarousels 获取当前应展示的轮播图
+func (c *CarouselServiceImpl) GetActiveCarousels(ctx context.Context) ([]*model.Carousel, error) {
+	return c.carouselDao.GetActiveCarousels(ctx, time.Now())
+}
+
+// GetAllCarousels 获取全部轮播图, 包括未激活和不在展示时间内的
+func (c *CarouselServiceImpl) GetAllCarousels(ctx context.Context) ([]*model.Carousel, error) {
+	return c.carouselDao.GetAllCarousels(ctx)
+}
+
+// GetCarouselByID 根据 ID 获取轮播图
+func (c *CarouselServiceImpl) GetCarouselByID(ctx context.Context, id uint64) (*model.Carousel, error) {
+	carousel, err := c.carouselDao.GetCarouselByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrCarouselNotFound
+		}
+		return nil, err
+	}
+	return carousel, nil
+}
+
+// CreateCarousel 创建轮播图
+func (c *CarouselServiceImpl) CreateCarousel(ctx context.Context, dto *request.CarouselDTO) (*model.Carousel, error) {
+	carousel := &model.Carousel{
+		IsActive: true, // 默认激活
+	}
+	if err := applyCarouselDTO(carousel, dto); err != nil {
+		return nil, err
+	}
+	if err := c.carouselDao.CreateCarousel(ctx, carousel); err != nil {
+		return nil, err
+	}
+	return carousel, nil
+}
+
+// UpdateCarousel 更新轮播图
+func (c *CarouselServiceImpl) UpdateCarousel(ctx context.Context, id uint64, dto *request.CarouselDTO) (*model.Carousel, error) {
+	carousel, err := c.GetCarouselByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := applyCarouselDTO(carousel, dto); err != nil {
+		return nil, err
+	}
+	if err := c.carouselDao.UpdateCarousel(ctx, carousel); err != nil {
+		return nil, err
+	}
+	return carousel, nil
+}
+
+// DeleteCarousel 删除轮播图
+func (c *CarouselServiceImpl) DeleteCarousel(ctx context.Context, id uint64) error {
+	if err := c.carouselDao.DeleteCarousel(ctx, id); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrCarouselNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+// ReorderCarousels 按 ids 的顺序重排轮播图, 未列出的轮播图保持原有相对顺序排在其后
+func (c *CarouselServiceImpl) ReorderCarousels(ctx context.Context, ids []uint64) ([]*model.Carousel, error) {
+	carousels, err := c.carouselDao.GetAllCarousels(ctx)
+	if err != nil {
+		return nil, err
+	}
+	exists := make(map[uint64]bool, len(carousels))
+	for _, carousel := range carousels {
+		exists[carousel.ID] = true
+	}
+
+	sortOrders := make(map[uint64]int, len(carousels))
+	for _, id := range ids {
+		if !exists[id] {
+			return nil, fmt.Errorf("%w: 轮播图 %d 不存在", ErrInvalidCarouselOrder, id)
+		}
+		if _, ok := sortOrders[id]; ok {
+			return nil, fmt.Errorf("%w: 轮播图 %d 重复", ErrInvalidCarouselOrder, id)
+		}
+		sortOrders[id] = len(sortOrders) + 1
+	}
+	for _, carousel := range carousels {
+		if _, ok := sortOrders[carousel.ID]; !ok {
+			sortOrders[carousel.ID] = len(sortOrders) + 1
+		}
+	}
+
+	if err := c.carouselDao.UpdateSortOrders(ctx, sortOrders); err != nil {
+		return nil, err
+	}
+	return c.carouselDao.GetAllCarousels(ctx)
+}
+
+// applyCarouselDTO 将管理员请求参数写入轮播图模型
+func applyCarouselDTO(carousel *model.Carousel, dto *request.CarouselDTO) error {
+	if dto.StartTime != nil && dto.EndTime != nil && !dto.EndTime.After(*dto.StartTime) {
+		return ErrInvalidCarouselWindow
+	}
+	carousel.Title = dto.Title
+	carousel.Description = dto.Description
+	carousel.ImageURL = dto.ImageURL
+	carousel.LinkURL = dto.LinkURL
+	carousel.SortOrder = dto.SortOrder
+	if dto.IsActive != nil {
+		carousel.IsActive = *dto.IsActive
+	}
+	carousel.StartTime = dto.StartTime
+	carousel.EndTime = dto.EndTime
+	return nil
+}
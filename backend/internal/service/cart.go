@@ -0,0 +1,325 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/wangn-tech/bookstore-go/internal/api/request"
+	"github.com/wangn-tech/bookstore-go/internal/api/response"
+	"github.com/wangn-tech/bookstore-go/internal/app/initializer/redis"
+	"github.com/wangn-tech/bookstore-go/internal/model"
+	"github.com/wangn-tech/bookstore-go/internal/repository"
+	"github.com/wangn-tech/bookstore-go/pkg/logger"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// ICartService 购物车服务接口
+type ICartService interface {
+	// GetCart 获取购物车, 附带图书当前价格和可购买状态
+	GetCart(ctx context.Context, userID uint64) (*response.CartVO, error)
+	// AddItem 加入购物车, 已存在时累加数量, 累加后不能超过单个商品数量上限
+	AddItem(ctx context.Context, userID uint64, bookID uint64, quantity int) error
+	// UpdateItem 修改购物车商品数量
+	UpdateItem(ctx context.Context, userID uint64, bookID uint64, quantity int) error
+	// RemoveItem 从购物车移除商品
+	RemoveItem(ctx context.Context, userID uint64, bookID uint64) error
+	// ClearCart 清空购物车
+	ClearCart(ctx context.Context, userID uint64) error
+	// Checkout 将购物车中的商品下单, bookIDs 为空时结算整个购物车
+	Checkout(ctx context.Context, userID uint64, bookIDs []uint64) (*model.Order, error)
+}
+
+var (
+	// ErrCartItemNotFound 购物车中没有该商品
+	ErrCartItemNotFound = errors.New("购物车中没有该商品")
+	// ErrCartEmpty 购物车为空
+	ErrCartEmpty = errors.New("购物车为空")
+	// ErrCartFull 购物车商品种类已达上限
+	ErrCartFull = errors.New("购物车商品数量已达上限")
+	// ErrCartQuantityExceeded 单个商品的数量超过上限
+	ErrCartQuantityExceeded = errors.New("单个商品数量不能超过 99")
+)
+
+const (
+	// Redis 键前缀, cart:{userID} hash: bookID → 数量
+	cartRedisKeyPrefix = "cart:"
+	// 购物车缓存有效期, 每次读写时续期; 过期后从 MySQL 重新加载
+	cartTTL = 7 * 24 * time.Hour
+	// 购物车最多容纳的图书种类
+	cartMaxItems = 100
+	// 单个商品的最大数量, 与 request.UpdateCartItemDTO 的校验一致
+	cartMaxQuantity = 99
+)
+
+// CartServiceImpl 购物车服务实现
+//
+//	// Redis 保存购物车供快速读写, MySQL (cart_items) 持久化; 写操作先写 MySQL 再同步 Redis
+type CartServiceImpl struct {
+	cartDao      *repository.CartDao
+	bookDao      *repository.BookDao
+	orderService IOrderService
+}
+
+func NewCartService(cartDao *repository.CartDao, bookDao *repository.BookDao, orderService IOrderService) ICartService {
+	return &CartServiceImpl{
+		cartDao:      cartDao,
+		bookDao:      bookDao,
+		orderService: orderService,
+	}
+}
+
+func cartKey(userID uint64) string {
+	return fmt.Sprintf("%s%d", cartRedisKeyPrefix, userID)
+}
+
+// loadCart 读取购物车 bookID → 数量, 优先读 Redis, 未命中时从 MySQL 加载并回填
+func (c *CartServiceImpl) loadCart(ctx context.Context, userID uint64) (map[uint64]int, error) {
+	key := cartKey(userID)
+	fields, err := redis.RedisClient.HGetAll(ctx, key).Result()
+	if err != nil {
+		logger.Log.Warn("loadCart: 读取购物车缓存失败, 改为读取数据库", zap.Uint64("userID", userID), zap.Error(err))
+	}
+	if err == nil && len(fields) > 0 {
+		cart := make(map[uint64]int, len(fields))
+		for field, value := range fields {
+			bookID, err1 := strconv.ParseUint(field, 10, 64)
+			quantity, err2 := strconv.Atoi(value)
+			if err1 != nil || err2 != nil {
+				continue
+			}
+			cart[bookID] = quantity
+		}
+		redis.RedisClient.Expire(ctx, key, cartTTL)
+		return cart, nil
+	}
+
+	items, err := c.cartDao.GetCartItems(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	cart := make(map[uint64]int, len(items))
+	values := make(map[string]any, len(items))
+	for _, item := range items {
+		cart[item.BookID] = item.Quantity
+		values[strconv.FormatUint(item.BookID, 10)] = item.Quantity
+	}
+	if len(values) > 0 {
+		pipe := redis.RedisClient.TxPipeline()
+		pipe.HSet(ctx, key, values)
+		pipe.Expire(ctx, key, cartTTL)
+		if _, err := pipe.Exec(ctx); err != nil {
+			logger.Log.Warn("loadCart: 回填购物车缓存失败", zap.Uint64("userID", userID), zap.Error(err))
+		}
+	}
+	return cart, nil
+}
+
+// cacheSetItem 同步购物车商品数量到 Redis, 失败时删除缓存, 下次读取从 MySQL 重新加载
+func (c *CartServiceImpl) cacheSetItem(ctx context.Context, userID uint64, bookID uint64, quantity int) {
+	key := cartKey(userID)
+	pipe := redis.RedisClient.TxPipeline()
+	pipe.HSet(ctx, key, strconv.FormatUint(bookID, 10), quantity)
+	pipe.Expire(ctx, key, cartTTL)
+	if _, err := pipe.Exec(ctx); err != nil {
+		c.invalidateCache(ctx, userID, err)
+	}
+}
+
+// cacheRemoveItems 从 Redis 购物车移除商品
+func (c *CartServiceImpl) cacheRemoveItems(ctx context.Context, userID uint64, bookIDs ...uint64) {
+	fields := make([]string, 0, len(bookIDs))
+	for _, bookID := range bookIDs {
+		fields = append(fields, strconv.FormatUint(bookID, 10))
+	}
+	if err := redis.RedisClient.HDel(ctx, cartKey(userID), fields...).Err(); err != nil {
+		c.invalidateCache(ctx, userID, err)
+	}
+}
+
+// invalidateCache 删除购物车缓存
+func (c *CartServiceImpl) invalidateCache(ctx context.Context, userID uint64, cause error) {
+	logger.Log.Warn("Cart: 同步购物车缓存失败", zap.Uint64("userID", userID), zap.Error(cause))
+	if err := redis.RedisClient.Del(ctx, cartKey(userID)).Err(); err != nil {
+		logger.Log.Error("Cart: 删除购物车缓存失败", zap.Uint64("userID", userID), zap.Error(err))
+	}
+}
+
+// checkPurchasable 校验图书可加入购物车: 存在、已上架且可售库存不少于 quantity
+func (c *CartServiceImpl) checkPurchasable(ctx context.Context, bookID uint64, quantity int) error {
+	book, err := c.bookDao.GetBookByIDForAdmin(ctx, bookID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrBookNotFound
+		}
+		return err
+	}
+	if book.Status != 1 {
+		return ErrBookOffShelf
+	}
+	if book.AvailableStock < quantity {
+		return fmt.Errorf("%w: 《%s》当前可购买 %d 本", ErrStockNotEnough, book.Title, book.AvailableStock)
+	}
+	return nil
+}
+
+// GetCart 获取购物车
+func (c *CartServiceImpl) GetCart(ctx context.Context, userID uint64) (*response.CartVO, error) {
+	cart, err := c.loadCart(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	bookIDs := make([]uint64, 0, len(cart))
+	for bookID := range cart {
+		bookIDs = append(bookIDs, bookID)
+	}
+	slices.Sort(bookIDs)
+
+	books, err := c.bookDao.GetBooksByIDs(ctx, bookIDs)
+	if err != nil {
+		return nil, err
+	}
+	bookMap := make(map[uint64]*model.Book, len(books))
+	for _, book := range books {
+		bookMap[book.ID] = book
+	}
+
+	vo := &response.CartVO{Items: make([]*response.CartItemVO, 0, len(bookIDs))}
+	for _, bookID := range bookIDs {
+		book, ok := bookMap[bookID]
+		// 图书已被删除, 不再展示
+		if !ok {
+			continue
+		}
+		quantity := cart[bookID]
+		item := &response.CartItemVO{
+			BookID:    bookID,
+			Quantity:  quantity,
+			Price:     book.SalePriceInCents(),
+			Subtotal:  book.SalePriceInCents() * quantity,
+			Available: book.Status == 1 && book.AvailableStock >= quantity,
+			Book:      book,
+		}
+		if item.Available {
+			vo.TotalQuantity += item.Quantity
+			vo.TotalAmount += item.Subtotal
+		}
+		vo.Items = append(vo.Items, item)
+	}
+	return vo, nil
+}
+
+// AddItem 加入购物车
+func (c *CartServiceImpl) AddItem(ctx context.Context, userID uint64, bookID uint64, quantity int) error {
+	cart, err := c.loadCart(ctx, userID)
+	if err != nil {
+		return err
+	}
+	current, exists := cart[bookID]
+	if !exists && len(cart) >= cartMaxItems {
+		return ErrCartFull
+	}
+	// 累加后的数量同样受单个商品数量上限约束
+	if current+quantity > cartMaxQuantity {
+		return fmt.Errorf("%w, 购物车中已有 %d 件", ErrCartQuantityExceeded, current)
+	}
+	if err := c.checkPurchasable(ctx, bookID, current+quantity); err != nil {
+		return err
+	}
+	if err := c.cartDao.SaveCartItem(ctx, userID, bookID, current+quantity); err != nil {
+		return err
+	}
+	c.cacheSetItem(ctx, userID, bookID, current+quantity)
+	return nil
+}
+
+// UpdateItem 修改购物车商品数量
+func (c *CartServiceImpl) UpdateItem(ctx context.Context, userID uint64, bookID uint64, quantity int) error {
+	cart, err := c.loadCart(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if _, exists := cart[bookID]; !exists {
+		return ErrCartItemNotFound
+	}
+	if err := c.checkPurchasable(ctx, bookID, quantity); err != nil {
+		return err
+	}
+	if err := c.cartDao.SaveCartItem(ctx, userID, bookID, quantity); err != nil {
+		return err
+	}
+	c.cacheSetItem(ctx, userID, bookID, quantity)
+	return nil
+}
+
+// RemoveItem 从购物车移除商品
+func (c *CartServiceImpl) RemoveItem(ctx context.Context, userID uint64, bookID uint64) error {
+	removed, err := c.cartDao.DeleteCartItems(ctx, userID, bookID)
+	if err != nil {
+		return err
+	}
+	c.cacheRemoveItems(ctx, userID, bookID)
+	if removed == 0 {
+		return ErrCartItemNotFound
+	}
+	return nil
+}
+
+// ClearCart 清空购物车
+func (c *CartServiceImpl) ClearCart(ctx context.Context, userID uint64) error {
+	if err := c.cartDao.ClearCart(ctx, userID); err != nil {
+		return err
+	}
+	if err := redis.RedisClient.Del(ctx, cartKey(userID)).Err(); err != nil {
+		logger.Log.Error("ClearCart: 删除购物车缓存失败", zap.Uint64("userID", userID), zap.Error(err))
+	}
+	return nil
+}
+
+// Checkout 购物车结算, 下单成功后从购物车移除已结算的商品
+//
+//	// 价格、上架状态和库存由 CreateOrder 在服务端重新校验
+func (c *CartServiceImpl) Checkout(ctx context.Context, userID uint64, bookIDs []uint64) (*model.Order, error) {
+	cart, err := c.loadCart(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if len(bookIDs) == 0 {
+		for bookID := range cart {
+			bookIDs = append(bookIDs, bookID)
+		}
+	}
+	if len(bookIDs) == 0 {
+		return nil, ErrCartEmpty
+	}
+	slices.Sort(bookIDs)
+	bookIDs = slices.Compact(bookIDs)
+
+	dto := &request.CreateOrderDTO{UserID: userID}
+	for _, bookID := range bookIDs {
+		quantity, exists := cart[bookID]
+		if !exists {
+			return nil, fmt.Errorf("%w: 图书 %d", ErrCartItemNotFound, bookID)
+		}
+		dto.Items = append(dto.Items, request.CreateOrderItemDTO{
+			BookID:   bookID,
+			Quantity: quantity,
+		})
+	}
+
+	order, err := c.orderService.CreateOrder(ctx, dto)
+	if err != nil {
+		return nil, err
+	}
+
+	// 订单已创建, 清理购物车失败只记录日志
+	if _, err := c.cartDao.DeleteCartItems(ctx, userID, bookIDs...); err != nil {
+		logger.Log.Error("Checkout: 移除已结算的购物车商品失败", zap.Uint64("userID", userID), zap.Uint64("orderID", order.ID), zap.Error(err))
+	}
+	c.cacheRemoveItems(ctx, userID, bookIDs...)
+	return order, nil
+}
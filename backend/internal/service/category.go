@@ -2,9 +2,13 @@ package service
 
 import (
 	"context"
+	"errors"
 
+	"github.com/wangn-tech/bookstore-go/internal/api/response"
+	"github.com/wangn-tech/bookstore-go/internal/app/initializer/database"
 	"github.com/wangn-tech/bookstore-go/internal/model"
 	"github.com/wangn-tech/bookstore-go/internal/repository"
+	"gorm.io/gorm"
 )
 
 type ICategoryService interface {
@@ -13,8 +17,28 @@ type ICategoryService interface {
 	CreateCategory(ctx context.Context, category *model.Category) error
 	UpdateCategory(ctx context.Context, category *model.Category) error
 	DeleteCategory(ctx context.Context, id uint64) error
+
+	// GetCategoryTree 获取完整分类树, 每个节点附带面包屑
+	GetCategoryTree(ctx context.Context) ([]*response.CategoryNodeVO, error)
+	// GetBreadcrumbs 获取从顶级分类到指定分类的路径
+	GetBreadcrumbs(ctx context.Context, id uint64) ([]response.CategoryCrumb, error)
+	// MoveCategory 修改父分类, parentID 为 nil 表示移动为顶级分类
+	MoveCategory(ctx context.Context, id uint64, parentID *uint64) (*model.Category, error)
+	// ReconcileBookCounts 根据 books 重新统计各分类的上架图书数量, 报告并修正偏差
+	ReconcileBookCounts(ctx context.Context, dryRun bool) (*response.CategoryCountReconcileVO, error)
 }
 
+var (
+	// ErrCategoryNotFound 分类不存在
+	ErrCategoryNotFound = errors.New("分类不存在")
+	// ErrParentCategoryNotFound 父分类不存在
+	ErrParentCategoryNotFound = errors.New("父分类不存在")
+	// ErrCategoryCycle 不能将分类移动到自身或其子分类下
+	ErrCategoryCycle = errors.New("不能将分类移动到自身或其子分类下")
+	// ErrCategoryHasChildren 分类下存在子分类, 不能删除
+	ErrCategoryHasChildren = errors.New("分类下存在子分类，请先移动或删除子分类")
+)
+
 type CategoryServiceImpl struct {
 	// Define fields, e.g., repository interfaces
 	categoryDao *repository.CategoryDao
@@ -34,20 +58,151 @@ func (c *CategoryServiceImpl) GetAllCategories(ctx context.Context) ([]*model.Ca
 
 // GetCategoryByID 根据 ID 获取分类详情
 func (c *CategoryServiceImpl) GetCategoryByID(ctx context.Context, id uint64) (*model.Category, error) {
-	return c.categoryDao.GetCategoryByID(ctx, id)
+	category, err := c.categoryDao.GetCategoryByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrCategoryNotFound
+		}
+		return nil, err
+	}
+	return category, nil
 }
 
-// CreateCategory 创建分类
+// CreateCategory 创建分类, 可指定父分类
 func (c *CategoryServiceImpl) CreateCategory(ctx context.Context, category *model.Category) error {
+	if category.ParentID != nil {
+		if _, err := c.categoryDao.GetCategoryByID(ctx, *category.ParentID); err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrParentCategoryNotFound
+			}
+			return err
+		}
+	}
+	// 图书数量由系统维护
+	category.BookCount = 0
 	return c.categoryDao.CreateCategory(ctx, category)
 }
 
-// UpdateCategory 更新分类
+// UpdateCategory 更新分类, 不修改父分类 (使用 MoveCategory)
 func (c *CategoryServiceImpl) UpdateCategory(ctx context.Context, category *model.Category) error {
+	if _, err := c.GetCategoryByID(ctx, category.ID); err != nil {
+		return err
+	}
 	return c.categoryDao.UpdateCategory(ctx, category)
 }
 
-// DeleteCategory 删除分类
+// DeleteCategory 删除分类, 存在子分类时拒绝删除
 func (c *CategoryServiceImpl) DeleteCategory(ctx context.Context, id uint64) error {
-	return c.categoryDao.DeleteCategory(ctx, id)
+	hasChildren, err := c.categoryDao.HasChildren(ctx, id)
+	if err != nil {
+		return err
+	}
+	if hasChildren {
+		return ErrCategoryHasChildren
+	}
+	if err := c.categoryDao.DeleteCategory(ctx, id); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrCategoryNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+// loadCategoryTree 加载全部分类并构建分类树
+func (c *CategoryServiceImpl) loadCategoryTree(ctx context.Context) (*categoryTree, error) {
+	categories, err := c.categoryDao.GetAllCategories(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return newCategoryTree(categories), nil
+}
+
+// GetCategoryTree 获取完整分类树
+func (c *CategoryServiceImpl) GetCategoryTree(ctx context.Context) ([]*response.CategoryNodeVO, error) {
+	tree, err := c.loadCategoryTree(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return tree.nodes(0, nil), nil
+}
+
+// GetBreadcrumbs 获取分类的面包屑
+func (c *CategoryServiceImpl) GetBreadcrumbs(ctx context.Context, id uint64) ([]response.CategoryCrumb, error) {
+	tree, err := c.loadCategoryTree(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if tree.byID[id] == nil {
+		return nil, ErrCategoryNotFound
+	}
+	return tree.breadcrumbs(id), nil
+}
+
+// MoveCategory 移动分类到新的父分类下
+//
+//	// 新父分类不能是分类自身或其子孙分类, 否则会形成环
+//	// 在事务中锁定全部分类后再检查和写入, 并发移动 (如 A 移到 B 下、B 移到 A 下) 串行执行, 后执行的一方能检查出环
+func (c *CategoryServiceImpl) MoveCategory(ctx context.Context, id uint64, parentID *uint64) (*model.Category, error) {
+	var category *model.Category
+	err := database.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		categories, err := c.categoryDao.GetAllCategoriesForUpdateTx(ctx, tx)
+		if err != nil {
+			return err
+		}
+		tree := newCategoryTree(categories)
+		category = tree.byID[id]
+		if category == nil {
+			return ErrCategoryNotFound
+		}
+		if parentID != nil {
+			if tree.byID[*parentID] == nil {
+				return ErrParentCategoryNotFound
+			}
+			for _, descendantID := range tree.descendantIDs(id) {
+				if descendantID == *parentID {
+					return ErrCategoryCycle
+				}
+			}
+		}
+		return c.categoryDao.UpdateParentTx(ctx, tx, id, parentID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	category.ParentID = parentID
+	return category, nil
+}
+
+// ReconcileBookCounts 根据 books 重新统计各分类的上架图书数量, 报告并修正偏差
+//
+//	// BookCount 由图书写操作在事务中维护, 此方法用于修复历史数据或手工改库造成的偏差
+func (c *CategoryServiceImpl) ReconcileBookCounts(ctx context.Context, dryRun bool) (*response.CategoryCountReconcileVO, error) {
+	counts, err := c.categoryDao.GetCategoryBookCounts(ctx)
+	if err != nil {
+		return nil, err
+	}
+	report := &response.CategoryCountReconcileVO{
+		DryRun:  dryRun,
+		Checked: len(counts),
+		Drifts:  make([]*response.CategoryCountDrift, 0),
+	}
+	for _, count := range counts {
+		if count.BookCount == count.Actual {
+			continue
+		}
+		report.Drifts = append(report.Drifts, &response.CategoryCountDrift{
+			ID:     count.ID,
+			Name:   count.Name,
+			Stored: count.BookCount,
+			Actual: count.Actual,
+		})
+		if dryRun {
+			continue
+		}
+		if err := c.categoryDao.RecountBookCount(ctx, count.ID); err != nil {
+			return nil, err
+		}
+	}
+	return report, nil
 }
@@ -0,0 +1,82 @@
+package service
+
+import (
+	"github.com/wangn-tech/bookstore-go/internal/api/response"
+	"github.com/wangn-tech/bookstore-go/internal/model"
+)
+
+// categoryTree 内存中的分类树, 由全部分类一次性构建
+//
+//	// 分类数量有限, 整表加载后在内存中计算子树和路径, 避免递归 SQL
+type categoryTree struct {
+	byID     map[uint64]*model.Category
+	children map[uint64][]*model.Category // 父分类 ID → 子分类, 顶级分类的父 ID 记为 0
+}
+
+func newCategoryTree(categories []*model.Category) *categoryTree {
+	t := &categoryTree{
+		byID:     make(map[uint64]*model.Category, len(categories)),
+		children: make(map[uint64][]*model.Category, len(categories)),
+	}
+	for _, category := range categories {
+		t.byID[category.ID] = category
+	}
+	for _, category := range categories {
+		parentID := uint64(0)
+		// 父分类不存在时视为顶级分类
+		if category.ParentID != nil && t.byID[*category.ParentID] != nil {
+			parentID = *category.ParentID
+		}
+		t.children[parentID] = append(t.children[parentID], category)
+	}
+	return t
+}
+
+// descendantIDs 返回分类自身及其全部子孙分类的 ID
+func (t *categoryTree) descendantIDs(id uint64) []uint64 {
+	ids := []uint64{id}
+	visited := map[uint64]bool{id: true}
+	for i := 0; i < len(ids); i++ {
+		for _, child := range t.children[ids[i]] {
+			if !visited[child.ID] {
+				visited[child.ID] = true
+				ids = append(ids, child.ID)
+			}
+		}
+	}
+	return ids
+}
+
+// breadcrumbs 返回从顶级分类到 id 的路径
+func (t *categoryTree) breadcrumbs(id uint64) []response.CategoryCrumb {
+	var path []response.CategoryCrumb
+	visited := map[uint64]bool{}
+	for category := t.byID[id]; category != nil && !visited[category.ID]; {
+		visited[category.ID] = true
+		path = append(path, response.CategoryCrumb{ID: category.ID, Name: category.Name})
+		if category.ParentID == nil {
+			break
+		}
+		category = t.byID[*category.ParentID]
+	}
+	// 反转为从顶级分类开始
+	for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+		path[i], path[j] = path[j], path[i]
+	}
+	return path
+}
+
+// nodes 构建 parentID 下的分类树节点
+func (t *categoryTree) nodes(parentID uint64, parentCrumbs []response.CategoryCrumb) []*response.CategoryNodeVO {
+	children := t.children[parentID]
+	nodes := make([]*response.CategoryNodeVO, 0, len(children))
+	for _, category := range children {
+		crumbs := append(append([]response.CategoryCrumb{}, parentCrumbs...), response.CategoryCrumb{ID: category.ID, Name: category.Name})
+		nodes = append(nodes, &response.CategoryNodeVO{
+			Category:    category,
+			Breadcrumbs: crumbs,
+			Children:    t.nodes(category.ID, crumbs),
+		})
+	}
+	return nodes
+}
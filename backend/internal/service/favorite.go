@@ -0,0 +1,122 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"github.com/wangn-tech/bookstore-go/common/result"
+	"github.com/wangn-tech/bookstore-go/internal/api/request"
+	"github.com/wangn-tech/bookstore-go/internal/api/response"
+	"github.com/wangn-tech/bookstore-go/internal/model"
+	"github.com/wangn-tech/bookstore-go/internal/repository"
+	"gorm.io/gorm"
+)
+
+// IFavoriteService 收藏服务接口
+type IFavoriteService interface {
+	AddFavorite(ctx context.Context, userID uint64, bookID uint64) error
+	RemoveFavorite(ctx context.Context, userID uint64, bookID uint64) error
+	GetUserFavorites(ctx context.Context, userID uint64, dto *request.FavoritesPageDTO) (*result.PageResult[*model.Favorite], error)
+	GetFavoriteStatus(ctx context.Context, userID uint64, bookID uint64) (*response.FavoriteStatusVO, error)
+	// FillFavoriteInfo 为图书填充收藏数和当前用户的收藏状态, userID 为 0 表示未登录
+	FillFavoriteInfo(ctx context.Context, userID uint64, books ...*model.Book) error
+}
+
+// ErrFavoriteNotFound 未收藏该图书
+var ErrFavoriteNotFound = errors.New("未收藏该图书")
+
+type FavoriteServiceImpl struct {
+	favoriteDao *repository.FavoriteDao
+	bookDao     *repository.BookDao
+}
+
+func NewFavoriteService(favoriteDao *repository.FavoriteDao, bookDao *repository.BookDao) IFavoriteService {
+	return &FavoriteServiceImpl{
+		favoriteDao: favoriteDao,
+		bookDao:     bookDao,
+	}
+}
+
+// AddFavorite 收藏图书, 重复收藏视为成功
+func (f *FavoriteServiceImpl) AddFavorite(ctx context.Context, userID uint64, bookID uint64) error {
+	if _, err := f.bookDao.GetBookByID(ctx, bookID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrBookNotFound
+		}
+		return err
+	}
+	return f.favoriteDao.AddFavorite(ctx, userID, bookID)
+}
+
+// RemoveFavorite 取消收藏
+func (f *FavoriteServiceImpl) RemoveFavorite(ctx context.Context, userID uint64, bookID uint64) error {
+	if err := f.favoriteDao.RemoveFavorite(ctx, userID, bookID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrFavoriteNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+// GetUserFavorites 分页获取用户的收藏列表
+func (f *FavoriteServiceImpl) GetUserFavorites(ctx context.Context, userID uint64, dto *request.FavoritesPageDTO) (*result.PageResult[*model.Favorite], error) {
+	pageResult, err := f.favoriteDao.GetUserFavoritesByPage(ctx, userID, dto.Page, dto.PageSize)
+	if err != nil {
+		return nil, err
+	}
+	books := make([]*model.Book, 0, len(pageResult.Records))
+	for _, favorite := range pageResult.Records {
+		if favorite.Book != nil {
+			books = append(books, favorite.Book)
+		}
+	}
+	if err := f.FillFavoriteInfo(ctx, userID, books...); err != nil {
+		return nil, err
+	}
+	return pageResult, nil
+}
+
+// GetFavoriteStatus 获取图书的收藏状态
+func (f *FavoriteServiceImpl) GetFavoriteStatus(ctx context.Context, userID uint64, bookID uint64) (*response.FavoriteStatusVO, error) {
+	favorited, err := f.favoriteDao.GetFavoritedBookIDs(ctx, userID, []uint64{bookID})
+	if err != nil {
+		return nil, err
+	}
+	counts, err := f.favoriteDao.CountByBookIDs(ctx, []uint64{bookID})
+	if err != nil {
+		return nil, err
+	}
+	return &response.FavoriteStatusVO{
+		BookID:        bookID,
+		IsFavorited:   favorited[bookID],
+		FavoriteCount: counts[bookID],
+	}, nil
+}
+
+// FillFavoriteInfo 批量填充收藏信息, 每次调用最多两条 SQL
+func (f *FavoriteServiceImpl) FillFavoriteInfo(ctx context.Context, userID uint64, books ...*model.Book) error {
+	if len(books) == 0 {
+		return nil
+	}
+	bookIDs := make([]uint64, 0, len(books))
+	for _, book := range books {
+		bookIDs = append(bookIDs, book.ID)
+	}
+
+	counts, err := f.favoriteDao.CountByBookIDs(ctx, bookIDs)
+	if err != nil {
+		return err
+	}
+	favorited := map[uint64]bool{}
+	if userID != 0 {
+		if favorited, err = f.favoriteDao.GetFavoritedBookIDs(ctx, userID, bookIDs); err != nil {
+			return err
+		}
+	}
+	for _, book := range books {
+		book.FavoriteCount = counts[book.ID]
+		book.IsFavorited = favorited[book.ID]
+	}
+	return nil
+}
@@ -1,9 +1,11 @@
 package service
 
 import (
+	"cmp"
 	"context"
 	"errors"
 	"fmt"
+	"slices"
 	"time"
 
 	"github.com/wangn-tech/bookstore-go/common/result"
@@ -16,21 +18,42 @@ import (
 
 type IOrderService interface {
 	CreateOrder(ctx context.Context, dto *request.CreateOrderDTO) (*model.Order, error)
-	GetOrderByID(ctx context.Context, id uint64) (*model.Order, error)
+	GetOrderByID(ctx context.Context, userID uint64, id uint64) (*model.Order, error)
 	GetUserOrders(ctx context.Context, userID uint64, dto *request.OrdersPageDTO) (*result.PageResult[*model.Order], error)
-	PayOrder(ctx context.Context, id uint64) error
+	PayOrder(ctx context.Context, userID uint64, id uint64) error
 	GetOrderStatistics(ctx context.Context, userID uint64) (map[string]any, error)
+	CancelOrder(ctx context.Context, userID uint64, id uint64, reason string) error
+	GetOrderStatusHistory(ctx context.Context, userID uint64, id uint64) ([]*model.OrderStatusHistory, error)
+
+	// 管理员接口, 不校验订单归属
+	GetOrderByIDForAdmin(ctx context.Context, id uint64) (*model.Order, error)
+	GetAllOrders(ctx context.Context, dto *request.OrdersPageDTO) (*result.PageResult[*model.Order], error)
+	TransitionOrderByAdmin(ctx context.Context, adminID uint64, id uint64, to int, remark string) error
+	GetOrderStatusHistoryForAdmin(ctx context.Context, id uint64) ([]*model.OrderStatusHistory, error)
+
+	// CancelExpiredOrders 取消超过 ttl 仍未支付的订单, 返回取消数量
+	CancelExpiredOrders(ctx context.Context, ttl time.Duration, limit int) (int, error)
 }
 
+// ErrOrderNotFound 订单不存在或不属于当前用户
+var ErrOrderNotFound = errors.New("订单不存在")
+
+// ErrPriceChanged 客户端价格与服务端计算的成交价不一致
+var ErrPriceChanged = errors.New("商品价格已变动，请刷新后重新下单")
+
 type OrderServiceImpl struct {
-	orderDao *repository.OrderDao
-	bookDao  *repository.BookDao
+	orderDao       *repository.OrderDao
+	bookDao        *repository.BookDao
+	rankingService IBookRankingService
+	suggestService ISuggestService
 }
 
-func NewOrderService(orderDao *repository.OrderDao, bookDao *repository.BookDao) IOrderService {
+func NewOrderService(orderDao *repository.OrderDao, bookDao *repository.BookDao, rankingService IBookRankingService, suggestService ISuggestService) IOrderService {
 	return &OrderServiceImpl{
-		orderDao: orderDao,
-		bookDao:  bookDao,
+		orderDao:       orderDao,
+		bookDao:        bookDao,
+		rankingService: rankingService,
+		suggestService: suggestService,
 	}
 }
 
@@ -39,34 +62,31 @@ func (o *OrderServiceImpl) GetOrderStatistics(ctx context.Context, userID uint64
 	return o.orderDao.GetOrderStatistics(ctx, userID)
 }
 
-// PayOrder 支付订单
-func (o *OrderServiceImpl) PayOrder(ctx context.Context, id uint64) error {
-	return database.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+// PayOrder 支付订单, 只能支付自己的待支付订单
+func (o *OrderServiceImpl) PayOrder(ctx context.Context, userID uint64, id uint64) error {
+	var order *model.Order
+	err := database.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
 		// 加锁读取订单及其明细，防并发
-		order, err := o.orderDao.GetOrderWithItemsForUpdate(ctx, tx, id)
+		var err error
+		order, err = o.orderDao.GetOrderWithItemsForUpdate(ctx, tx, id, userID)
 		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrOrderNotFound
+			}
 			return err
 		}
 		if order.IsPaid {
 			return errors.New("订单已支付")
 		}
 
-		// 原子扣减每个商品库存并增加销量
-		for _, item := range order.OrderItems {
-			if err := o.bookDao.DecreaseStockAndIncreaseSaleTx(ctx, tx, item.BookID, item.Quantity); err != nil {
-				if errors.Is(err, gorm.ErrRecordNotFound) {
-					return errors.New("图书库存不足")
-				}
-				return err
-			}
-		}
-
-		// 标记订单支付完成（幂等）
-		if err := o.orderDao.MarkPaidTx(ctx, tx, id, time.Now()); err != nil {
-			return err
-		}
-		return nil
+		// 扣减库存、标记支付完成并记录状态变更
+		return o.changeStatusTx(ctx, tx, order, model.OrderStatusPaid, orderOperator{Type: model.OperatorUser, ID: userID}, "买家支付")
 	})
+	if err != nil {
+		return err
+	}
+	o.recordSales(ctx, order)
+	return nil
 }
 
 // GetUserOrders 获取用户的订单列表，支持分页
@@ -74,19 +94,48 @@ func (o *OrderServiceImpl) GetUserOrders(ctx context.Context, userID uint64, dto
 	return o.orderDao.GetUserOrdersByPage(ctx, userID, dto.Page, dto.PageSize)
 }
 
-// GetOrderByID 根据 ID 获取订单详情
-func (o *OrderServiceImpl) GetOrderByID(ctx context.Context, id uint64) (*model.Order, error) {
-	return o.orderDao.GetOrderByID(ctx, id)
+// GetOrderByID 根据 ID 获取用户的订单详情, 他人的订单返回 ErrOrderNotFound
+func (o *OrderServiceImpl) GetOrderByID(ctx context.Context, userID uint64, id uint64) (*model.Order, error) {
+	order, err := o.orderDao.GetUserOrderByID(ctx, id, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrOrderNotFound
+		}
+		return nil, err
+	}
+	return order, nil
+}
+
+// GetOrderByIDForAdmin 根据 ID 获取订单详情（管理员用）
+func (o *OrderServiceImpl) GetOrderByIDForAdmin(ctx context.Context, id uint64) (*model.Order, error) {
+	order, err := o.orderDao.GetOrderByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrOrderNotFound
+		}
+		return nil, err
+	}
+	return order, nil
+}
+
+// GetAllOrders 分页获取全部订单（管理员用）
+func (o *OrderServiceImpl) GetAllOrders(ctx context.Context, dto *request.OrdersPageDTO) (*result.PageResult[*model.Order], error) {
+	return o.orderDao.GetOrdersByPage(ctx, dto.Page, dto.PageSize)
 }
 
 // CreateOrder 创建订单
+//
+//	// 成交价由服务端根据图书当前 Price 和 Discount 计算, 不信任客户端传入的价格
 func (o *OrderServiceImpl) CreateOrder(ctx context.Context, dto *request.CreateOrderDTO) (*model.Order, error) {
 	if len(dto.Items) == 0 {
 		return nil, errors.New("订单项不能为空")
 	}
 
-	// 检查库存
-	err := o.checkStockAvailability(ctx, dto.Items)
+	// 合并同一图书的订单项, 避免重复条目绕过库存检查
+	items := mergeOrderItems(dto.Items)
+
+	// 加载图书并检查上架状态和库存
+	books, err := o.loadOrderBooks(ctx, items)
 	if err != nil {
 		return nil, err
 	}
@@ -95,15 +144,23 @@ func (o *OrderServiceImpl) CreateOrder(ctx context.Context, dto *request.CreateO
 	// 计算总金额
 	var totalAmount int               // 总金额
 	var orderItems []*model.OrderItem // 订单项列表
-	for _, item := range dto.Items {
-		subtotal := item.Price * item.Quantity
+	for _, item := range items {
+		book := books[item.BookID]
+		price := book.SalePriceInCents()
+		// 客户端传入的价格与服务端不一致 (价格已变动或被篡改), 拒绝下单
+		if item.ExpectedPriceCents != 0 && item.ExpectedPriceCents != price {
+			return nil, fmt.Errorf("%w: 《%s》当前单价为 %d 分", ErrPriceChanged, book.Title, price)
+		}
+		subtotal := price * item.Quantity
 		totalAmount += subtotal
 
 		orderItems = append(orderItems, &model.OrderItem{
-			BookID:   item.BookID,
-			Quantity: item.Quantity,
-			Price:    item.Price,
-			Subtotal: subtotal,
+			BookID:    item.BookID,
+			Quantity:  item.Quantity,
+			ListPrice: book.ListPriceInCents(),
+			Discount:  book.Discount,
+			Price:     price,
+			Subtotal:  subtotal,
 		})
 	}
 	// 创建订单
@@ -111,32 +168,73 @@ func (o *OrderServiceImpl) CreateOrder(ctx context.Context, dto *request.CreateO
 		OrderNo:     orderNo,
 		UserID:      dto.UserID,
 		TotalAmount: totalAmount,
-		Status:      0,     // 待支付
+		Status:      model.OrderStatusPending,
 		IsPaid:      false, // 未支付
 	}
-	// 使用事务创建订单和订单项
-	err = o.orderDao.CreateOrderWithItems(ctx, order, orderItems)
+	// 使用事务预占库存并创建订单和订单项
+	err = database.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// 按图书 ID 顺序预占, 避免并发下单时互相等待行锁造成死锁
+		reserveItems := slices.Clone(orderItems)
+		slices.SortFunc(reserveItems, func(a, b *model.OrderItem) int {
+			return cmp.Compare(a.BookID, b.BookID)
+		})
+		for _, item := range reserveItems {
+			if err := o.bookDao.ReserveStockTx(ctx, tx, item.BookID, item.Quantity); err != nil {
+				if errors.Is(err, gorm.ErrRecordNotFound) {
+					return fmt.Errorf("%w: 《%s》", ErrStockNotEnough, books[item.BookID].Title)
+				}
+				return err
+			}
+		}
+		return o.orderDao.CreateOrderWithItemsTx(ctx, tx, order, orderItems)
+	})
 	if err != nil {
 		return nil, err
 	}
+	// 响应中携带每个订单项的价格明细: 原价、折扣、成交价
+	for _, item := range orderItems {
+		order.OrderItems = append(order.OrderItems, *item)
+	}
 	return order, nil
 }
 
-// checkStockAvailability 检查订单项的库存是否充足
-func (o *OrderServiceImpl) checkStockAvailability(ctx context.Context, items []request.CreateOrderItemDTO) error {
+// mergeOrderItems 合并同一图书的订单项数量, 保持首次出现的顺序
+func mergeOrderItems(items []request.CreateOrderItemDTO) []request.CreateOrderItemDTO {
+	merged := make([]request.CreateOrderItemDTO, 0, len(items))
+	index := make(map[uint64]int, len(items))
+	for _, item := range items {
+		if i, ok := index[item.BookID]; ok {
+			merged[i].Quantity += item.Quantity
+			continue
+		}
+		index[item.BookID] = len(merged)
+		merged = append(merged, item)
+	}
+	return merged
+}
+
+// loadOrderBooks 加载订单项对应的图书, 检查上架状态和可售库存是否充足
+//
+//	// 此处仅提前拦截, 最终以事务内的库存预占结果为准
+func (o *OrderServiceImpl) loadOrderBooks(ctx context.Context, items []request.CreateOrderItemDTO) (map[uint64]*model.Book, error) {
+	books := make(map[uint64]*model.Book, len(items))
 	for _, item := range items {
 		book, err := o.bookDao.GetBookByIDForAdmin(ctx, item.BookID)
 		if err != nil {
-			return errors.New("图书不存在")
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, ErrBookNotFound
+			}
+			return nil, err
 		}
 		if book.Status != 1 {
-			return errors.New("图书已下架")
+			return nil, fmt.Errorf("%w: 《%s》", ErrBookOffShelf, book.Title)
 		}
-		if book.Stock < item.Quantity {
-			return errors.New("库存不足")
+		if book.AvailableStock < item.Quantity {
+			return nil, fmt.Errorf("%w: 《%s》", ErrStockNotEnough, book.Title)
 		}
+		books[item.BookID] = book
 	}
-	return nil
+	return books, nil
 }
 
 // generateOrderNo 生成唯一的订单号 (模拟实现)
@@ -0,0 +1,223 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/wangn-tech/bookstore-go/internal/app/initializer/database"
+	"github.com/wangn-tech/bookstore-go/internal/model"
+	"github.com/wangn-tech/bookstore-go/pkg/logger"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// ErrInvalidOrderTransition 订单当前状态不允许变更为目标状态
+var ErrInvalidOrderTransition = errors.New("订单状态不允许此操作")
+
+// orderTransitions 订单状态机: 当前状态 → 允许的目标状态
+var orderTransitions = map[int][]int{
+	model.OrderStatusPending:   {model.OrderStatusPaid, model.OrderStatusCancelled},
+	model.OrderStatusPaid:      {model.OrderStatusShipped, model.OrderStatusRefunded},
+	model.OrderStatusShipped:   {model.OrderStatusDelivered, model.OrderStatusRefunded},
+	model.OrderStatusDelivered: {model.OrderStatusCompleted, model.OrderStatusRefunded},
+	// 已取消、已完成、已退款为终态
+}
+
+// orderStatusText 订单状态描述
+var orderStatusText = map[int]string{
+	model.OrderStatusPending:   "待支付",
+	model.OrderStatusPaid:      "已支付",
+	model.OrderStatusCancelled: "已取消",
+	model.OrderStatusShipped:   "已发货",
+	model.OrderStatusDelivered: "已送达",
+	model.OrderStatusCompleted: "已完成",
+	model.OrderStatusRefunded:  "已退款",
+}
+
+// canTransitionOrder 校验订单状态变更是否合法
+func canTransitionOrder(from, to int) bool {
+	return slices.Contains(orderTransitions[from], to)
+}
+
+// orderOperator 订单状态变更操作人
+type orderOperator struct {
+	Type string // model.OperatorUser, model.OperatorAdmin, model.OperatorSystem
+	ID   uint64
+}
+
+// changeStatusTx 在事务中变更订单状态, 调用方需已对订单加锁 (GetOrderWithItemsForUpdate)
+//
+//	// 校验状态机 → 执行状态附带的库存变更 → 更新订单状态 → 记录状态变更
+func (o *OrderServiceImpl) changeStatusTx(ctx context.Context, tx *gorm.DB, order *model.Order, to int, operator orderOperator, remark string) error {
+	from := order.Status
+	if !canTransitionOrder(from, to) {
+		return fmt.Errorf("%w: %s → %s", ErrInvalidOrderTransition, orderStatusText[from], orderStatusText[to])
+	}
+
+	var extra map[string]any
+	switch to {
+	case model.OrderStatusPaid:
+		// 将下单时预占的库存转为销量
+		for _, item := range order.OrderItems {
+			if err := o.bookDao.ConsumeReservedStockTx(ctx, tx, item.BookID, item.Quantity); err != nil {
+				if errors.Is(err, gorm.ErrRecordNotFound) {
+					return errors.New("图书库存不足")
+				}
+				return err
+			}
+		}
+		now := time.Now()
+		order.IsPaid = true
+		order.PaymentTime = &now
+		extra = map[string]any{
+			"is_paid":      true,
+			"payment_time": now,
+		}
+	case model.OrderStatusCancelled:
+		// 只有待支付订单可以取消, 释放下单时预占的库存
+		for _, item := range order.OrderItems {
+			if err := o.bookDao.ReleaseStockTx(ctx, tx, item.BookID, item.Quantity); err != nil {
+				return err
+			}
+		}
+	case model.OrderStatusRefunded:
+		// 归还库存并扣减销量
+		for _, item := range order.OrderItems {
+			if err := o.bookDao.IncreaseStockAndDecreaseSaleTx(ctx, tx, item.BookID, item.Quantity); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := o.orderDao.UpdateStatusTx(ctx, tx, order.ID, from, to, extra); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrInvalidOrderTransition
+		}
+		return err
+	}
+	order.Status = to
+
+	return o.orderDao.CreateStatusHistoryTx(ctx, tx, &model.OrderStatusHistory{
+		OrderID:      order.ID,
+		FromStatus:   &from,
+		ToStatus:     to,
+		OperatorType: operator.Type,
+		OperatorID:   operator.ID,
+		Remark:       remark,
+	})
+}
+
+// CancelOrder 买家取消自己的待支付订单
+func (o *OrderServiceImpl) CancelOrder(ctx context.Context, userID uint64, id uint64, reason string) error {
+	return database.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		order, err := o.orderDao.GetOrderWithItemsForUpdate(ctx, tx, id, userID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrOrderNotFound
+			}
+			return err
+		}
+		if reason == "" {
+			reason = "买家取消"
+		}
+		return o.changeStatusTx(ctx, tx, order, model.OrderStatusCancelled, orderOperator{Type: model.OperatorUser, ID: userID}, reason)
+	})
+}
+
+// TransitionOrderByAdmin 管理员变更订单状态 (发货、送达、完成、退款、取消)
+func (o *OrderServiceImpl) TransitionOrderByAdmin(ctx context.Context, adminID uint64, id uint64, to int, remark string) error {
+	// 支付只能由买家发起
+	if to == model.OrderStatusPaid {
+		return ErrInvalidOrderTransition
+	}
+	var order *model.Order
+	err := database.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var err error
+		order, err = o.orderDao.GetOrderWithItemsForUpdateByAdmin(ctx, tx, id)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrOrderNotFound
+			}
+			return err
+		}
+		return o.changeStatusTx(ctx, tx, order, to, orderOperator{Type: model.OperatorAdmin, ID: adminID}, remark)
+	})
+	if err != nil {
+		return err
+	}
+	o.recordSales(ctx, order)
+	return nil
+}
+
+// recordSales 订单支付或退款提交后更新畅销榜和联想词权重, 失败只记录日志, 两者均可通过重建修正
+func (o *OrderServiceImpl) recordSales(ctx context.Context, order *model.Order) {
+	var rankingErr, suggestErr error
+	switch order.Status {
+	case model.OrderStatusPaid:
+		rankingErr = o.rankingService.RecordPaid(ctx, order)
+		suggestErr = o.suggestService.RecordPaid(ctx, order)
+	case model.OrderStatusRefunded:
+		rankingErr = o.rankingService.RecordRefunded(ctx, order)
+		suggestErr = o.suggestService.RecordRefunded(ctx, order)
+	default:
+		return
+	}
+	if rankingErr != nil {
+		logger.Log.Warn("recordSales: 更新畅销榜失败", zap.Uint64("orderID", order.ID), zap.Int("status", order.Status), zap.Error(rankingErr))
+	}
+	if suggestErr != nil {
+		logger.Log.Warn("recordSales: 更新联想词权重失败", zap.Uint64("orderID", order.ID), zap.Int("status", order.Status), zap.Error(suggestErr))
+	}
+}
+
+// GetOrderStatusHistory 获取用户订单的状态变更记录
+func (o *OrderServiceImpl) GetOrderStatusHistory(ctx context.Context, userID uint64, id uint64) ([]*model.OrderStatusHistory, error) {
+	if _, err := o.GetOrderByID(ctx, userID, id); err != nil {
+		return nil, err
+	}
+	return o.orderDao.GetStatusHistory(ctx, id)
+}
+
+// GetOrderStatusHistoryForAdmin 获取订单的状态变更记录（管理员用）
+func (o *OrderServiceImpl) GetOrderStatusHistoryForAdmin(ctx context.Context, id uint64) ([]*model.OrderStatusHistory, error) {
+	if _, err := o.GetOrderByIDForAdmin(ctx, id); err != nil {
+		return nil, err
+	}
+	return o.orderDao.GetStatusHistory(ctx, id)
+}
+
+// CancelExpiredOrders 取消超过 ttl 仍未支付的订单, 每个订单单独事务
+//
+//	// 订单逐个加锁 (SKIP LOCKED), 多副本同时执行或与买家支付并发时不会重复处理
+func (o *OrderServiceImpl) CancelExpiredOrders(ctx context.Context, ttl time.Duration, limit int) (int, error) {
+	before := time.Now().Add(-ttl)
+	ids, err := o.orderDao.GetExpiredPendingOrderIDs(ctx, before, limit)
+	if err != nil {
+		return 0, err
+	}
+
+	cancelled := 0
+	for _, id := range ids {
+		err := database.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			order, err := o.orderDao.GetExpiredPendingOrderForUpdate(ctx, tx, id, before)
+			if err != nil {
+				return err
+			}
+			return o.changeStatusTx(ctx, tx, order, model.OrderStatusCancelled, orderOperator{Type: model.OperatorSystem}, "超时未支付，系统自动取消")
+		})
+		if err != nil {
+			// 已被支付、取消或正被其他事务处理, 跳过
+			if errors.Is(err, gorm.ErrRecordNotFound) || errors.Is(err, ErrInvalidOrderTransition) {
+				continue
+			}
+			logger.Log.Error("CancelExpiredOrders: 自动取消订单失败", zap.Uint64("orderID", id), zap.Error(err))
+			continue
+		}
+		cancelled++
+		logger.Log.Info("CancelExpiredOrders: 订单超时未支付，已自动取消", zap.Uint64("orderID", id), zap.Duration("ttl", ttl))
+	}
+	return cancelled, nil
+}
@@ -0,0 +1,73 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/wangn-tech/bookstore-go/internal/model"
+)
+
+func TestCanTransitionOrder(t *testing.T) {
+	const (
+		pending   = model.OrderStatusPending
+		paid      = model.OrderStatusPaid
+		cancelled = model.OrderStatusCancelled
+		shipped   = model.OrderStatusShipped
+		delivered = model.OrderStatusDelivered
+		completed = model.OrderStatusCompleted
+		refunded  = model.OrderStatusRefunded
+	)
+	allowed := map[[2]int]bool{
+		{pending, paid}:        true,
+		{pending, cancelled}:   true,
+		{paid, shipped}:        true,
+		{paid, refunded}:       true,
+		{shipped, delivered}:   true,
+		{shipped, refunded}:    true,
+		{delivered, completed}: true,
+		{delivered, refunded}:  true,
+	}
+	statuses := []int{pending, paid, cancelled, shipped, delivered, completed, refunded}
+	// 逐一检查全部状态组合, 未列出的组合 (包括终态和原地变更) 均不允许
+	for _, from := range statuses {
+		for _, to := range statuses {
+			want := allowed[[2]int{from, to}]
+			if got := canTransitionOrder(from, to); got != want {
+				t.Errorf("canTransitionOrder(%s, %s) = %v, want %v", orderStatusText[from], orderStatusText[to], got, want)
+			}
+		}
+	}
+	for _, terminal := range []int{cancelled, completed, refunded} {
+		if len(orderTransitions[terminal]) != 0 {
+			t.Errorf("%s should be terminal, got transitions %v", orderStatusText[terminal], orderTransitions[terminal])
+		}
+	}
+	for _, status := range statuses {
+		if orderStatusText[status] == "" {
+			t.Errorf("status %d has no text", status)
+		}
+	}
+}
+
+func TestChangeStatusTxRejectsInvalidTransition(t *testing.T) {
+	tests := []struct {
+		from, to int
+	}{
+		{model.OrderStatusCancelled, model.OrderStatusPaid},
+		{model.OrderStatusRefunded, model.OrderStatusShipped},
+		{model.OrderStatusPending, model.OrderStatusShipped},
+		{model.OrderStatusPaid, model.OrderStatusCancelled},
+	}
+	for _, tt := range tests {
+		// 状态校验先于任何数据库操作, 非法变更不会访问事务和 DAO
+		order := &model.Order{Status: tt.from}
+		err := (&OrderServiceImpl{}).changeStatusTx(context.Background(), nil, order, tt.to, orderOperator{Type: model.OperatorAdmin, ID: 1}, "")
+		if !errors.Is(err, ErrInvalidOrderTransition) {
+			t.Errorf("changeStatusTx(%s → %s) error = %v, want ErrInvalidOrderTransition", orderStatusText[tt.from], orderStatusText[tt.to], err)
+		}
+		if order.Status != tt.from {
+			t.Errorf("changeStatusTx(%s → %s) changed status to %d", orderStatusText[tt.from], orderStatusText[tt.to], order.Status)
+		}
+	}
+}
@@ -0,0 +1,222 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/wangn-tech/bookstore-go/internal/app/initializer/database"
+	"github.com/wangn-tech/bookstore-go/internal/model"
+	"github.com/wangn-tech/bookstore-go/internal/repository"
+	"gorm.io/gorm"
+)
+
+// IRecommendService 图书推荐服务接口
+type IRecommendService interface {
+	// RebuildSimilarities 根据已支付订单全量重建图书共同购买相似度, 返回写入的行数
+	RebuildSimilarities(ctx context.Context) (int, error)
+	// GetRelatedBooks 买了这本书的人还买了, 相似数据不足时用同分类畅销书补足
+	GetRelatedBooks(ctx context.Context, bookID uint64, limit int) ([]*model.Book, error)
+	// GetRecommendations 根据用户的购买和收藏记录推荐图书, userID 为 0 或没有记录时返回各分类畅销书
+	GetRecommendations(ctx context.Context, userID uint64, limit int) ([]*model.Book, error)
+}
+
+const (
+	// similarityTopK 每本图书保留的相似图书数
+	similarityTopK = 20
+	// similarityMaxBasket 购买图书数超过该值的用户 (如批量采购) 不参与共同购买计数, 避免产生大量无意义的图书对
+	similarityMaxBasket = 200
+	// recommendHistorySize 个性化推荐时参考的最近购买和收藏数
+	recommendHistorySize = 50
+)
+
+type RecommendServiceImpl struct {
+	recommendDao *repository.RecommendDao
+	bookDao      *repository.BookDao
+	orderDao     *repository.OrderDao
+	favoriteDao  *repository.FavoriteDao
+}
+
+func NewRecommendService(recommendDao *repository.RecommendDao, bookDao *repository.BookDao, orderDao *repository.OrderDao, favoriteDao *repository.FavoriteDao) IRecommendService {
+	return &RecommendServiceImpl{
+		recommendDao: recommendDao,
+		bookDao:      bookDao,
+		orderDao:     orderDao,
+		favoriteDao:  favoriteDao,
+	}
+}
+
+// bookPair 无序图书对, a < b
+type bookPair struct {
+	a, b uint64
+}
+
+// RebuildSimilarities 全量重建共同购买相似度
+//
+//	// 相似度为余弦相似度: 同时购买两本书的人数 / sqrt(两本书各自的购买人数之积),
+//	// 只按购买人数计算, 同一用户多次购买同一本书计一次, 避免复购多的书相似度虚高
+func (r *RecommendServiceImpl) RebuildSimilarities(ctx context.Context) (int, error) {
+	buyers := make(map[uint64]int)
+	coCounts := make(map[bookPair]int)
+
+	var currentUser uint64
+	var basket []uint64
+	flush := func() {
+		if len(basket) > similarityMaxBasket {
+			basket = basket[:0]
+			return
+		}
+		for i := 0; i < len(basket); i++ {
+			for j := i + 1; j < len(basket); j++ {
+				// 购买记录按图书 ID 升序返回, basket[i] < basket[j]
+				coCounts[bookPair{a: basket[i], b: basket[j]}]++
+			}
+		}
+		basket = basket[:0]
+	}
+	err := r.recommendDao.ScanPurchases(ctx, func(userID uint64, bookID uint64) error {
+		if userID != currentUser {
+			flush()
+			currentUser = userID
+		}
+		buyers[bookID]++
+		basket = append(basket, bookID)
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	flush()
+
+	neighbors := make(map[uint64][]*model.BookSimilarity)
+	now := time.Now()
+	for pair, count := range coCounts {
+		score := float64(count) / math.Sqrt(float64(buyers[pair.a])*float64(buyers[pair.b]))
+		neighbors[pair.a] = append(neighbors[pair.a], &model.BookSimilarity{BookID: pair.a, SimilarBookID: pair.b, Score: score, CoCount: count, UpdatedAt: now})
+		neighbors[pair.b] = append(neighbors[pair.b], &model.BookSimilarity{BookID: pair.b, SimilarBookID: pair.a, Score: score, CoCount: count, UpdatedAt: now})
+	}
+	similarities := make([]*model.BookSimilarity, 0, len(neighbors)*similarityTopK)
+	for _, list := range neighbors {
+		sort.Slice(list, func(i, j int) bool {
+			if list[i].Score != list[j].Score {
+				return list[i].Score > list[j].Score
+			}
+			if list[i].CoCount != list[j].CoCount {
+				return list[i].CoCount > list[j].CoCount
+			}
+			return list[i].SimilarBookID < list[j].SimilarBookID
+		})
+		if len(list) > similarityTopK {
+			list = list[:similarityTopK]
+		}
+		similarities = append(similarities, list...)
+	}
+
+	err = database.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return r.recommendDao.ReplaceSimilaritiesTx(ctx, tx, similarities)
+	})
+	if err != nil {
+		return 0, err
+	}
+	return len(similarities), nil
+}
+
+// GetRelatedBooks 获取相关图书
+func (r *RecommendServiceImpl) GetRelatedBooks(ctx context.Context, bookID uint64, limit int) ([]*model.Book, error) {
+	book, err := r.bookDao.GetBookByID(ctx, bookID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrBookNotFound
+		}
+		return nil, err
+	}
+	books, err := r.recommendDao.GetRelatedBooks(ctx, bookID, limit)
+	if err != nil {
+		return nil, err
+	}
+	if len(books) >= limit || book.CategoryID == 0 {
+		return books, nil
+	}
+	more, err := r.recommendDao.GetCategoryBestsellers(ctx, []uint64{book.CategoryID}, append(bookIDs(books), bookID), limit-len(books))
+	if err != nil {
+		return nil, err
+	}
+	return append(books, more...), nil
+}
+
+// GetRecommendations 个性化推荐
+//
+//	// 1. 与最近购买、收藏的图书相似度之和最高的图书
+//	// 2. 不足时补充这些图书所在分类的畅销书
+//	// 3. 仍不足 (含未登录和没有任何记录的用户) 时补充各分类畅销书
+//	// 已购买和已收藏的图书不再推荐
+func (r *RecommendServiceImpl) GetRecommendations(ctx context.Context, userID uint64, limit int) ([]*model.Book, error) {
+	var seeds []uint64
+	if userID != 0 {
+		purchased, err := r.orderDao.GetPaidBookIDs(ctx, userID, recommendHistorySize)
+		if err != nil {
+			return nil, err
+		}
+		favorites, err := r.favoriteDao.GetFavoriteBookIDs(ctx, userID, recommendHistorySize)
+		if err != nil {
+			return nil, err
+		}
+		seeds = mergeIDs(purchased, favorites)
+	}
+
+	books, err := r.recommendDao.GetSimilarToBooks(ctx, seeds, seeds, limit)
+	if err != nil {
+		return nil, err
+	}
+	if len(books) < limit && len(seeds) > 0 {
+		seedBooks, err := r.bookDao.GetBooksByIDs(ctx, seeds)
+		if err != nil {
+			return nil, err
+		}
+		var categoryIDs []uint64
+		for _, book := range seedBooks {
+			if book.CategoryID != 0 {
+				categoryIDs = append(categoryIDs, book.CategoryID)
+			}
+		}
+		more, err := r.recommendDao.GetCategoryBestsellers(ctx, mergeIDs(categoryIDs), mergeIDs(seeds, bookIDs(books)), limit-len(books))
+		if err != nil {
+			return nil, err
+		}
+		books = append(books, more...)
+	}
+	if len(books) < limit {
+		more, err := r.recommendDao.GetBestsellersAcrossCategories(ctx, mergeIDs(seeds, bookIDs(books)), limit-len(books))
+		if err != nil {
+			return nil, err
+		}
+		books = append(books, more...)
+	}
+	return books, nil
+}
+
+// bookIDs 提取图书 ID
+func bookIDs(books []*model.Book) []uint64 {
+	ids := make([]uint64, 0, len(books))
+	for _, book := range books {
+		ids = append(ids, book.ID)
+	}
+	return ids
+}
+
+// mergeIDs 合并并去重, 保持首次出现的顺序
+func mergeIDs(lists ...[]uint64) []uint64 {
+	seen := make(map[uint64]bool)
+	var merged []uint64
+	for _, list := range lists {
+		for _, id := range list {
+			if !seen[id] {
+				seen[id] = true
+				merged = append(merged, id)
+			}
+		}
+	}
+	return merged
+}
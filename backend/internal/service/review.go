@@ -0,0 +1,381 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"github.com/wangn-tech/bookstore-go/common/result"
+	"github.com/wangn-tech/bookstore-go/internal/api/request"
+	"github.com/wangn-tech/bookstore-go/internal/api/response"
+	"github.com/wangn-tech/bookstore-go/internal/app/initializer/database"
+	"github.com/wangn-tech/bookstore-go/internal/model"
+	"github.com/wangn-tech/bookstore-go/internal/repository"
+	"gorm.io/gorm"
+)
+
+// IReviewService 图书评价服务接口
+type IReviewService interface {
+	CreateReview(ctx context.Context, userID uint64, bookID uint64, dto *request.ReviewDTO) (*response.ReviewVO, error)
+	UpdateReview(ctx context.Context, userID uint64, id uint64, dto *request.ReviewDTO) (*response.ReviewVO, error)
+	DeleteReview(ctx context.Context, userID uint64, id uint64) error
+	// GetBookReviews 分页获取图书展示中的评价, userID 为 0 表示未登录
+	GetBookReviews(ctx context.Context, userID uint64, bookID uint64, dto *request.ReviewsPageDTO) (*response.ReviewsPageVO, error)
+	GetRatingSummary(ctx context.Context, bookID uint64) (*response.RatingSummaryVO, error)
+	// GetRatingDistribution 获取图书 5 星到 1 星的评价数量
+	GetRatingDistribution(ctx context.Context, bookID uint64) ([]*response.RatingBucketVO, error)
+	VoteHelpful(ctx context.Context, userID uint64, id uint64) error
+	UnvoteHelpful(ctx context.Context, userID uint64, id uint64) error
+
+	// 管理员接口
+	GetReviewsForAdmin(ctx context.Context, dto *request.AdminReviewsPageDTO) (*response.AdminReviewsPageVO, error)
+	UpdateReviewStatus(ctx context.Context, id uint64, status int) (*model.Review, error)
+}
+
+var (
+	// ErrReviewNotFound 评价不存在
+	ErrReviewNotFound = errors.New("评价不存在")
+	// ErrReviewNotPurchased 未购买该图书
+	ErrReviewNotPurchased = errors.New("购买该图书后才能评价")
+	// ErrReviewExists 已评价过该图书
+	ErrReviewExists = errors.New("已评价过该图书，请修改原评价")
+	// ErrReviewVoteSelf 不能给自己的评价投票
+	ErrReviewVoteSelf = errors.New("不能给自己的评价投票")
+	// ErrReviewVoteNotFound 未投票
+	ErrReviewVoteNotFound = errors.New("未给该评价投票")
+)
+
+type ReviewServiceImpl struct {
+	reviewDao *repository.ReviewDao
+	bookDao   *repository.BookDao
+	orderDao  *repository.OrderDao
+}
+
+func NewReviewService(reviewDao *repository.ReviewDao, bookDao *repository.BookDao, orderDao *repository.OrderDao) IReviewService {
+	return &ReviewServiceImpl{
+		reviewDao: reviewDao,
+		bookDao:   bookDao,
+		orderDao:  orderDao,
+	}
+}
+
+// CreateReview 发表评价, 只有已支付订单中包含该图书的用户可以评价, 每本书只能评价一次
+func (r *ReviewServiceImpl) CreateReview(ctx context.Context, userID uint64, bookID uint64, dto *request.ReviewDTO) (*response.ReviewVO, error) {
+	if _, err := r.bookDao.GetBookByID(ctx, bookID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrBookNotFound
+		}
+		return nil, err
+	}
+	purchased, err := r.orderDao.HasPaidOrderItem(ctx, userID, bookID)
+	if err != nil {
+		return nil, err
+	}
+	if !purchased {
+		return nil, ErrReviewNotPurchased
+	}
+	exists, err := r.reviewDao.ExistsUserReview(ctx, userID, bookID)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		return nil, ErrReviewExists
+	}
+
+	review := &model.Review{
+		UserID:  userID,
+		BookID:  bookID,
+		Rating:  dto.Rating,
+		Content: dto.Content,
+		Status:  model.ReviewStatusPending,
+	}
+	err = database.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := r.reviewDao.CreateReviewTx(ctx, tx, review); err != nil {
+			return err
+		}
+		return r.bookDao.RefreshRatingTx(ctx, tx, bookID)
+	})
+	if err != nil {
+		// 并发提交时两个请求都可能通过上面的检查, 由 uk_user_book 唯一索引兜底
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return nil, ErrReviewExists
+		}
+		return nil, err
+	}
+	return r.getReviewVO(ctx, review.ID)
+}
+
+// UpdateReview 修改自己的评价
+//
+//	// 已通过审核的评价修改后重新进入待审核; 已隐藏的评价保持隐藏, 避免通过修改绕过审核
+func (r *ReviewServiceImpl) UpdateReview(ctx context.Context, userID uint64, id uint64, dto *request.ReviewDTO) (*response.ReviewVO, error) {
+	err := database.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		review, err := r.lockOwnReviewTx(ctx, tx, userID, id)
+		if err != nil {
+			return err
+		}
+		review.Rating = dto.Rating
+		review.Content = dto.Content
+		if review.Status == model.ReviewStatusApproved {
+			review.Status = model.ReviewStatusPending
+		}
+		if err := r.reviewDao.UpdateReviewTx(ctx, tx, review); err != nil {
+			return err
+		}
+		return r.bookDao.RefreshRatingTx(ctx, tx, review.BookID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return r.getReviewVO(ctx, id)
+}
+
+// DeleteReview 删除自己的评价
+func (r *ReviewServiceImpl) DeleteReview(ctx context.Context, userID uint64, id uint64) error {
+	return database.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		review, err := r.lockOwnReviewTx(ctx, tx, userID, id)
+		if err != nil {
+			return err
+		}
+		if err := r.reviewDao.DeleteReviewTx(ctx, tx, id); err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrReviewNotFound
+			}
+			return err
+		}
+		return r.bookDao.RefreshRatingTx(ctx, tx, review.BookID)
+	})
+}
+
+// GetBookReviews 分页获取图书展示中的评价, 并填充当前用户的投票状态
+func (r *ReviewServiceImpl) GetBookReviews(ctx context.Context, userID uint64, bookID uint64, dto *request.ReviewsPageDTO) (*response.ReviewsPageVO, error) {
+	result.PageVerify(&dto.Page, &dto.PageSize)
+	filter := &repository.ReviewFilter{
+		BookID:      bookID,
+		Rating:      dto.Rating,
+		VisibleOnly: true,
+		Sort:        dto.Sort,
+	}
+	pageResult, err := r.reviewDao.GetReviewsByPage(ctx, filter, dto.Page, dto.PageSize)
+	if err != nil {
+		return nil, err
+	}
+	if err := r.fillVoteInfo(ctx, userID, pageResult.Records); err != nil {
+		return nil, err
+	}
+
+	vo := &response.ReviewsPageVO{
+		Reviews:   make([]*response.ReviewVO, 0, len(pageResult.Records)),
+		Total:     pageResult.Total,
+		Page:      dto.Page,
+		PageSize:  dto.PageSize,
+		TotalPage: (pageResult.Total + int64(dto.PageSize) - 1) / int64(dto.PageSize),
+	}
+	for _, review := range pageResult.Records {
+		vo.Reviews = append(vo.Reviews, newReviewVO(review))
+	}
+	return vo, nil
+}
+
+// GetRatingSummary 获取图书的平均评分、评分人数和星级分布
+func (r *ReviewServiceImpl) GetRatingSummary(ctx context.Context, bookID uint64) (*response.RatingSummaryVO, error) {
+	book, err := r.bookDao.GetBookByID(ctx, bookID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrBookNotFound
+		}
+		return nil, err
+	}
+	distribution, err := r.GetRatingDistribution(ctx, bookID)
+	if err != nil {
+		return nil, err
+	}
+	return &response.RatingSummaryVO{
+		RatingAvg:    book.RatingAvg,
+		RatingCount:  book.RatingCount,
+		Distribution: distribution,
+	}, nil
+}
+
+// GetRatingDistribution 获取图书展示中评价的星级分布, 没有评价的星级计为 0
+func (r *ReviewServiceImpl) GetRatingDistribution(ctx context.Context, bookID uint64) ([]*response.RatingBucketVO, error) {
+	counts, err := r.reviewDao.GetRatingDistribution(ctx, bookID)
+	if err != nil {
+		return nil, err
+	}
+	distribution := make([]*response.RatingBucketVO, 0, 5)
+	for rating := 5; rating >= 1; rating-- {
+		distribution = append(distribution, &response.RatingBucketVO{Rating: rating, Count: counts[rating]})
+	}
+	return distribution, nil
+}
+
+// VoteHelpful 给评价投有用票, 重复投票视为成功
+func (r *ReviewServiceImpl) VoteHelpful(ctx context.Context, userID uint64, id uint64) error {
+	return database.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		review, err := r.lockVisibleReviewTx(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+		if review.UserID == userID {
+			return ErrReviewVoteSelf
+		}
+		added, err := r.reviewDao.AddVoteTx(ctx, tx, id, userID)
+		if err != nil || !added {
+			return err
+		}
+		return r.reviewDao.IncrHelpfulCountTx(ctx, tx, id, 1)
+	})
+}
+
+// UnvoteHelpful 撤销有用票
+func (r *ReviewServiceImpl) UnvoteHelpful(ctx context.Context, userID uint64, id uint64) error {
+	return database.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if _, err := r.lockVisibleReviewTx(ctx, tx, id); err != nil {
+			return err
+		}
+		if err := r.reviewDao.RemoveVoteTx(ctx, tx, id, userID); err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrReviewVoteNotFound
+			}
+			return err
+		}
+		return r.reviewDao.IncrHelpfulCountTx(ctx, tx, id, -1)
+	})
+}
+
+// GetReviewsForAdmin 管理员分页获取评价, 包含已隐藏的评价
+func (r *ReviewServiceImpl) GetReviewsForAdmin(ctx context.Context, dto *request.AdminReviewsPageDTO) (*response.AdminReviewsPageVO, error) {
+	result.PageVerify(&dto.Page, &dto.PageSize)
+	filter := &repository.ReviewFilter{
+		BookID:   dto.BookID,
+		UserID:   dto.UserID,
+		Status:   dto.Status,
+		WithBook: true,
+	}
+	pageResult, err := r.reviewDao.GetReviewsByPage(ctx, filter, dto.Page, dto.PageSize)
+	if err != nil {
+		return nil, err
+	}
+	return &response.AdminReviewsPageVO{
+		Reviews:   pageResult.Records,
+		Total:     pageResult.Total,
+		Page:      dto.Page,
+		PageSize:  dto.PageSize,
+		TotalPage: (pageResult.Total + int64(dto.PageSize) - 1) / int64(dto.PageSize),
+	}, nil
+}
+
+// UpdateReviewStatus 管理员审核评价: 通过或隐藏, 展示状态变化时重新统计图书评分
+func (r *ReviewServiceImpl) UpdateReviewStatus(ctx context.Context, id uint64, status int) (*model.Review, error) {
+	var review *model.Review
+	err := database.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var err error
+		review, err = r.lockReviewTx(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+		wasVisible := review.Visible()
+		review.Status = status
+		if err := r.reviewDao.UpdateReviewStatusTx(ctx, tx, id, status); err != nil {
+			return err
+		}
+		if wasVisible == review.Visible() {
+			return nil
+		}
+		return r.bookDao.RefreshRatingTx(ctx, tx, review.BookID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return review, nil
+}
+
+// lockReviewTx 在事务中锁定评价
+func (r *ReviewServiceImpl) lockReviewTx(ctx context.Context, tx *gorm.DB, id uint64) (*model.Review, error) {
+	review, err := r.reviewDao.GetReviewForUpdateTx(ctx, tx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrReviewNotFound
+		}
+		return nil, err
+	}
+	return review, nil
+}
+
+// lockOwnReviewTx 在事务中锁定用户自己的评价, 他人的评价视为不存在
+func (r *ReviewServiceImpl) lockOwnReviewTx(ctx context.Context, tx *gorm.DB, userID uint64, id uint64) (*model.Review, error) {
+	review, err := r.lockReviewTx(ctx, tx, id)
+	if err != nil {
+		return nil, err
+	}
+	if review.UserID != userID {
+		return nil, ErrReviewNotFound
+	}
+	return review, nil
+}
+
+// lockVisibleReviewTx 在事务中锁定展示中的评价, 已隐藏的评价视为不存在
+func (r *ReviewServiceImpl) lockVisibleReviewTx(ctx context.Context, tx *gorm.DB, id uint64) (*model.Review, error) {
+	review, err := r.lockReviewTx(ctx, tx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !review.Visible() {
+		return nil, ErrReviewNotFound
+	}
+	return review, nil
+}
+
+// getReviewVO 写操作提交后重新读取评价
+func (r *ReviewServiceImpl) getReviewVO(ctx context.Context, id uint64) (*response.ReviewVO, error) {
+	review, err := r.reviewDao.GetReviewByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrReviewNotFound
+		}
+		return nil, err
+	}
+	return newReviewVO(review), nil
+}
+
+// fillVoteInfo 批量填充当前用户的投票状态
+func (r *ReviewServiceImpl) fillVoteInfo(ctx context.Context, userID uint64, reviews []*model.Review) error {
+	if userID == 0 || len(reviews) == 0 {
+		return nil
+	}
+	ids := make([]uint64, 0, len(reviews))
+	for _, review := range reviews {
+		ids = append(ids, review.ID)
+	}
+	voted, err := r.reviewDao.GetVotedReviewIDs(ctx, userID, ids)
+	if err != nil {
+		return err
+	}
+	for _, review := range reviews {
+		review.IsVoted = voted[review.ID]
+	}
+	return nil
+}
+
+// newReviewVO 转换为对外展示的评价, 只保留评价用户的公开信息
+func newReviewVO(review *model.Review) *response.ReviewVO {
+	vo := &response.ReviewVO{
+		ID:           review.ID,
+		BookID:       review.BookID,
+		Rating:       review.Rating,
+		Content:      review.Content,
+		HelpfulCount: review.HelpfulCount,
+		Status:       review.Status,
+		IsVoted:      review.IsVoted,
+		CreatedAt:    review.CreatedAt,
+		UpdatedAt:    review.UpdatedAt,
+	}
+	if review.User != nil {
+		vo.User = &response.ReviewerVO{
+			ID:       review.User.ID,
+			Username: review.User.Username,
+			Avatar:   review.User.Avatar,
+		}
+	}
+	return vo
+}
@@ -0,0 +1,107 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"slices"
+
+	"github.com/wangn-tech/bookstore-go/internal/app/constants"
+	"github.com/wangn-tech/bookstore-go/internal/app/initializer/database"
+	"github.com/wangn-tech/bookstore-go/internal/model"
+	"github.com/wangn-tech/bookstore-go/internal/repository"
+	"github.com/wangn-tech/bookstore-go/internal/utils"
+	"gorm.io/gorm"
+)
+
+type IRoleService interface {
+	// GetAllRoles 获取所有角色及其权限
+	GetAllRoles(ctx context.Context) ([]*model.Role, error)
+	// GetUserRoles 获取用户的角色
+	GetUserRoles(ctx context.Context, userID uint64) ([]*model.Role, error)
+	// SetUserRoles 覆盖设置用户的角色
+	SetUserRoles(ctx context.Context, userID uint64, roleNames []string) error
+	// GrantRole 按用户名为用户追加角色, 保留已有角色
+	GrantRole(ctx context.Context, username string, roleName string) error
+}
+
+type RoleServiceImpl struct {
+	roleDao *repository.RoleDao
+	userDao *repository.UserDao
+}
+
+func NewRoleService(roleDao *repository.RoleDao, userDao *repository.UserDao) IRoleService {
+	return &RoleServiceImpl{
+		roleDao: roleDao,
+		userDao: userDao,
+	}
+}
+
+// GetAllRoles 获取所有角色及其权限
+func (r *RoleServiceImpl) GetAllRoles(ctx context.Context) ([]*model.Role, error) {
+	return r.roleDao.GetAllRoles(ctx)
+}
+
+// GetUserRoles 获取用户的角色
+func (r *RoleServiceImpl) GetUserRoles(ctx context.Context, userID uint64) ([]*model.Role, error) {
+	if _, err := r.userDao.GetUserByID(ctx, userID); err != nil {
+		return nil, errors.New("用户不存在")
+	}
+	return r.roleDao.GetUserRoles(ctx, userID)
+}
+
+// SetUserRoles 覆盖设置用户的角色, 同步 users.is_admin 标记
+//
+//	// 角色写入 token 载荷, 变更后撤销用户的全部会话, 用户重新登录后按新角色签发 token
+//	// 避免被撤销管理员角色的用户在 access token 过期前仍保留原有权限
+func (r *RoleServiceImpl) SetUserRoles(ctx context.Context, userID uint64, roleNames []string) error {
+	if _, err := r.userDao.GetUserByID(ctx, userID); err != nil {
+		return errors.New("用户不存在")
+	}
+	// 去重后校验角色是否全部存在
+	roleNames = slices.Compact(slices.Sorted(slices.Values(roleNames)))
+	roles, err := r.roleDao.GetRolesByNames(ctx, roleNames)
+	if err != nil {
+		return err
+	}
+	if len(roles) != len(roleNames) {
+		return errors.New("角色不存在")
+	}
+
+	roleIDs := make([]uint64, 0, len(roles))
+	for _, role := range roles {
+		roleIDs = append(roleIDs, role.ID)
+	}
+	isAdmin := slices.Contains(roleNames, constants.RoleAdmin)
+	err = database.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := r.roleDao.ReplaceUserRolesTx(ctx, tx, userID, roleIDs); err != nil {
+			return err
+		}
+		return r.userDao.UpdateIsAdminTx(ctx, tx, userID, isAdmin)
+	})
+	if err != nil {
+		return err
+	}
+	return utils.RevokeAllSessions(userID)
+}
+
+// GrantRole 按用户名为用户追加角色, 保留已有角色
+//
+//	// 用于命令行初始化首个管理员: 分配角色需要 role:manage 权限, 全新安装时没有任何用户拥有该权限
+func (r *RoleServiceImpl) GrantRole(ctx context.Context, username string, roleName string) error {
+	user, err := r.userDao.GetUserByUsername(ctx, username)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New("用户不存在")
+		}
+		return err
+	}
+	roles, err := r.roleDao.GetUserRoles(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+	roleNames := []string{roleName}
+	for _, role := range roles {
+		roleNames = append(roleNames, role.Name)
+	}
+	return r.SetUserRoles(ctx, user.ID, roleNames)
+}
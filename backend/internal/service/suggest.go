@@ -0,0 +1,441 @@
+package service
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	goredis "github.com/go-redis/redis/v8"
+	"github.com/wangn-tech/bookstore-go/internal/api/response"
+	"github.com/wangn-tech/bookstore-go/internal/app/initializer/redis"
+	"github.com/wangn-tech/bookstore-go/internal/model"
+	"github.com/wangn-tech/bookstore-go/internal/repository"
+	"github.com/wangn-tech/bookstore-go/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// ISuggestService 搜索联想服务接口
+type ISuggestService interface {
+	// Suggest 按前缀返回书名、作者、丛书和热门搜索词联想, prefix 为空时返回热门搜索词
+	Suggest(ctx context.Context, prefix string, limit int) ([]*response.SuggestionVO, error)
+	// IndexBook 图书变更后增量更新联想索引, before 为 nil 表示新建, after 为 nil 表示删除
+	IndexBook(ctx context.Context, before, after *model.Book) error
+	// RecordPaid 订单支付后按购买数量增加相关图书联想词的权重
+	RecordPaid(ctx context.Context, order *model.Order) error
+	// RecordRefunded 订单退款后扣除相关图书联想词的权重
+	RecordRefunded(ctx context.Context, order *model.Order) error
+	// RecordQuery 记录一次搜索词, 作为热门搜索联想
+	RecordQuery(ctx context.Context, keyword string) error
+	// IndexExists 联想索引是否已构建
+	IndexExists(ctx context.Context) (bool, error)
+	// RebuildIndex 从数据库全量重建联想索引, 返回处理的上架图书数
+	RebuildIndex(ctx context.Context) (int, error)
+}
+
+// 联想类型
+const (
+	SuggestTypeTitle  = "title"
+	SuggestTypeAuthor = "author"
+	SuggestTypeSeries = "series"
+	SuggestTypeQuery  = "query"
+)
+
+// 联想索引存储结构
+//
+//	// suggest:lex     zset (score 均为 0): "{归一化文本}\x00{类型}\x00{原文}", 用 ZRANGEBYLEX 做前缀匹配
+//	// suggest:weight  zset: "{类型}\x00{原文}" → 权重 (包含该词的上架图书销量之和), 用于排序;
+//	//                 词加入索引时计入图书当时的销量, 之后随订单支付、退款增减
+//	// suggest:refs    hash: "{类型}\x00{原文}" → 引用该词的上架图书数, 归零时从索引中删除
+//	// suggest:queries zset: 搜索词原文 → 搜索次数; 搜索词同时写入 suggest:lex
+const (
+	suggestLexKey     = "suggest:lex"
+	suggestWeightKey  = "suggest:weight"
+	suggestRefsKey    = "suggest:refs"
+	suggestQueriesKey = "suggest:queries"
+
+	// 全量重建时先写入临时键, 完成后原子替换
+	suggestRebuildSuffix = ":rebuild"
+
+	// 单次前缀匹配最多取出的候选数, 控制排序开销
+	suggestMaxCandidates = 100
+	// 热门搜索词最多保留的数量, 超出时淘汰搜索次数最少的
+	suggestMaxQueries = 10000
+	// 记录的搜索词最大长度
+	suggestMaxQueryRunes = 50
+	// 分隔符, 不会出现在归一化文本中
+	suggestSep = "\x00"
+)
+
+// suggestRemoveScript 原子地减少引用计数, 归零时从索引中删除该词
+//
+//	// KEYS: refs, lex, weight; ARGV: 词 ("{类型}\x00{原文}"), lex 成员, 权重减量
+var suggestRemoveScript = goredis.NewScript(`
+local n = redis.call('HINCRBY', KEYS[1], ARGV[1], -1)
+if n <= 0 then
+	redis.call('HDEL', KEYS[1], ARGV[1])
+	redis.call('ZREM', KEYS[2], ARGV[2])
+	redis.call('ZREM', KEYS[3], ARGV[1])
+	return 0
+end
+if tonumber(redis.call('ZINCRBY', KEYS[3], -tonumber(ARGV[3]), ARGV[1])) < 0 then
+	redis.call('ZADD', KEYS[3], 0, ARGV[1])
+end
+return n
+`)
+
+// suggestWeightScript 原子地调整已索引词的权重, 权重不低于 0
+//
+//	// KEYS: refs, weight; ARGV: 词 ("{类型}\x00{原文}"), 权重增量
+//	// 词不在索引中 (图书未上架或索引尚未构建) 时不处理, 避免产生没有引用计数的权重
+var suggestWeightScript = goredis.NewScript(`
+if redis.call('HEXISTS', KEYS[1], ARGV[1]) == 0 then
+	return 0
+end
+if tonumber(redis.call('ZINCRBY', KEYS[2], ARGV[2], ARGV[1])) < 0 then
+	redis.call('ZADD', KEYS[2], 0, ARGV[1])
+end
+return 1
+`)
+
+// suggestTerm 图书中参与联想的一个词
+type suggestTerm struct {
+	kind string
+	text string
+}
+
+// key 用于 suggest:weight 和 suggest:refs 的成员名
+func (t suggestTerm) key() string {
+	return t.kind + suggestSep + t.text
+}
+
+// lexMember 用于 suggest:lex 的成员名
+func (t suggestTerm) lexMember() string {
+	return normalizeSuggest(t.text) + suggestSep + t.kind + suggestSep + t.text
+}
+
+// normalizeSuggest 归一化: 去除首尾空白、合并连续空白、转小写
+func normalizeSuggest(text string) string {
+	return strings.ToLower(strings.Join(strings.Fields(text), " "))
+}
+
+// bookSuggestTerms 图书参与联想的词, 未上架的图书不参与
+func bookSuggestTerms(book *model.Book) []suggestTerm {
+	if book == nil || book.Status != 1 {
+		return nil
+	}
+	var terms []suggestTerm
+	for _, term := range []suggestTerm{
+		{SuggestTypeTitle, strings.TrimSpace(book.Title)},
+		{SuggestTypeAuthor, strings.TrimSpace(book.Author)},
+		{SuggestTypeSeries, strings.TrimSpace(book.Series)},
+	} {
+		if term.text != "" {
+			terms = append(terms, term)
+		}
+	}
+	return terms
+}
+
+// SuggestServiceImpl 搜索联想服务实现
+//
+//	// 索引保存在 Redis, 图书写操作后由 BookService 增量更新, 销量权重由 OrderService 在支付、退款后更新;
+//	// 索引丢失时启动时或通过命令行全量重建
+type SuggestServiceImpl struct {
+	bookDao *repository.BookDao
+}
+
+func NewSuggestService(bookDao *repository.BookDao) ISuggestService {
+	return &SuggestServiceImpl{
+		bookDao: bookDao,
+	}
+}
+
+// Suggest 按前缀返回联想词, 按权重 (销量或搜索次数) 倒序
+func (s *SuggestServiceImpl) Suggest(ctx context.Context, prefix string, limit int) ([]*response.SuggestionVO, error) {
+	prefix = normalizeSuggest(prefix)
+	if prefix == "" {
+		return s.popularQueries(ctx, limit)
+	}
+
+	members, err := redis.RedisClient.ZRangeByLex(ctx, suggestLexKey, &goredis.ZRangeBy{
+		Min:   "[" + prefix,
+		Max:   "[" + prefix + "\xff",
+		Count: suggestMaxCandidates,
+	}).Result()
+	if err != nil {
+		return nil, err
+	}
+	if len(members) == 0 {
+		return []*response.SuggestionVO{}, nil
+	}
+
+	// 批量查询候选词的权重
+	type candidate struct {
+		term   suggestTerm
+		lex    string
+		weight *goredis.FloatCmd
+	}
+	candidates := make([]*candidate, 0, len(members))
+	pipe := redis.RedisClient.Pipeline()
+	for _, member := range members {
+		parts := strings.SplitN(member, suggestSep, 3)
+		if len(parts) != 3 {
+			continue
+		}
+		c := &candidate{term: suggestTerm{kind: parts[1], text: parts[2]}, lex: member}
+		if c.term.kind == SuggestTypeQuery {
+			c.weight = pipe.ZScore(ctx, suggestQueriesKey, c.term.text)
+		} else {
+			c.weight = pipe.ZScore(ctx, suggestWeightKey, c.term.key())
+		}
+		candidates = append(candidates, c)
+	}
+	if _, err := pipe.Exec(ctx); err != nil && err != goredis.Nil {
+		return nil, err
+	}
+
+	type scored struct {
+		term   suggestTerm
+		weight float64
+	}
+	results := make([]scored, 0, len(candidates))
+	var stale []any
+	for _, c := range candidates {
+		weight, err := c.weight.Result()
+		if err == goredis.Nil {
+			// 热门搜索词已被淘汰, 清理残留的前缀索引
+			if c.term.kind == SuggestTypeQuery {
+				stale = append(stale, c.lex)
+				continue
+			}
+			weight = 0
+		} else if err != nil {
+			return nil, err
+		}
+		results = append(results, scored{term: c.term, weight: weight})
+	}
+	if len(stale) > 0 {
+		redis.RedisClient.ZRem(ctx, suggestLexKey, stale...)
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].weight > results[j].weight
+	})
+
+	// 同一文本只返回一次, 图书词优先于搜索词 (权重相同时按 ZRANGEBYLEX 顺序)
+	suggestions := make([]*response.SuggestionVO, 0, limit)
+	seen := make(map[string]bool)
+	for _, r := range results {
+		normalized := normalizeSuggest(r.term.text)
+		if seen[normalized] {
+			continue
+		}
+		seen[normalized] = true
+		suggestions = append(suggestions, &response.SuggestionVO{Text: r.term.text, Type: r.term.kind})
+		if len(suggestions) >= limit {
+			break
+		}
+	}
+	return suggestions, nil
+}
+
+// popularQueries 返回搜索次数最多的搜索词
+func (s *SuggestServiceImpl) popularQueries(ctx context.Context, limit int) ([]*response.SuggestionVO, error) {
+	queries, err := redis.RedisClient.ZRevRange(ctx, suggestQueriesKey, 0, int64(limit-1)).Result()
+	if err != nil {
+		return nil, err
+	}
+	suggestions := make([]*response.SuggestionVO, 0, len(queries))
+	for _, query := range queries {
+		suggestions = append(suggestions, &response.SuggestionVO{Text: query, Type: SuggestTypeQuery})
+	}
+	return suggestions, nil
+}
+
+// IndexBook 图书变更后增量更新联想索引
+//
+//	// 对比变更前后的词: 新增的词引用计数 +1 并计入当前销量, 移除的词引用计数 -1 (归零时删除) 并扣除变更前的销量;
+//	// 保留的词权重不变, 销量变化由 RecordPaid / RecordRefunded 计入
+func (s *SuggestServiceImpl) IndexBook(ctx context.Context, before, after *model.Book) error {
+	oldTerms := make(map[string]suggestTerm)
+	for _, term := range bookSuggestTerms(before) {
+		oldTerms[term.key()] = term
+	}
+	newTerms := make(map[string]suggestTerm)
+	for _, term := range bookSuggestTerms(after) {
+		newTerms[term.key()] = term
+	}
+	var oldSale, newSale int
+	if before != nil {
+		oldSale = before.Sale
+	}
+	if after != nil {
+		newSale = after.Sale
+	}
+
+	for key, term := range oldTerms {
+		if _, ok := newTerms[key]; ok {
+			continue
+		}
+		keys := []string{suggestRefsKey, suggestLexKey, suggestWeightKey}
+		if err := suggestRemoveScript.Run(ctx, redis.RedisClient, keys, key, term.lexMember(), oldSale).Err(); err != nil {
+			return err
+		}
+	}
+
+	pipe := redis.RedisClient.TxPipeline()
+	for key, term := range newTerms {
+		if _, ok := oldTerms[key]; ok {
+			continue
+		}
+		pipe.HIncrBy(ctx, suggestRefsKey, key, 1)
+		pipe.ZAdd(ctx, suggestLexKey, &goredis.Z{Member: term.lexMember()})
+		pipe.ZIncrBy(ctx, suggestWeightKey, float64(newSale), key)
+	}
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// RecordPaid 订单支付后增加联想词权重
+func (s *SuggestServiceImpl) RecordPaid(ctx context.Context, order *model.Order) error {
+	return s.recordSales(ctx, order, 1)
+}
+
+// RecordRefunded 订单退款后扣除联想词权重
+func (s *SuggestServiceImpl) RecordRefunded(ctx context.Context, order *model.Order) error {
+	return s.recordSales(ctx, order, -1)
+}
+
+// recordSales 按订单中各图书的数量调整其联想词的权重, sign 为 1 表示支付, -1 表示退款
+func (s *SuggestServiceImpl) recordSales(ctx context.Context, order *model.Order, sign int) error {
+	if len(order.OrderItems) == 0 {
+		return nil
+	}
+	quantities := make(map[uint64]int, len(order.OrderItems))
+	ids := make([]uint64, 0, len(order.OrderItems))
+	for _, item := range order.OrderItems {
+		if _, ok := quantities[item.BookID]; !ok {
+			ids = append(ids, item.BookID)
+		}
+		quantities[item.BookID] += item.Quantity
+	}
+	books, err := s.bookDao.GetBooksByIDs(ctx, ids)
+	if err != nil {
+		return err
+	}
+
+	// 同一订单中多本图书包含同一个词 (如同一作者) 时合并为一次调整
+	deltas := make(map[string]int)
+	for _, book := range books {
+		for _, term := range bookSuggestTerms(book) {
+			deltas[term.key()] += sign * quantities[book.ID]
+		}
+	}
+	keys := []string{suggestRefsKey, suggestWeightKey}
+	for key, delta := range deltas {
+		if delta == 0 {
+			continue
+		}
+		if err := suggestWeightScript.Run(ctx, redis.RedisClient, keys, key, delta).Err(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RecordQuery 记录一次搜索词
+func (s *SuggestServiceImpl) RecordQuery(ctx context.Context, keyword string) error {
+	query := strings.Join(strings.Fields(keyword), " ")
+	if query == "" || utf8.RuneCountInString(query) > suggestMaxQueryRunes {
+		return nil
+	}
+	term := suggestTerm{kind: SuggestTypeQuery, text: query}
+	pipe := redis.RedisClient.TxPipeline()
+	pipe.ZIncrBy(ctx, suggestQueriesKey, 1, query)
+	pipe.ZAdd(ctx, suggestLexKey, &goredis.Z{Member: term.lexMember()})
+	// 只保留搜索次数最多的搜索词, 被淘汰词的前缀索引在联想时清理
+	pipe.ZRemRangeByRank(ctx, suggestQueriesKey, 0, -suggestMaxQueries-1)
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// IndexExists 联想索引是否已构建
+func (s *SuggestServiceImpl) IndexExists(ctx context.Context) (bool, error) {
+	n, err := redis.RedisClient.Exists(ctx, suggestRefsKey).Result()
+	if err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
+
+// RebuildIndex 从数据库全量重建联想索引
+//
+//	// 图书词写入临时键后原子替换, 重建期间联想仍可使用旧索引; 热门搜索词保留, 重新写入前缀索引
+func (s *SuggestServiceImpl) RebuildIndex(ctx context.Context) (int, error) {
+	lexTmp := suggestLexKey + suggestRebuildSuffix
+	weightTmp := suggestWeightKey + suggestRebuildSuffix
+	refsTmp := suggestRefsKey + suggestRebuildSuffix
+	if err := redis.RedisClient.Del(ctx, lexTmp, weightTmp, refsTmp).Err(); err != nil {
+		return 0, err
+	}
+
+	onShelf := 1
+	filter := &repository.BookFilter{Status: &onShelf}
+	total := 0
+	for page := 1; ; page++ {
+		pageResult, err := s.bookDao.GetBooksByPage(ctx, filter, page, reindexBatchSize)
+		if err != nil {
+			return total, err
+		}
+		pipe := redis.RedisClient.Pipeline()
+		for _, book := range pageResult.Records {
+			for _, term := range bookSuggestTerms(book) {
+				pipe.HIncrBy(ctx, refsTmp, term.key(), 1)
+				pipe.ZAdd(ctx, lexTmp, &goredis.Z{Member: term.lexMember()})
+				pipe.ZIncrBy(ctx, weightTmp, float64(book.Sale), term.key())
+			}
+		}
+		if _, err := pipe.Exec(ctx); err != nil {
+			return total, err
+		}
+		total += len(pageResult.Records)
+		if len(pageResult.Records) < reindexBatchSize {
+			break
+		}
+	}
+
+	queries, err := redis.RedisClient.ZRange(ctx, suggestQueriesKey, 0, -1).Result()
+	if err != nil {
+		return total, err
+	}
+	if len(queries) > 0 {
+		members := make([]*goredis.Z, 0, len(queries))
+		for _, query := range queries {
+			members = append(members, &goredis.Z{Member: suggestTerm{kind: SuggestTypeQuery, text: query}.lexMember()})
+		}
+		if err := redis.RedisClient.ZAdd(ctx, lexTmp, members...).Err(); err != nil {
+			return total, err
+		}
+	}
+
+	// 没有任何上架图书时临时键不存在, RENAME 会失败, 改为直接删除旧索引
+	if total == 0 && len(queries) == 0 {
+		return 0, redis.RedisClient.Del(ctx, suggestLexKey, suggestWeightKey, suggestRefsKey).Err()
+	}
+	_, err = redis.RedisClient.TxPipelined(ctx, func(pipe goredis.Pipeliner) error {
+		pipe.Rename(ctx, lexTmp, suggestLexKey)
+		if total > 0 {
+			pipe.Rename(ctx, weightTmp, suggestWeightKey)
+			pipe.Rename(ctx, refsTmp, suggestRefsKey)
+		} else {
+			pipe.Del(ctx, suggestWeightKey, suggestRefsKey)
+		}
+		return nil
+	})
+	if err != nil {
+		return total, err
+	}
+	logger.Log.Info("RebuildIndex: 联想索引重建完成", zap.Int("books", total), zap.Int("queries", len(queries)))
+	return total, nil
+}
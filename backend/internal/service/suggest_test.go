@@ -0,0 +1,115 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	goredis "github.com/go-redis/redis/v8"
+	"github.com/wangn-tech/bookstore-go/internal/app/initializer/redis"
+	"github.com/wangn-tech/bookstore-go/internal/model"
+)
+
+func setupRedis(t *testing.T) {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	client := goredis.NewClient(&goredis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+	prev := redis.RedisClient
+	redis.RedisClient = client
+	t.Cleanup(func() { redis.RedisClient = prev })
+}
+
+func suggestWeight(t *testing.T, term suggestTerm) float64 {
+	t.Helper()
+	weight, err := redis.RedisClient.ZScore(context.Background(), suggestWeightKey, term.key()).Result()
+	if err != nil {
+		t.Fatalf("ZScore(%q): %v", term.key(), err)
+	}
+	return weight
+}
+
+func TestIndexBookWeight(t *testing.T) {
+	setupRedis(t)
+	ctx := context.Background()
+	s := &SuggestServiceImpl{}
+	author := suggestTerm{kind: SuggestTypeAuthor, text: "刘慈欣"}
+
+	a := &model.Book{ID: 1, Title: "三体", Author: "刘慈欣", Status: 1, Sale: 10}
+	b := &model.Book{ID: 2, Title: "球状闪电", Author: "刘慈欣", Status: 1, Sale: 5}
+	for _, book := range []*model.Book{a, b} {
+		if err := s.IndexBook(ctx, nil, book); err != nil {
+			t.Fatalf("IndexBook(%d): %v", book.ID, err)
+		}
+	}
+	if got := suggestWeight(t, author); got != 15 {
+		t.Fatalf("author weight = %v, want 15", got)
+	}
+
+	// 编辑时保留的词不按销量差调整, 销量变化只由订单支付、退款计入
+	edited := *a
+	edited.Title = "三体 I"
+	edited.Sale = 110
+	if err := s.IndexBook(ctx, a, &edited); err != nil {
+		t.Fatalf("IndexBook(edit): %v", err)
+	}
+	if got := suggestWeight(t, author); got != 15 {
+		t.Errorf("author weight after edit = %v, want 15", got)
+	}
+	if got := suggestWeight(t, suggestTerm{kind: SuggestTypeTitle, text: "三体 I"}); got != 110 {
+		t.Errorf("new title weight = %v, want 110", got)
+	}
+
+	// 删除时扣除的销量超过已计入的权重, 权重不低于 0
+	if err := s.IndexBook(ctx, &edited, nil); err != nil {
+		t.Fatalf("IndexBook(delete): %v", err)
+	}
+	if got := suggestWeight(t, author); got != 0 {
+		t.Errorf("author weight after delete = %v, want 0", got)
+	}
+
+	// 下架后作者不再被引用, 从索引中删除
+	offShelf := *b
+	offShelf.Status = 0
+	if err := s.IndexBook(ctx, b, &offShelf); err != nil {
+		t.Fatalf("IndexBook(off shelf): %v", err)
+	}
+	if err := redis.RedisClient.ZScore(ctx, suggestWeightKey, author.key()).Err(); err != goredis.Nil {
+		t.Errorf("author weight still exists after last book is off shelf: %v", err)
+	}
+}
+
+func TestSuggestWeightScript(t *testing.T) {
+	setupRedis(t)
+	ctx := context.Background()
+	keys := []string{suggestRefsKey, suggestWeightKey}
+	author := suggestTerm{kind: SuggestTypeAuthor, text: "刘慈欣"}
+
+	// 未索引的词不产生权重
+	if err := suggestWeightScript.Run(ctx, redis.RedisClient, keys, author.key(), 3).Err(); err != nil {
+		t.Fatalf("suggestWeightScript: %v", err)
+	}
+	if err := redis.RedisClient.ZScore(ctx, suggestWeightKey, author.key()).Err(); err != goredis.Nil {
+		t.Fatalf("unindexed term got a weight: %v", err)
+	}
+
+	s := &SuggestServiceImpl{}
+	if err := s.IndexBook(ctx, nil, &model.Book{ID: 1, Title: "三体", Author: "刘慈欣", Status: 1, Sale: 2}); err != nil {
+		t.Fatalf("IndexBook: %v", err)
+	}
+	for _, tt := range []struct {
+		delta int
+		want  float64
+	}{
+		{3, 5},
+		{-4, 1},
+		{-2, 0}, // 退款数量超过已计入的权重时归零
+	} {
+		if err := suggestWeightScript.Run(ctx, redis.RedisClient, keys, author.key(), tt.delta).Err(); err != nil {
+			t.Fatalf("suggestWeightScript(%d): %v", tt.delta, err)
+		}
+		if got := suggestWeight(t, author); got != tt.want {
+			t.Errorf("after %+d weight = %v, want %v", tt.delta, got, tt.want)
+		}
+	}
+}
@@ -3,19 +3,31 @@ package service
 import (
 	"context"
 	"errors"
+	"time"
 
 	"github.com/wangn-tech/bookstore-go/internal/api/request"
 	"github.com/wangn-tech/bookstore-go/internal/api/response"
+	"github.com/wangn-tech/bookstore-go/internal/app/constants"
+	"github.com/wangn-tech/bookstore-go/internal/app/initializer/database"
 	"github.com/wangn-tech/bookstore-go/internal/model"
 	"github.com/wangn-tech/bookstore-go/internal/repository"
 	"github.com/wangn-tech/bookstore-go/internal/utils"
+	"gorm.io/gorm"
 )
 
 type IUserService interface {
-	// Login 登录
-	Login(ctx context.Context, username, password string) (*response.UserLoginVO, error)
-	// Logout 登出
-	Logout(ctx context.Context, userID uint64) error
+	// Login 登录, 每次登录创建一个独立的设备会话
+	Login(ctx context.Context, username, password string, meta *utils.SessionMeta) (*response.UserLoginVO, error)
+	// Logout 登出当前会话
+	Logout(ctx context.Context, userID uint64, sessionID string) error
+	// ListSessions 获取用户的登录会话
+	ListSessions(ctx context.Context, userID uint64, currentSessionID string) ([]*response.SessionVO, error)
+	// RevokeSession 撤销用户的单个会话
+	RevokeSession(ctx context.Context, userID uint64, sessionID string) error
+	// RevokeAllSessions 撤销用户的全部会话
+	RevokeAllSessions(ctx context.Context, userID uint64) error
+	// RefreshToken 使用 refreshToken 轮换 token 对
+	RefreshToken(ctx context.Context, refreshToken string) (*utils.TokenResponse, error)
 	// Register 注册
 	Register(ctx context.Context, username, password, email, phone string) error
 	// GetUserByID 获取用户信息
@@ -26,18 +38,23 @@ type IUserService interface {
 	ChangePassword(ctx context.Context, userID uint64, oldPassword, newPassword string) error
 }
 
+// ErrSessionNotFound 会话不存在或不属于当前用户
+var ErrSessionNotFound = errors.New("会话不存在")
+
 type UserServiceImpl struct {
 	userDao *repository.UserDao
+	roleDao *repository.RoleDao
 }
 
-func NewUserService(userDao *repository.UserDao) IUserService {
+func NewUserService(userDao *repository.UserDao, roleDao *repository.RoleDao) IUserService {
 	return &UserServiceImpl{
 		userDao: userDao,
+		roleDao: roleDao,
 	}
 }
 
 // Login 用户登录
-func (u *UserServiceImpl) Login(ctx context.Context, username, password string) (*response.UserLoginVO, error) {
+func (u *UserServiceImpl) Login(ctx context.Context, username, password string, meta *utils.SessionMeta) (*response.UserLoginVO, error) {
 	// 获取 user 信息
 	user, err := u.userDao.GetUserByUsername(ctx, username)
 	if err != nil || user == nil {
@@ -48,8 +65,14 @@ func (u *UserServiceImpl) Login(ctx context.Context, username, password string)
 		return nil, errors.New("密码错误")
 	}
 
+	// 加载角色和权限, 写入 token
+	roles, permissions, err := u.getUserRolesAndPermissions(ctx, user.ID)
+	if err != nil {
+		return nil, errors.New("获取用户角色失败")
+	}
+
 	// 生成 JWT Token 对
-	tokenResponse, err := utils.GenerateTokenPair(user.ID, user.Username)
+	tokenResponse, err := utils.GenerateTokenPair(user.ID, user.Username, roles, permissions, meta)
 	if err != nil {
 		return nil, errors.New("生成 token 失败")
 	}
@@ -64,18 +87,77 @@ func (u *UserServiceImpl) Login(ctx context.Context, username, password string)
 			Username: user.Username,
 			Email:    user.Email,
 			Phone:    user.Phone,
+			Roles:    roles,
 		},
 	}, nil
 }
 
-func (u *UserServiceImpl) Logout(ctx context.Context, userID uint64) error {
-	// 调用 RevokeToken 函数，从 Redis 中删除用户的 token
-	if err := utils.RevokeToken(userID); err != nil {
+func (u *UserServiceImpl) Logout(ctx context.Context, userID uint64, sessionID string) error {
+	// 从 Redis 中删除当前会话, 其他设备的会话不受影响
+	if _, err := utils.RevokeSession(userID, sessionID); err != nil {
 		return err
 	}
 	return nil
 }
 
+// ListSessions 获取用户的登录会话, 标记当前会话
+func (u *UserServiceImpl) ListSessions(ctx context.Context, userID uint64, currentSessionID string) ([]*response.SessionVO, error) {
+	sessions, err := utils.ListSessions(userID)
+	if err != nil {
+		return nil, err
+	}
+	vos := make([]*response.SessionVO, 0, len(sessions))
+	for _, session := range sessions {
+		vos = append(vos, &response.SessionVO{
+			ID:         session.ID,
+			DeviceName: session.DeviceName,
+			IP:         session.IP,
+			UserAgent:  session.UserAgent,
+			CreatedAt:  time.Unix(session.CreatedAt, 0).Format("2006-01-02 15:04:05"),
+			LastSeen:   time.Unix(session.LastSeen, 0).Format("2006-01-02 15:04:05"),
+			Current:    session.ID == currentSessionID,
+		})
+	}
+	return vos, nil
+}
+
+// RevokeSession 撤销用户的单个会话
+func (u *UserServiceImpl) RevokeSession(ctx context.Context, userID uint64, sessionID string) error {
+	revoked, err := utils.RevokeSession(userID, sessionID)
+	if err != nil {
+		return err
+	}
+	if !revoked {
+		return ErrSessionNotFound
+	}
+	return nil
+}
+
+// RevokeAllSessions 撤销用户的全部会话
+func (u *UserServiceImpl) RevokeAllSessions(ctx context.Context, userID uint64) error {
+	return utils.RevokeAllSessions(userID)
+}
+
+// RefreshToken 使用 refreshToken 轮换 token 对
+//
+//	// 重新加载用户角色, 角色变更在刷新后生效
+func (u *UserServiceImpl) RefreshToken(ctx context.Context, refreshToken string) (*utils.TokenResponse, error) {
+	claims, err := utils.ParseRefreshToken(refreshToken)
+	if err != nil {
+		return nil, err
+	}
+	user, err := u.userDao.GetUserByID(ctx, claims.UserID)
+	if err != nil {
+		return nil, errors.New("用户不存在")
+	}
+	roles, permissions, err := u.getUserRolesAndPermissions(ctx, user.ID)
+	if err != nil {
+		return nil, errors.New("获取用户角色失败")
+	}
+	claims.Username = user.Username
+	return utils.RotateTokenPair(claims, refreshToken, roles, permissions)
+}
+
 // Register 用户注册
 func (u *UserServiceImpl) Register(ctx context.Context, username, password, email, phone string) error {
 	// 查询用户是否已存在 (username, phone, email)
@@ -159,7 +241,7 @@ func (u *UserServiceImpl) checkUserExists(ctx context.Context, username, phone,
 	return u.userDao.CheckUserExists(ctx, username, phone, email)
 }
 
-// createUser 创建新用户
+// createUser 创建新用户, 并分配默认角色 customer
 func (u *UserServiceImpl) createUser(ctx context.Context, username, hashedPassword, email, phone string) error {
 	user := &model.User{
 		Username: username,
@@ -167,5 +249,32 @@ func (u *UserServiceImpl) createUser(ctx context.Context, username, hashedPasswo
 		Email:    email,
 		Phone:    phone,
 	}
-	return u.userDao.CreateUser(ctx, user)
+	return database.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := u.userDao.CreateUserTx(ctx, tx, user); err != nil {
+			return err
+		}
+		return u.roleDao.AssignRoleByNameTx(ctx, tx, user.ID, constants.RoleCustomer)
+	})
+}
+
+// getUserRolesAndPermissions 获取用户的角色标识和去重后的权限标识
+func (u *UserServiceImpl) getUserRolesAndPermissions(ctx context.Context, userID uint64) ([]string, []string, error) {
+	userRoles, err := u.roleDao.GetUserRoles(ctx, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+	roles := make([]string, 0, len(userRoles))
+	permissions := make([]string, 0)
+	seen := make(map[string]struct{})
+	for _, role := range userRoles {
+		roles = append(roles, role.Name)
+		for _, perm := range role.Permissions {
+			if _, ok := seen[perm.Code]; ok {
+				continue
+			}
+			seen[perm.Code] = struct{}{}
+			permissions = append(permissions, perm.Code)
+		}
+	}
+	return roles, permissions, nil
 }
@@ -0,0 +1,115 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/wangn-tech/bookstore-go/internal/app/initializer/redis"
+	"github.com/wangn-tech/bookstore-go/internal/model"
+	"github.com/wangn-tech/bookstore-go/internal/repository"
+)
+
+// IViewHistoryService 浏览记录服务接口
+type IViewHistoryService interface {
+	// RecordView 记录一次图书详情浏览, 返回图书累计浏览次数; userID 为 0 (未登录) 时只计数, 不记录浏览历史
+	RecordView(ctx context.Context, userID uint64, bookID uint64) (int64, error)
+	// GetHistory 获取用户最近浏览的图书, 按浏览时间倒序, 返回图书的当前状态和价格 (含已下架图书)
+	GetHistory(ctx context.Context, userID uint64, limit int) ([]*model.Book, error)
+	// ClearHistory 清空用户的浏览历史
+	ClearHistory(ctx context.Context, userID uint64) error
+}
+
+// 浏览记录存储结构
+//
+//	// history:view:{用户ID}  list: 最近浏览的图书 ID, 最新的在表头, 同一本书只保留最近一次
+//	// book:views            zset: 图书 ID → 累计浏览次数 (含未登录用户)
+//	// rank:view:{yyyymmdd}  zset: 图书 ID → 当日浏览次数, 与畅销日榜保留期相同, 供热度排行按周期合并
+const (
+	viewHistoryKeyPrefix = "history:view:"
+	viewCountKey         = "book:views"
+	viewDayKeyPrefix     = "rank:view:"
+
+	// ViewHistoryMaxSize 每个用户保留的浏览记录数
+	ViewHistoryMaxSize = 100
+	// viewHistoryTTL 浏览历史在最后一次浏览后的保留时间
+	viewHistoryTTL = 90 * 24 * time.Hour
+)
+
+// viewHistoryKey 用户浏览历史
+func viewHistoryKey(userID uint64) string {
+	return fmt.Sprintf("%s%d", viewHistoryKeyPrefix, userID)
+}
+
+type ViewHistoryServiceImpl struct {
+	bookDao *repository.BookDao
+}
+
+func NewViewHistoryService(bookDao *repository.BookDao) IViewHistoryService {
+	return &ViewHistoryServiceImpl{
+		bookDao: bookDao,
+	}
+}
+
+// RecordView 记录浏览
+//
+//	// 浏览历史: 先删除已有的同一本书, 再插入表头并截断到上限, 在一个事务中执行
+func (v *ViewHistoryServiceImpl) RecordView(ctx context.Context, userID uint64, bookID uint64) (int64, error) {
+	member := strconv.FormatUint(bookID, 10)
+	today := startOfDay(time.Now())
+	dayKey := viewDayKeyPrefix + today.Format(rankDayLayout)
+
+	pipe := redis.RedisClient.TxPipeline()
+	if userID != 0 {
+		key := viewHistoryKey(userID)
+		pipe.LRem(ctx, key, 0, member)
+		pipe.LPush(ctx, key, member)
+		pipe.LTrim(ctx, key, 0, ViewHistoryMaxSize-1)
+		pipe.Expire(ctx, key, viewHistoryTTL)
+	}
+	total := pipe.ZIncrBy(ctx, viewCountKey, 1, member)
+	pipe.ZIncrBy(ctx, dayKey, 1, member)
+	pipe.ExpireAt(ctx, dayKey, today.AddDate(0, 0, rankRetentionDays))
+	if _, err := pipe.Exec(ctx); err != nil {
+		return 0, err
+	}
+	return int64(total.Val()), nil
+}
+
+// GetHistory 获取最近浏览的图书, 已删除的图书不返回
+func (v *ViewHistoryServiceImpl) GetHistory(ctx context.Context, userID uint64, limit int) ([]*model.Book, error) {
+	members, err := redis.RedisClient.LRange(ctx, viewHistoryKey(userID), 0, int64(limit-1)).Result()
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]uint64, 0, len(members))
+	for _, member := range members {
+		id, err := strconv.ParseUint(member, 10, 64)
+		if err != nil {
+			continue
+		}
+		ids = append(ids, id)
+	}
+
+	books, err := v.bookDao.GetBooksByIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[uint64]*model.Book, len(books))
+	for _, book := range books {
+		byID[book.ID] = book
+	}
+	history := make([]*model.Book, 0, len(ids))
+	for _, id := range ids {
+		if book := byID[id]; book != nil {
+			history = append(history, book)
+		}
+	}
+	return history, nil
+}
+
+// ClearHistory 清空浏览历史
+func (v *ViewHistoryServiceImpl) ClearHistory(ctx context.Context, userID uint64) error {
+	return redis.RedisClient.Del(ctx, viewHistoryKey(userID)).Err()
+}
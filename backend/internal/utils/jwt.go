@@ -2,21 +2,37 @@ package utils
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"errors"
-	"fmt"
+	"strconv"
 	"time"
 
+	goredis "github.com/go-redis/redis/v8"
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/wangn-tech/bookstore-go/internal/app/config"
 	"github.com/wangn-tech/bookstore-go/internal/app/constants"
 	"github.com/wangn-tech/bookstore-go/internal/app/initializer/redis"
 )
 
+var (
+	// ErrTokenRevoked token 已失效或被撤销
+	ErrTokenRevoked = errors.New("token已被撤销")
+	// ErrRefreshTokenReused 已轮换的 refreshToken 被再次使用, 视为被盗用
+	ErrRefreshTokenReused = errors.New("refresh token 重复使用")
+)
+
 // Claims 自定义 JWT 载荷结构体
+//
+//	// RegisteredClaims.ID (jti) 为会话 ID (即 token 家族 ID): 登录时生成, 刷新轮换时保持不变
+//	// Generation 为会话内的轮换代数, 每次刷新 +1
 type Claims struct {
-	UserID    uint64 `json:"user_id"`    // 用户 ID
-	Username  string `json:"username"`   // 用户名
-	TokenType string `json:"token_type"` // tokenType: access, refresh
+	UserID      uint64   `json:"user_id"`               // 用户 ID
+	Username    string   `json:"username"`              // 用户名
+	TokenType   string   `json:"token_type"`            // tokenType: access, refresh
+	Roles       []string `json:"roles,omitempty"`       // 角色列表
+	Permissions []string `json:"permissions,omitempty"` // 权限列表
+	Generation  int      `json:"gen"`                   // 轮换代数
 	jwt.RegisteredClaims
 }
 
@@ -27,44 +43,22 @@ type TokenResponse struct {
 	ExpiresIn    int64  `json:"expires_in"` // 过期时间, 单位秒
 }
 
-// GenerateTokenPair 生成一对 accessToken 和 refreshToken
-func GenerateTokenPair(userID uint64, username string) (*TokenResponse, error) {
-	// 生成 accessToken
-	accessClaims := Claims{
-		UserID:    userID,
-		Username:  username,
-		TokenType: constants.AccessToken,
-		RegisteredClaims: jwt.RegisteredClaims{
-			Issuer:    constants.Issuer,                                                // 签发者 			// 签发受众
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(constants.AccessTokenExpire)), // 过期时间
-			IssuedAt:  jwt.NewNumericDate(time.Now()),                                  // 签发时间
-			NotBefore: jwt.NewNumericDate(time.Now()),                                  // 生效时间
-		},
-	}
-	accessToken, err := jwt.NewWithClaims(jwt.SigningMethodHS256, accessClaims).SignedString([]byte(config.AppConf.JWT.Secret))
+// GenerateTokenPair 生成一对 accessToken 和 refreshToken, 并创建新的登录会话
+//
+//	// roles, permissions 写入载荷, 角色变更在下次签发 token 后生效
+//	// 每次登录对应一个独立会话, 不影响同一用户在其他设备上的会话
+func GenerateTokenPair(userID uint64, username string, roles []string, permissions []string, meta *SessionMeta) (*TokenResponse, error) {
+	sessionID, err := newTokenID()
 	if err != nil {
 		return nil, err
 	}
-
-	// 生成 refreshToken
-	refreshClaims := Claims{
-		UserID:    userID,
-		Username:  username,
-		TokenType: constants.RefreshToken,
-		RegisteredClaims: jwt.RegisteredClaims{
-			Issuer:    constants.Issuer,                                                 // 签发者 			// 签发受众
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(constants.RefreshTokenExpire)), // 过期时间
-			IssuedAt:  jwt.NewNumericDate(time.Now()),                                   // 签发时间
-			NotBefore: jwt.NewNumericDate(time.Now()),                                   // 生效时间
-		},
-	}
-	refreshToken, err := jwt.NewWithClaims(jwt.SigningMethodHS256, refreshClaims).SignedString([]byte(config.AppConf.JWT.Secret))
+	accessToken, refreshToken, err := signTokenPair(userID, username, roles, permissions, sessionID, 0)
 	if err != nil {
 		return nil, err
 	}
 
-	// 将 token 缓存到 Redis
-	if err := StoreTokenInRedis(userID, accessToken, refreshToken); err != nil {
+	// 将 token 和设备信息缓存到 Redis
+	if err := storeSession(context.Background(), userID, sessionID, accessToken, refreshToken, meta); err != nil {
 		return nil, err
 	}
 
@@ -75,32 +69,53 @@ func GenerateTokenPair(userID uint64, username string) (*TokenResponse, error) {
 	}, nil
 }
 
-func StoreTokenInRedis(userID uint64, accessToken string, refreshToken string) error {
-	ctx := context.Background()
+// signTokenPair 签发同一家族、同一代数的 accessToken 和 refreshToken
+func signTokenPair(userID uint64, username string, roles []string, permissions []string, sessionID string, gen int) (string, string, error) {
+	now := time.Now()
+	newClaims := func(tokenType string, expire time.Duration) Claims {
+		return Claims{
+			UserID:      userID,
+			Username:    username,
+			TokenType:   tokenType,
+			Roles:       roles,
+			Permissions: permissions,
+			Generation:  gen,
+			RegisteredClaims: jwt.RegisteredClaims{
+				ID:        sessionID,                           // 会话 ID
+				Issuer:    constants.Issuer,                    // 签发者
+				ExpiresAt: jwt.NewNumericDate(now.Add(expire)), // 过期时间
+				IssuedAt:  jwt.NewNumericDate(now),             // 签发时间
+				NotBefore: jwt.NewNumericDate(now),             // 生效时间
+			},
+		}
+	}
+	secret := []byte(config.AppConf.JWT.Secret)
 
-	// 使用 hash 存储用户 token 信息
-	userKey := fmt.Sprintf("user_tokens:%d", userID)
-	err := redis.RedisClient.HSet(ctx, userKey, map[string]interface{}{
-		"access_token":  accessToken,
-		"refresh_token": refreshToken,
-		"created_at":    time.Now().Unix(),
-	}).Err()
+	// 生成 accessToken
+	accessToken, err := jwt.NewWithClaims(jwt.SigningMethodHS256, newClaims(constants.AccessToken, constants.AccessTokenExpire)).SignedString(secret)
 	if err != nil {
-		return err
+		return "", "", err
 	}
-
-	// 设置过期时间为 refresh_token 的过期时间
-	err = redis.RedisClient.Expire(ctx, userKey, constants.RefreshTokenExpire).Err()
+	// 生成 refreshToken
+	refreshToken, err := jwt.NewWithClaims(jwt.SigningMethodHS256, newClaims(constants.RefreshToken, constants.RefreshTokenExpire)).SignedString(secret)
 	if err != nil {
-		return err
+		return "", "", err
 	}
+	return accessToken, refreshToken, nil
+}
 
-	return nil
+// newTokenID 生成随机的会话 ID
+func newTokenID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
 }
 
 // GenerateToken 兼容旧接口, 返回 accessToken
-func GenerateToken(userID uint64, username string) (string, error) {
-	tokenResponse, err := GenerateTokenPair(userID, username)
+func GenerateToken(userID uint64, username string, roles []string, permissions []string) (string, error) {
+	tokenResponse, err := GenerateTokenPair(userID, username, roles, permissions, nil)
 	if err != nil {
 		return "", err
 	}
@@ -109,77 +124,132 @@ func GenerateToken(userID uint64, username string) (string, error) {
 
 // ParseToken 解析和校验JWT Token
 func ParseToken(tokenString string) (*Claims, error) {
+	claims, err := parseClaims(tokenString)
+	if err != nil {
+		return nil, err
+	}
+	// 检查token所属会话是否在Redis中有效
+	if !IsTokenValidInRedis(claims.UserID, claims.ID, tokenString, claims.TokenType) {
+		return nil, ErrTokenRevoked
+	}
+	return claims, nil
+}
+
+// ParseRefreshToken 解析 refreshToken, 只校验签名、有效期和类型
+//
+//	// 不校验 Redis, 由 RotateTokenPair 原子地完成校验和轮换
+func ParseRefreshToken(tokenString string) (*Claims, error) {
+	claims, err := parseClaims(tokenString)
+	if err != nil {
+		return nil, err
+	}
+	if claims.TokenType != constants.RefreshToken || claims.ID == "" {
+		return nil, errors.New("无效的刷新token")
+	}
+	return claims, nil
+}
+
+// parseClaims 校验签名和有效期, 解析载荷
+func parseClaims(tokenString string) (*Claims, error) {
 	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (any, error) {
 		return []byte(config.AppConf.JWT.Secret), nil
-	})
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}))
 	if err != nil {
 		return nil, err
 	}
 	if claims, ok := token.Claims.(*Claims); ok && token.Valid {
-		// 检查token是否在Redis中被撤销
-		if !IsTokenValidInRedis(claims.UserID, tokenString, claims.TokenType) {
-			return nil, errors.New("token已被撤销")
-		}
 		return claims, nil
 	}
 	return nil, errors.New("invalid token")
 }
 
-// IsTokenValidInRedis 检查token是否在Redis中有效
-func IsTokenValidInRedis(userID uint64, token string, tokenType string) bool {
+// IsTokenValidInRedis 检查token是否与会话记录中的最新token一致
+func IsTokenValidInRedis(userID uint64, sessionID string, token string, tokenType string) bool {
+	if sessionID == "" {
+		return false
+	}
 	ctx := context.Background()
-	userKey := fmt.Sprintf("user_tokens:%d", userID)
-
-	var redisToken string
-	var err error
 
+	field := "refresh_token"
 	if tokenType == constants.AccessToken {
-		redisToken, err = redis.RedisClient.HGet(ctx, userKey, "access_token").Result()
-	} else {
-		redisToken, err = redis.RedisClient.HGet(ctx, userKey, "refresh_token").Result()
+		field = "access_token"
 	}
-
-	if err != nil {
+	values, err := redis.RedisClient.HMGet(ctx, sessionKey(sessionID), "user_id", field).Result()
+	if err != nil || values[0] == nil || values[1] == nil {
 		return false
 	}
 
-	return redisToken == token
+	return values[0] == strconv.FormatUint(userID, 10) && values[1] == token
 }
 
-// RefreshAccessToken 使用刷新token生成新的访问token
-func RefreshAccessToken(refreshToken string) (*TokenResponse, error) {
-	// 解析刷新token
-	claims, err := ParseToken(refreshToken)
+// rotateTokenScript 原子地校验并轮换 token 对
+//
+//	// 返回 1: 轮换成功, 会话记录和用户会话集合一并续期, 集合不会先于仍在轮换的会话过期
+//	// 返回 0: 会话已失效 (已登出、已撤销或已过期)
+//	// 返回 -1: 会话有效但 refreshToken 不是最新一代, 即已轮换的 token 被重复使用, 撤销整个会话
+var rotateTokenScript = goredis.NewScript(`
+local userID = redis.call('HGET', KEYS[1], 'user_id')
+if not userID or userID ~= ARGV[1] then
+	return 0
+end
+if redis.call('HGET', KEYS[1], 'refresh_token') ~= ARGV[2] then
+	redis.call('DEL', KEYS[1])
+	redis.call('SREM', KEYS[2], ARGV[6])
+	return -1
+end
+redis.call('HSET', KEYS[1], 'access_token', ARGV[3], 'refresh_token', ARGV[4], 'last_seen', ARGV[7])
+redis.call('EXPIRE', KEYS[1], ARGV[5])
+redis.call('SADD', KEYS[2], ARGV[6])
+redis.call('EXPIRE', KEYS[2], ARGV[5])
+return 1
+`)
+
+// RotateTokenPair 使用 refreshToken 轮换 token 对
+//
+//	// 每个 refreshToken 只能使用一次; 已轮换的 refreshToken 再次出现时撤销整个会话
+func RotateTokenPair(claims *Claims, refreshToken string, roles []string, permissions []string) (*TokenResponse, error) {
+	accessToken, newRefreshToken, err := signTokenPair(claims.UserID, claims.Username, roles, permissions, claims.ID, claims.Generation+1)
 	if err != nil {
 		return nil, err
 	}
 
-	if claims.TokenType != constants.RefreshToken {
-		return nil, errors.New("无效的刷新token")
-	}
-
-	// 生成新的token对
-	return GenerateTokenPair(claims.UserID, claims.Username)
-}
-
-// RevokeToken 撤销用户的所有token
-func RevokeToken(userID uint64) error {
 	ctx := context.Background()
-	userKey := fmt.Sprintf("user_tokens:%d", userID)
-	return redis.RedisClient.Del(ctx, userKey).Err()
+	keys := []string{sessionKey(claims.ID), userSessionsKey(claims.UserID)}
+	res, err := rotateTokenScript.Run(ctx, redis.RedisClient, keys,
+		claims.UserID, refreshToken, accessToken, newRefreshToken,
+		int64(constants.RefreshTokenExpire.Seconds()), claims.ID, time.Now().Unix(),
+	).Int()
+	if err != nil {
+		return nil, err
+	}
+	switch res {
+	case 1:
+		return &TokenResponse{
+			AccessToken:  accessToken,
+			RefreshToken: newRefreshToken,
+			ExpiresIn:    int64(constants.AccessTokenExpire.Seconds()),
+		}, nil
+	case -1:
+		return nil, ErrRefreshTokenReused
+	default:
+		return nil, ErrTokenRevoked
+	}
 }
 
 // RevokeAllUserTokens 撤销所有用户的token（用于安全事件）
 func RevokeAllUserTokens() error {
 	ctx := context.Background()
-	// 删除所有用户token
-	keys, err := redis.RedisClient.Keys(ctx, "user_tokens:*").Result()
-	if err != nil {
-		return err
-	}
-
-	if len(keys) > 0 {
-		return redis.RedisClient.Del(ctx, keys...).Err()
+	// 删除所有会话
+	for _, pattern := range []string{sessionKeyPrefix + "*", userSessionKeyPrefix + "*"} {
+		keys, err := redis.RedisClient.Keys(ctx, pattern).Result()
+		if err != nil {
+			return err
+		}
+		if len(keys) > 0 {
+			if err := redis.RedisClient.Del(ctx, keys...).Err(); err != nil {
+				return err
+			}
+		}
 	}
 	return nil
 }
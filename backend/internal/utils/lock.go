@@ -0,0 +1,35 @@
+package utils
+
+import (
+	"context"
+	"time"
+
+	goredis "github.com/go-redis/redis/v8"
+	"github.com/wangn-tech/bookstore-go/internal/app/initializer/redis"
+)
+
+// unlockScript 仅当锁仍由自己持有时释放, 避免误删其他实例在锁过期后获得的锁
+var unlockScript = goredis.NewScript(`
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+	return redis.call('DEL', KEYS[1])
+end
+return 0
+`)
+
+// TryLock 尝试获取 Redis 分布式锁, 返回持有者 token, 用于多副本部署时的互斥
+func TryLock(ctx context.Context, key string, ttl time.Duration) (string, bool, error) {
+	token, err := newTokenID()
+	if err != nil {
+		return "", false, err
+	}
+	ok, err := redis.RedisClient.SetNX(ctx, key, token, ttl).Result()
+	if err != nil {
+		return "", false, err
+	}
+	return token, ok, nil
+}
+
+// Unlock 释放 TryLock 获取的锁
+func Unlock(ctx context.Context, key string, token string) error {
+	return unlockScript.Run(ctx, redis.RedisClient, []string{key}, token).Err()
+}
@@ -0,0 +1,162 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	goredis "github.com/go-redis/redis/v8"
+	"github.com/wangn-tech/bookstore-go/internal/app/constants"
+	"github.com/wangn-tech/bookstore-go/internal/app/initializer/redis"
+)
+
+// 会话存储结构
+//
+//	// user_session:{sessionID} hash: 单个登录会话 (设备) 的 token 和设备信息, 过期时间同 refreshToken
+//	// user_sessions:{userID}   set:  用户的全部会话 ID
+const (
+	sessionKeyPrefix     = "user_session:"
+	userSessionKeyPrefix = "user_sessions:"
+)
+
+// SessionMeta 登录设备信息
+type SessionMeta struct {
+	DeviceName string // 设备名称
+	IP         string // 登录 IP
+	UserAgent  string // User-Agent
+}
+
+// Session 登录会话
+type Session struct {
+	ID         string `json:"id"`          // 会话 ID, 即 JWT jti
+	UserID     uint64 `json:"user_id"`     // 用户 ID
+	DeviceName string `json:"device_name"` // 设备名称
+	IP         string `json:"ip"`          // 登录 IP
+	UserAgent  string `json:"user_agent"`  // User-Agent
+	CreatedAt  int64  `json:"created_at"`  // 创建时间 (unix 秒)
+	LastSeen   int64  `json:"last_seen"`   // 最近活跃时间 (unix 秒)
+}
+
+func sessionKey(sessionID string) string {
+	return sessionKeyPrefix + sessionID
+}
+
+func userSessionsKey(userID uint64) string {
+	return fmt.Sprintf("%s%d", userSessionKeyPrefix, userID)
+}
+
+// storeSession 创建会话记录
+func storeSession(ctx context.Context, userID uint64, sessionID string, accessToken, refreshToken string, meta *SessionMeta) error {
+	if meta == nil {
+		meta = &SessionMeta{}
+	}
+	now := time.Now().Unix()
+	key := sessionKey(sessionID)
+	_, err := redis.RedisClient.TxPipelined(ctx, func(pipe goredis.Pipeliner) error {
+		pipe.HSet(ctx, key, map[string]interface{}{
+			"user_id":       userID,
+			"access_token":  accessToken,
+			"refresh_token": refreshToken,
+			"device_name":   meta.DeviceName,
+			"ip":            meta.IP,
+			"user_agent":    meta.UserAgent,
+			"created_at":    now,
+			"last_seen":     now,
+		})
+		// 设置过期时间为 refresh_token 的过期时间
+		pipe.Expire(ctx, key, constants.RefreshTokenExpire)
+		pipe.SAdd(ctx, userSessionsKey(userID), sessionID)
+		pipe.Expire(ctx, userSessionsKey(userID), constants.RefreshTokenExpire)
+		return nil
+	})
+	return err
+}
+
+// touchSessionScript 会话存在时才更新最近活跃时间, 避免与登出、撤销并发时重建出没有过期时间的会话记录
+var touchSessionScript = goredis.NewScript(`
+if redis.call('EXISTS', KEYS[1]) == 1 then
+	return redis.call('HSET', KEYS[1], 'last_seen', ARGV[1])
+end
+return 0
+`)
+
+// TouchSession 更新会话最近活跃时间, 会话已不存在时不做处理
+func TouchSession(sessionID string) error {
+	ctx := context.Background()
+	return touchSessionScript.Run(ctx, redis.RedisClient, []string{sessionKey(sessionID)}, time.Now().Unix()).Err()
+}
+
+// ListSessions 获取用户的全部有效会话, 按最近活跃时间倒序
+func ListSessions(userID uint64) ([]*Session, error) {
+	ctx := context.Background()
+	sessionIDs, err := redis.RedisClient.SMembers(ctx, userSessionsKey(userID)).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	sessions := make([]*Session, 0, len(sessionIDs))
+	var expired []interface{}
+	for _, sessionID := range sessionIDs {
+		fields, err := redis.RedisClient.HGetAll(ctx, sessionKey(sessionID)).Result()
+		if err != nil {
+			return nil, err
+		}
+		// 会话记录已过期, 从集合中清理
+		if len(fields) == 0 {
+			expired = append(expired, sessionID)
+			continue
+		}
+		createdAt, _ := strconv.ParseInt(fields["created_at"], 10, 64)
+		lastSeen, _ := strconv.ParseInt(fields["last_seen"], 10, 64)
+		sessions = append(sessions, &Session{
+			ID:         sessionID,
+			UserID:     userID,
+			DeviceName: fields["device_name"],
+			IP:         fields["ip"],
+			UserAgent:  fields["user_agent"],
+			CreatedAt:  createdAt,
+			LastSeen:   lastSeen,
+		})
+	}
+	if len(expired) > 0 {
+		redis.RedisClient.SRem(ctx, userSessionsKey(userID), expired...)
+	}
+
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].LastSeen > sessions[j].LastSeen
+	})
+	return sessions, nil
+}
+
+// RevokeSession 撤销用户的单个会话, 会话不属于该用户时返回 false
+func RevokeSession(userID uint64, sessionID string) (bool, error) {
+	ctx := context.Background()
+	removed, err := redis.RedisClient.SRem(ctx, userSessionsKey(userID), sessionID).Result()
+	if err != nil {
+		return false, err
+	}
+	if removed == 0 {
+		return false, nil
+	}
+	if err := redis.RedisClient.Del(ctx, sessionKey(sessionID)).Err(); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// RevokeAllSessions 撤销用户的全部会话
+func RevokeAllSessions(userID uint64) error {
+	ctx := context.Background()
+	sessionIDs, err := redis.RedisClient.SMembers(ctx, userSessionsKey(userID)).Result()
+	if err != nil {
+		return err
+	}
+	keys := make([]string, 0, len(sessionIDs)+1)
+	for _, sessionID := range sessionIDs {
+		keys = append(keys, sessionKey(sessionID))
+	}
+	keys = append(keys, userSessionsKey(userID))
+	return redis.RedisClient.Del(ctx, keys...).Err()
+}
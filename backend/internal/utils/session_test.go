@@ -0,0 +1,70 @@
+package utils
+
+import (
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	goredis "github.com/go-redis/redis/v8"
+	"github.com/wangn-tech/bookstore-go/internal/app/config"
+	"github.com/wangn-tech/bookstore-go/internal/app/constants"
+	"github.com/wangn-tech/bookstore-go/internal/app/initializer/redis"
+)
+
+func setupRedis(t *testing.T) *miniredis.Miniredis {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	client := goredis.NewClient(&goredis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+
+	prevClient, prevConf := redis.RedisClient, config.AppConf
+	redis.RedisClient = client
+	config.AppConf = &config.Config{JWT: config.JWTConfig{Secret: "test-secret"}}
+	t.Cleanup(func() { redis.RedisClient, config.AppConf = prevClient, prevConf })
+	return mr
+}
+
+// TestRotateKeepsUserSessions 会话持续轮换超过登录时的有效期后, 仍可列出和撤销
+func TestRotateKeepsUserSessions(t *testing.T) {
+	mr := setupRedis(t)
+	const userID = 42
+
+	tokens, err := GenerateTokenPair(userID, "alice", nil, nil, nil)
+	if err != nil {
+		t.Fatalf("GenerateTokenPair: %v", err)
+	}
+	// 在登录有效期内分两次轮换, 累计时间超过登录时设置的有效期
+	for i := 0; i < 2; i++ {
+		mr.FastForward(constants.RefreshTokenExpire - time.Hour)
+		claims, err := ParseRefreshToken(tokens.RefreshToken)
+		if err != nil {
+			t.Fatalf("ParseRefreshToken: %v", err)
+		}
+		tokens, err = RotateTokenPair(claims, tokens.RefreshToken, nil, nil)
+		if err != nil {
+			t.Fatalf("RotateTokenPair #%d: %v", i+1, err)
+		}
+	}
+
+	claims, err := ParseToken(tokens.AccessToken)
+	if err != nil {
+		t.Fatalf("ParseToken: %v", err)
+	}
+	if !IsTokenValidInRedis(userID, claims.ID, tokens.AccessToken, constants.AccessToken) {
+		t.Fatal("rotated access token is not valid")
+	}
+	sessions, err := ListSessions(userID)
+	if err != nil {
+		t.Fatalf("ListSessions: %v", err)
+	}
+	if len(sessions) != 1 {
+		t.Fatalf("ListSessions returned %d sessions, want 1", len(sessions))
+	}
+
+	if err := RevokeAllSessions(userID); err != nil {
+		t.Fatalf("RevokeAllSessions: %v", err)
+	}
+	if IsTokenValidInRedis(userID, claims.ID, tokens.AccessToken, constants.AccessToken) {
+		t.Error("access token is still valid after RevokeAllSessions")
+	}
+}
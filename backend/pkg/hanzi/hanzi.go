@@ -0,0 +1,83 @@
+// Package hanzi 中文文本归一化: 繁体转简体、汉字转拼音, 用于生成图书检索键
+package hanzi
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/mozillazg/go-pinyin"
+)
+
+// pinyinArgs 不带声调的拼音, 多音字取常用读音; 非汉字原样保留
+var pinyinArgs = func() pinyin.Args {
+	args := pinyin.NewArgs()
+	args.Style = pinyin.Normal
+	args.Fallback = func(r rune, a pinyin.Args) []string {
+		return []string{string(r)}
+	}
+	return args
+}()
+
+// Pinyin 返回全拼和拼音首字母, 均为小写且去除空白和标点,
+// 如 "三体" → ("santi", "st"); 非汉字的字母数字原样计入全拼和首字母
+func Pinyin(s string) (full string, initials string) {
+	var fullSB, initialsSB strings.Builder
+	for _, r := range ToSimplified(s) {
+		if unicode.Is(unicode.Han, r) {
+			syllables := pinyin.SinglePinyin(r, pinyinArgs)
+			if len(syllables) == 0 || syllables[0] == string(r) {
+				continue
+			}
+			fullSB.WriteString(syllables[0])
+			initialsSB.WriteByte(syllables[0][0])
+			continue
+		}
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			lower := unicode.ToLower(r)
+			fullSB.WriteRune(lower)
+			initialsSB.WriteRune(lower)
+		}
+	}
+	return fullSB.String(), initialsSB.String()
+}
+
+// Normalize 检索归一化: 繁体转简体、转小写、合并连续空白
+func Normalize(s string) string {
+	return strings.ToLower(strings.Join(strings.Fields(ToSimplified(s)), " "))
+}
+
+// CompactPinyin 将用户输入的拼音归一化为检索键格式: 小写并去除空白和分隔符, 如 "San Ti" → "santi"
+func CompactPinyin(s string) string {
+	var sb strings.Builder
+	for _, r := range strings.ToLower(s) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			sb.WriteRune(r)
+		}
+	}
+	return sb.String()
+}
+
+// SearchKeys 由多个字段 (书名、作者等) 生成检索键: 每个字段的简体文本、全拼和拼音首字母,
+// 以空格分隔并去重. 繁体书名以简体入键, 配合检索词归一化即可繁简互查
+func SearchKeys(fields ...string) string {
+	seen := make(map[string]bool)
+	var keys []string
+	add := func(key string) {
+		if key != "" && !seen[key] {
+			seen[key] = true
+			keys = append(keys, key)
+		}
+	}
+	for _, field := range fields {
+		normalized := Normalize(field)
+		if normalized == "" {
+			continue
+		}
+		// 简体文本中的空格替换掉, 保证一个字段对应一个检索词
+		add(strings.ReplaceAll(normalized, " ", ""))
+		full, initials := Pinyin(normalized)
+		add(full)
+		add(initials)
+	}
+	return strings.Join(keys, " ")
+}
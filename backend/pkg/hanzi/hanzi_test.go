@@ -0,0 +1,103 @@
+package hanzi
+
+import "testing"
+
+func TestPinyin(t *testing.T) {
+	tests := []struct {
+		in           string
+		wantFull     string
+		wantInitials string
+	}{
+		{"三体", "santi", "st"},
+		{"刘慈欣", "liucixin", "lcx"},
+		{"三體", "santi", "st"}, // 繁体先转简体
+		// 字母数字原样保留并转小写, 空白和标点去除
+		{"C++ Primer 中文版", "cprimerzhongwenban", "cprimerzwb"},
+		{"1984", "1984", "1984"},
+		{"三体 II: 黑暗森林", "santiiiheiansenlin", "stiihasl"},
+		// 多音字按字取字典中的第一个读音, 不做词语级别的判断
+		{"重庆", "zhongqing", "zq"},
+		{"长安", "zhangan", "za"},
+		{"银行", "yinxing", "yx"},
+		{"音乐", "yinle", "yl"},
+		{"", "", ""},
+	}
+	for _, tt := range tests {
+		full, initials := Pinyin(tt.in)
+		if full != tt.wantFull || initials != tt.wantInitials {
+			t.Errorf("Pinyin(%q) = (%q, %q), want (%q, %q)", tt.in, full, initials, tt.wantFull, tt.wantInitials)
+		}
+	}
+}
+
+func TestToSimplified(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"三體", "三体"},
+		{"紅樓夢", "红楼梦"},
+		{"鋼鐵是怎樣煉成的", "钢铁是怎样炼成的"},
+		{"Harry Potter 與魔法石", "Harry Potter 与魔法石"},
+		{"三体", "三体"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := ToSimplified(tt.in); got != tt.want {
+			t.Errorf("ToSimplified(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"  The   Three-Body  Problem ", "the three-body problem"},
+		{"三體 黑暗森林", "三体 黑暗森林"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := Normalize(tt.in); got != tt.want {
+			t.Errorf("Normalize(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestCompactPinyin(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"San Ti", "santi"},
+		{"liu-ci-xin", "liucixin"},
+		{"  LCX ", "lcx"},
+	}
+	for _, tt := range tests {
+		if got := CompactPinyin(tt.in); got != tt.want {
+			t.Errorf("CompactPinyin(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestSearchKeys(t *testing.T) {
+	tests := []struct {
+		fields []string
+		want   string
+	}{
+		{[]string{"三体", "刘慈欣"}, "三体 santi st 刘慈欣 liucixin lcx"},
+		// 繁体字段以简体入键
+		{[]string{"三體"}, "三体 santi st"},
+		// 纯 ASCII 字段的简体文本与全拼、首字母相同, 去重后只保留一个
+		{[]string{"1984", "George Orwell"}, "1984 georgeorwell"},
+		// 重复字段和空字段不产生检索键
+		{[]string{"三体", "", "三体"}, "三体 santi st"},
+		{nil, ""},
+	}
+	for _, tt := range tests {
+		if got := SearchKeys(tt.fields...); got != tt.want {
+			t.Errorf("SearchKeys(%q) = %q, want %q", tt.fields, got, tt.want)
+		}
+	}
+}
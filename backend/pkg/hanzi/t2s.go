@@ -0,0 +1,54 @@
+package hanzi
+
+import "strings"
+
+// t2sPairs 常用繁体字 → 简体字对照, 每项为 "繁简" 两个字
+//
+//	// 只收录图书标题、作者名中的常用字, 按字逐一转换, 不处理词语级别的差异 (如 "乾" 在 "乾隆" 中不转换)
+var t2sPairs = []string{
+	// 一至十画部件常见字
+	"萬万 與与 醜丑 專专 業业 叢丛 東东 絲丝 兩两 嚴严 喪丧 個个 豐丰 臨临 為为 麗丽 舉举 麼么 義义 烏乌 樂乐 喬乔 習习 鄉乡 書书 買买 亂乱 爭争 於于 虧亏 雲云 亞亚 產产 畝亩 親亲 褻亵 億亿 僅仅 從从 侖仑 倉仓 儀仪 們们 價价 眾众 優优 會会 傘伞 偉伟 傳传 傷伤 倫伦 偽伪 體体 餘余 傭佣 僉佥 俠侠 侶侣 僥侥 偵侦 側侧 僑侨 儈侩 儕侪 儂侬 俁俣 係系 儔俦 儼俨 倆俩 儷俪 儉俭 債债 傾倾 僂偻 僨偾 償偿 儻傥 儐傧 儲储 儺傩",
+	"兒儿 兌兑 黨党 蘭兰 關关 興兴 茲兹 養养 獸兽 內内 岡冈 冊册 寫写 軍军 農农 馮冯 衝冲 決决 況况 凍冻 淨净 涼凉 減减 湊凑 凜凛 幾几 鳳凤 憑凭 凱凯 擊击 鑿凿 芻刍 劃划 劉刘 則则 剛刚 創创 刪删 別别 剗刬 剄刭 劊刽 劌刿 劍剑 劑剂 勸劝 辦办 務务 動动 勵励 勁劲 勞劳 勢势 勳勋 勻匀 匯汇 區区 醫医 華华 協协 單单 賣卖 盧卢 鹵卤 衛卫 卻却 廠厂 廳厅 歷历 厲厉 壓压 厭厌 厙厍 廁厕 廂厢 厴厣 廈厦 廚厨 廄厩 廝厮 縣县 參参 雙双 發发 變变 敘叙 疊叠 葉叶 號号 嘆叹 嘰叽 嚇吓 呂吕 嗎吗 噸吨 聽听 啟启 吳吴 嘸呒 囈呓 嘔呕 嚦呖 唄呗 員员 嗆呛 嗚呜 詠咏 嚨咙 嚀咛 噝咝 響响 啞哑 噠哒 嘵哓 嗶哔 噦哕 嘩哗 噲哙 嚌哜 噥哝 喲哟 嘜唛 嘮唠 嗩唢 喚唤 嘖啧 嗇啬 囀啭 齧啮 嘯啸 噴喷 嘍喽 嚳喾 囁嗫 噯嗳 噓嘘 嚶嘤 囑嘱 嚕噜 囂嚣",
+	"團团 園园 囪囱 圍围 圇囵 國国 圖图 圓圆 聖圣 壙圹 場场 壞坏 塊块 堅坚 壇坛 壢坜 壩坝 塢坞 墳坟 墜坠 壟垄 壠垅 壚垆 壘垒 墾垦 堊垩 墊垫 埡垭 塏垲 塒埘 塤埙 堝埚 塹堑 墮堕 壪塆 牆墙 壯壮 聲声 殼壳 壺壶 處处 備备 復复 夠够 頭头 誇夸 夾夹 奪夺 奩奁 奐奂 奮奋 獎奖 奧奥 妝妆 婦妇 媽妈 嫵妩 嫗妪 姍姗 薑姜 婁娄 婭娅 嬈娆 嬌娇 孌娈 娛娱 媧娲 嫻娴 嬰婴 嬋婵 嬸婶 媼媪 嬡嫒 嬪嫔 嬙嫱 嬤嬷 孫孙 學学 孿孪 寧宁 寶宝 實实 寵宠 審审 憲宪 宮宫 寬宽 賓宾 寢寝 對对 尋寻 導导 壽寿 將将 爾尔 塵尘 堯尧 尷尴 屍尸 盡尽 層层 屜屉 屆届 屬属 屢屡 屨屦 嶼屿 歲岁 豈岂 嶇岖 崗岗 峴岘 嵐岚 島岛 嶺岭 崬岽 巋岿 嶧峄 峽峡 嶠峤 崢峥 嶗崂 崳嵛 嶄崭 嶸嵘 巔巅 鞏巩 幣币 帥帅 師师 幃帏 帳帐 簾帘 幟帜 帶带 幀帧 幫帮 幬帱 幗帼 幘帻 幹干 並并 廣广 莊庄 慶庆 廬庐 廡庑 庫库 應应 廟庙 龐庞 廢废 廩廪 開开 異异 棄弃 弔吊 張张 彌弥 彎弯 彈弹 強强 歸归 當当 錄录 彙汇 彥彦 徹彻 徑径 徠徕",
+	"憶忆 懺忏 憂忧 愾忾 懷怀 態态 慫怂 憮怃 慪怄 悵怅 愴怆 憐怜 總总 懟怼 懌怿 戀恋 懇恳 惡恶 慟恸 懨恹 愷恺 惻恻 惱恼 惲恽 悅悦 懸悬 慳悭 悞悮 憫悯 驚惊 懼惧 慘惨 懲惩 憊惫 愜惬 慚惭 憚惮 慣惯 愨悫 慍愠 憤愤 憒愦 願愿 懾慑 懣懑 懶懒 懍懔 戇戆 戔戋 戲戏 戧戗 戰战 戩戬 戶户 紮扎 撲扑 執执 擴扩 捫扪 掃扫 揚扬 擾扰 撫抚 拋抛 摶抟 摳抠 掄抡 搶抢 護护 報报 擔担 擬拟 攏拢 揀拣 擁拥 攔拦 擰拧 撥拨 擇择 掛挂 摯挚 攣挛 掗挜 撾挝 撻挞 挾挟 撓挠 擋挡 撟挢 掙挣 擠挤 揮挥 撏挦 撈捞 損损 撿捡 換换 搗捣 據据 擄掳 摑掴 擲掷 撣掸 摻掺 摜掼 攬揽 撳揿 攙搀 擱搁 摟搂 攪搅 攜携 攝摄 攄摅 擺摆 搖摇 擯摈 攤摊 攖撄 撐撑 攆撵 擷撷 擼撸 攛撺 擻擞 攢攒 敵敌 數数 齋斋 斕斓 鬥斗 斬斩 斷断 無无 舊旧 時时 曠旷 暘旸 曇昙 晝昼 顯显 晉晋 曬晒 曉晓 曄晔 暈晕 暉晖 暫暂 曖暧 術术 樸朴 機机 殺杀 雜杂 權权 條条 來来 楊杨 榪杩 傑杰 極极 構构 樅枞 樞枢 棗枣 櫪枥 梘枧 棖枨 槍枪 楓枫 梟枭 櫃柜 檸柠 檉柽 梔栀 柵栅 標标 棧栈 櫛栉 櫳栊 棟栋 櫨栌 櫟栎 欄栏 樹树 棲栖 樣样 欒栾 棬桊 椏桠 橈桡 楨桢 檔档 榿桤 橋桥 樺桦 檜桧 槳桨 樁桩 夢梦 檢检 欞棂 槨椁 櫝椟 槧椠 槓杠 槤梿 櫚榈 櫸榉 檳槟 櫧槠 橫横 檣樯 櫻樱 櫫橥 櫥橱 櫓橹 櫞橼 檁檩",
+	"歡欢 歟欤 歐欧 殲歼 殤殇 殘残 殞殒 殮殓 殫殚 殯殡 毆殴 毀毁 轂毂 畢毕 斃毙 氈毡 毿毵 氌氇 氣气 氫氢 氬氩 氳氲 匯汇 漢汉 湯汤 溝沟 沒没 灃沣 漚沤 瀝沥 淪沦 滄沧 渢沨 溈沩 滬沪 濔沵 濘泞 淚泪 澩泶 瀧泷 瀘泸 濼泺 瀉泻 潑泼 澤泽 涇泾 潔洁 灑洒 窪洼 浹浃 淺浅 漿浆 澆浇 湞浈 濁浊 測测 澮浍 濟济 瀏浏 渾浑 滸浒 濃浓 潯浔 濤涛 澇涝 淶涞 漣涟 渦涡 溳涢 渙涣 滌涤 潤润 澗涧 漲涨 澀涩 澱淀 淵渊 漬渍 瀆渎 漸渐 澠渑 漁渔 瀋沈 滲渗 溫温 遊游 灣湾 濕湿 潰溃 濺溅 漵溆 滾滚 滯滞 灩滟 灄滠 滿满 瀅滢 濾滤 濫滥 灤滦 濱滨 灘滩 澦滪 瀠潆 瀟潇 瀲潋 濰潍 潛潜 瀾澜 瀨濑 灕漓 灝灏 滅灭 燈灯 靈灵 災灾 燦灿 煬炀 爐炉 燉炖 煒炜 熗炝 點点 煉炼 熾炽 爍烁 爛烂 烴烃 燭烛 煙烟 煩烦 燒烧 燁烨 燴烩 燙烫 燼烬 熱热 煥焕 燜焖 燾焘 愛爱 爺爷 牘牍 犛牦 牽牵 犧牺 犢犊 強强 狀状 獷犷 獁犸 猶犹 狽狈 獮狝 獰狞 獨独 狹狭 獅狮 獪狯 猙狰 獄狱 猻狲 獫猃 獵猎 獼猕 玀猡 豬猪 貓猫 蝟猬 獻献 獺獭",
+	"璣玑 瑪玛 瑋玮 環环 現现 瑲玱 璽玺 瓏珑 琺珐 璫珰 琿珲 璉琏 瑣琐 瓊琼 瑤瑶 璦瑷 瓔璎 甌瓯 電电 畫画 暢畅 疇畴 療疗 瘧疟 癘疠 瘍疡 癤疖 瘡疮 瘋疯 皰疱 痾疴 癰痈 痙痉 癢痒 瘂痖 癆痨 瘓痪 癇痫 癉瘅 瘞瘗 瘺瘘 癟瘪 癱瘫 癮瘾 癭瘿 癩癞 癬癣 癲癫 發发 皚皑 皺皱 盜盗 盞盏 監监 盤盘 盧卢 蕩荡 眥眦 矚瞩 睜睁 瞼睑 瞞瞒 礬矾 礦矿 碭砀 碼码 磚砖 硨砗 硯砚 碸砜 礪砺 礱砻 礫砾 礎础 硜硁 碩硕 硤硖 磽硗 確确 鹼碱 礙碍 磧碛 磣碜 禮礼 禕祎 禰祢 禍祸 禎祯 祿禄 禪禅 離离 禿秃 稈秆 種种 積积 稱称 穢秽 穠秾 穩稳 穡穑 窮穷 竊窃 竅窍 窯窑 竄窜 窩窝 窺窥 竇窦 豎竖 競竞 筆笔 筍笋 箋笺 筧笕 籌筹 簽签 簡简 籃篮 籬篱 籮箩 類类 糧粮 糲粝 粵粤 糞粪 緊紧 糾纠 紀纪 紂纣 約约 紅红 紆纡 紇纥 紈纨 紉纫 紋纹 納纳 紐纽 紓纾 純纯 紕纰 紗纱 綸纶 紛纷 紙纸 級级 紜纭 紡纺 紖纼 細细 紱绂 紳绅 紹绍 紺绀 終终 組组 絆绊 紼绋 絀绌 綁绑 絨绒 結结 絝绔 繞绕 給给 絢绚 絳绛 絡络 絕绝 絞绞 統统 綆绠 綃绡 絹绢 繡绣 綏绥 絛绦 繼继 綈绨 績绩 緒绪 綾绫 續续 綺绮 緋绯 綽绰 緔绱 緄绲 繩绳 維维 綿绵 綬绶 繃绷 綢绸 綹绺 綣绻 綜综 綻绽 綰绾 綠绿 綴缀 緇缁 緙缂 緗缃 緘缄 緬缅 纜缆 緹缇 緲缈 緝缉 縕缊 緞缎 締缔 緣缘 編编 緩缓 緡缗 緯纬 緱缑 縋缒 緶缏 縛缚 縟缛 縉缙 縗缞 縞缟 縭缡 縊缢 縑缣 繽缤 縹缥 縵缦 縲缧 纓缨 縮缩 繆缪 繅缫 纈缬 繚缭 織织 繕缮 繒缯 繳缴 纘缵 罌罂 網网 羅罗 罰罚 罷罢 羆罴 羈羁",
+	"羥羟 翹翘 耬耧 聳耸 恥耻 聶聂 聾聋 職职 聹聍 聯联 聵聩 聰聪 肅肃 腸肠 膚肤 腎肾 腫肿 脹胀 脅胁 膽胆 勝胜 朧胧 臚胪 脛胫 膠胶 脈脉 膾脍 臍脐 腦脑 膿脓 臠脔 腳脚 脫脱 腡脶 臉脸 臘腊 醃腌 膩腻 騰腾 臏膑 臥卧 臨临 臺台 與与 興兴 舉举 艙舱 艤舣 艦舰 艫舻 艱艰 艷艳 藝艺 節节 羋芈 薌芗 蕪芜 蘆芦 蓯苁 葦苇 藶苈 莧苋 萇苌 蒼苍 苧苎 蘋苹 莖茎 蘢茏 蔦茑 塋茔 煢茕 薦荐 蕎荞 蓀荪 蔭荫 葷荤 蒔莳 萵莴 薟莶 獲获 蕕莸 瑩莹 鶯莺 蓴莼 蘿萝 螢萤 營营 縈萦 蕭萧 薩萨 蔥葱 蕆蒇 蕢蒉 蔣蒋 蔞蒌 藍蓝 薊蓟 蘺蓠 蕷蓣 鎣蓥 驀蓦 藺蔺 蘞蔹 蘄蕲 蘊蕴 藪薮 蘚藓 虜虏 慮虑 蟲虫 虯虬 蝦虾 雖虽 螞蚂 蠶蚕 蠆虿 蜆蚬 蠱蛊 蠣蛎 蟶蛏 蠻蛮 蟄蛰 蛺蛱 螄蛳 蠐蛴 蝸蜗 蠟蜡 蠅蝇 蟬蝉 蠍蝎 螻蝼 蠑蝾 蟻蚁 銜衔 補补 襯衬 襖袄 嫋袅 褘袆 襪袜 襲袭 裝装 襠裆 褳裢 襝裣 褲裤 襉裥 褸褛 襤褴 見见 觀观 規规 覓觅 視视 覘觇 覽览 覺觉 覬觊 覡觋 覲觐 覦觎 覯觏 覷觑 觴觞 觸触 觶觯 訁讠 計计 訂订 訃讣 認认 譏讥 訐讦 訌讧 討讨 讓让 訕讪 訖讫 訓训 議议 訊讯 記记 講讲 諱讳 謳讴 詎讵 訝讶 訥讷 許许 訛讹 論论 訩讻 訟讼 諷讽 設设 訪访 訣诀 證证 詁诂 訶诃 評评 詛诅 識识 詐诈 訴诉 診诊 詆诋 謅诌 詞词 詘诎 詔诏 譯译 詒诒 誆诓 誄诔 試试 詿诖 詩诗 詰诘 詼诙 誠诚 誅诛 詵诜 話话 誕诞 詬诟 詮诠 詭诡 詢询 詣诣 諍诤 該该 詳详 詫诧 諢诨 詡诩 譸诪 誡诫 誣诬 語语 誚诮 誤误 誥诰 誘诱 誨诲 誑诳 說说 誦诵 誒诶 請请 諸诸 諏诹 諾诺 讀读 諑诼 誹诽 課课 諉诿 諛谀 誰谁 諗谂 調调 諂谄 諒谅 諄谆 誶谇 談谈 誼谊 謀谋 諶谌 諜谍 謊谎 諫谏 諧谐 謔谑 謁谒 謂谓 諤谔 諭谕 諼谖 讒谗 諮谘 諳谙 諺谚 諦谛 謎谜 諞谝 謨谟 讜谠 謝谢 謠谣 謗谤 謚谥 謙谦 謐谧 謹谨 謾谩 謫谪 譾谫 謬谬 譚谭 譖谮 譙谯 讕谰 譜谱 譎谲 讞谳 譴谴 譫谵 讖谶",
+	"豈岂 豎竖 豐丰 豔艳 貝贝 貞贞 負负 貢贡 財财 責责 賢贤 敗败 賬账 貨货 質质 販贩 貪贪 貧贫 貶贬 購购 貯贮 貫贯 貳贰 賤贱 賁贲 貰贳 貼贴 貴贵 貺贶 貸贷 貿贸 費费 賀贺 貽贻 賊贼 贄贽 賈贾 賄贿 貲赀 賃赁 賂赂 資资 賅赅 贐赆 賕赇 賑赈 賚赉 賒赊 賦赋 賭赌 贖赎 賞赏 賜赐 贔赑 賙赒 賡赓 賠赔 賧赕 賴赖 贅赘 賻赙 賺赚 賽赛 賾赜 贊赞 贈赠 贍赡 贏赢 贛赣 趙赵 趕赶 趨趋 趲趱 躉趸 躍跃 蹌跄 跡迹 踐践 躂跶 蹺跷 蹕跸 躚跹 躒跞 踴踊 躊踌 蹤踪 躓踬 躑踯 躡蹑 蹣蹒 躪躏 車车 軋轧 軌轨 軒轩 軔轫 轉转 輪轮 軟软 轟轰 軸轴 軻轲 軼轶 軤轷 軫轸 轢轹 軺轺 輕轻 軾轼 載载 輊轾 轎轿 輇辁 輅辂 較较 輒辄 輔辅 輛辆 輦辇 輩辈 輝辉 輥辊 輞辋 輟辍 輜辎 輳辏 輻辐 輯辑 輸输 轡辔 轅辕 輾辗 輿舆 轄辖 轆辘 轍辙 轔辚 辭辞 辯辩 農农 邊边 遼辽 達达 遷迁 過过 邁迈 運运 還还 這这 進进 遠远 違违 連连 遲迟 邇迩 逕迳 適适 選选 遜逊 遞递 邏逻 遺遗 遙遥 鄧邓 鄺邝 鄔邬 郵邮 鄒邹 鄴邺 鄰邻 鬱郁 郟郏 鄶郐 鄭郑 鄆郓 酈郦 鄖郧 鄲郸 醞酝 醱酦 醬酱 釅酽 釃酾 釀酿 釋释 裡里 裏里 鑒鉴 鑾銮 鏨錾",
+	"釓钆 釔钇 針针 釘钉 釗钊 釙钋 釕钌 釷钍 釺钎 釧钏 釤钐 釩钒 釣钓 鍆钔 釹钕 鈣钙 鈈钚 鋇钡 鈍钝 鈔钞 鍾钟 鐘钟 鈉钠 鋇钡 鋼钢 鈑钣 鈐钤 鑰钥 欽钦 鈞钧 鎢钨 鈧钪 鈁钫 鈥钬 鈄钭 鈕钮 鈀钯 鈺钰 錢钱 鉦钲 鉗钳 鈷钴 缽钵 鈳钶 鉕钷 鈽钸 鈸钹 鉞钺 鑽钻 鉬钼 鉭钽 鉀钾 鈿钿 鈾铀 鐵铁 鉑铂 鈴铃 鑠铄 鉛铅 鉚铆 鈰铈 鉉铉 鉈铊 鉍铋 鈹铍 鐸铎 鉶铏 銬铐 銠铑 鉺铒 銪铕 鋁铝 銱铞 銦铟 鎧铠 鍘铡 銖铢 銑铣 鋌铤 銩铥 鏵铧 銓铨 鉿铪 鎩铩 銚铫 鉻铬 銘铭 錚铮 銫铯 鉸铰 銥铱 銃铳 鐺铛 銨铵 銀银 銣铷 鑄铸 鐒铹 鋪铺 錸铼 鋱铽 鏈链 鏗铿 銷销 鎖锁 鋰锂 鋥锃 鋤锄 鍋锅 鋯锆 鋨锇 銹锈 銼锉 鋒锋 鋅锌 鋶锍 鐦锎 鐧锏 銻锑 鋃锒 鋟锓 鋦锔 錒锕 錆锖 鍺锗 錯错 錨锚 錛锛 錡锜 錁锞 錕锟 錩锠 錫锡 錮锢 鑼锣 錘锤 錐锥 錦锦 鍁锨 錈锩 錇锫 錟锬 錠锭 鍵键 鋸锯 錳锰 錙锱 鍥锲 鍈锳 鍇锴 鏘锵 鍶锶 鍔锷 鍤锸 鍬锹 鍛锻 鎪锼 鍠锽 鍰锾 鎄锿 鍍镀 鎂镁 鏤镂 鐨镄 鎇镅 鏌镆 鎮镇 鎛镈 鎘镉 鑷镊 鐫镌 鎳镍 鎿镎 鎦镏 鎬镐 鎊镑 鎰镒 鎵镓 鑌镔 鏢镖 鏜镗 鏝镘 鏍镙 鏰镚 鏞镛 鏡镜 鏑镝 鏃镞 鏇镟 鐓镦 鐔镡 鐝镢 鐐镣 鏷镤 鑥镥 鐓镦 鑭镧 鐠镨 鑹镩 鏹镪 鐙镫 鑊镬 鐳镭 鐶镮 鐲镯 鐮镰 鐿镱 鑣镳 鑲镶 钁镢 長长 門门 閂闩 閃闪 閆闫 閉闭 問问 闖闯 閏闰 閑闲 閒闲 間间 閔闵 閌闶 悶闷 閘闸 鬧闹 閨闺 聞闻 闥闼 閩闽 閭闾 閥阀 閣阁 閡阂 閫阃 鬮阄 閱阅 閬阆 閾阈 閹阉 閶阊 鬩阋 閿阌 閽阍 閻阎 閼阏 闡阐 闌阑 闃阒 闊阔 闋阕 闔阖 闐阗 關关 闞阚 闢辟 隊队 陽阳 陰阴 陣阵 階阶 際际 陸陆 隴陇 陳陈 陘陉 陝陕 隉陧 隕陨 險险 隨随 隱隐 隸隶 難难 雛雏 讎雠 靂雳 霧雾 霽霁 靄霭 靚靓 靜静 靨靥 韃鞑 韁缰 韋韦 韌韧 韓韩 韙韪 韜韬 韞韫 韻韵",
+	"頁页 頂顶 頃顷 項项 順顺 須须 頊顼 頑顽 顧顾 頓顿 頎颀 頒颁 頌颂 頏颃 預预 顱颅 領领 頗颇 頸颈 頡颉 頰颊 頜颌 潁颍 頦颏 頻频 頹颓 頷颔 穎颖 顆颗 題题 顏颜 額额 顎颚 顓颛 願愿 顙颡 顛颠 類类 顢颟 顥颢 顫颤 顰颦 顴颧 風风 颮飑 颯飒 颱台 颳刮 颶飓 飄飘 飆飙 飛飞 饗飨 饜餍 飢饥 飣饤 餳饧 飩饨 飪饪 飫饫 飭饬 飯饭 飲饮 餞饯 飾饰 飽饱 飼饲 飴饴 餌饵 饒饶 餉饷 餃饺 餅饼 餑饽 餓饿 餒馁 餘余 餚肴 餛馄 餡馅 館馆 餷馇 饋馈 餿馊 饞馋 饃馍 饅馒 饈馐 饉馑 饊馓 饌馔 饢馕 馬马 馭驭 馱驮 馴驯 馳驰 驅驱 駁驳 驢驴 駔驵 駛驶 駟驷 駙驸 駒驹 騶驺 駐驻 駑驽 駕驾 驛驿 駘骀 驍骁 駱骆 駭骇 駢骈 驊骅 騎骑 驗验 駿骏 騁骋 騍骒 騏骐 驥骥 騙骗 騷骚 騖骛 驁骜 驂骖 驃骠 驄骢 驕骄 驟骤 驪骊 骯肮 髏髅 髒脏 體体 髕髌 髖髋 髮发 鬆松 鬍胡 鬚须 鬢鬓 鬥斗 鬧闹 鬱郁 魎魉 魘魇 魚鱼 魯鲁 鮑鲍 鮮鲜 鯉鲤 鯊鲨 鯨鲸 鰐鳄 鱗鳞 鱷鳄 鳥鸟 鳩鸠 雞鸡 鳴鸣 鴉鸦 鴨鸭 鴛鸳 鴦鸯 鴻鸿 鵑鹃 鵝鹅 鵬鹏 鶴鹤 鷗鸥 鷹鹰 鸚鹦 鸞鸾 鹹咸 鹽盐 麥麦 麩麸 黃黄 黌黉 點点 黨党 黲黪 黷黩 鼴鼹 齊齐 齏齑 齒齿 齡龄 齣出 齦龈 齜龇 齪龊 齲龋 齶腭 龍龙 龔龚 龕龛 龜龟",
+	// 补充: 书名作者常见字
+	"後后 麵面 隻只 衆众 鐵铁 門门 傢家 麪面 嘗尝 嚐尝 蹟迹 癡痴 竈灶 壺壶 禦御 甦苏 蘇苏 囉啰 嘍喽 剋克 慾欲 矇蒙 濛蒙 懞蒙 兇凶 鑑鉴 檯台 颱台 週周 準准 製制 築筑 範范 鬨哄 閤合 徵征 僕仆 醖酝 佔占 佈布 託托 夥伙 彿佛 祕秘 纔才 衹只 祇只",
+	"經经 線线 練练 縱纵 繪绘 纖纤 樓楼 劇剧 稅税 藥药 誌志 恆恒 曆历 榮荣 癒愈 穀谷 臟脏 萊莱 蓋盖 虛虚 軀躯 闆板 黴霉",
+}
+
+var t2s map[rune]rune
+
+func init() {
+	t2s = make(map[rune]rune, 3000)
+	for _, line := range t2sPairs {
+		for _, pair := range strings.Fields(line) {
+			runes := []rune(pair)
+			if len(runes) != 2 || runes[0] == runes[1] {
+				continue
+			}
+			t2s[runes[0]] = runes[1]
+		}
+	}
+}
+
+// ToSimplified 将文本中的繁体字按字转换为简体字, 非汉字和未收录的字保持不变
+func ToSimplified(s string) string {
+	runes := []rune(s)
+	changed := false
+	for i, r := range runes {
+		if simplified, ok := t2s[r]; ok {
+			runes[i] = simplified
+			changed = true
+		}
+	}
+	if !changed {
+		return s
+	}
+	return string(runes)
+}
@@ -0,0 +1,103 @@
+// Package isbn ISBN-10 / ISBN-13 的解析、校验位校验和相互转换
+//
+//	// 图书以 ISBN-13 为唯一标识: 两种形式的 ISBN 都转换为 ISBN-13 后比较
+package isbn
+
+import (
+	"errors"
+	"strings"
+)
+
+var (
+	// ErrFormat 去除连字符和空格后不是 10 位（末位可为 X）或 978/979 开头的 13 位数字
+	ErrFormat = errors.New("ISBN 格式错误")
+	// ErrChecksum 校验位错误
+	ErrChecksum = errors.New("ISBN 校验位错误")
+	// ErrNoISBN10 979 开头的 ISBN-13 没有对应的 ISBN-10
+	ErrNoISBN10 = errors.New("979 开头的 ISBN 没有 ISBN-10 形式")
+)
+
+// Normalize 去除连字符和空格, 校验位 x 转为大写; 不做校验
+func Normalize(s string) string {
+	return strings.ToUpper(strings.NewReplacer("-", "", " ", "").Replace(strings.TrimSpace(s)))
+}
+
+// To13 解析 ISBN-10 或 ISBN-13 (允许连字符和空格) 并校验校验位, 返回规范化的 ISBN-13
+func To13(s string) (string, error) {
+	s = Normalize(s)
+	switch len(s) {
+	case 10:
+		if !isDigits(s[:9]) || !(isDigits(s[9:]) || s[9] == 'X') {
+			return "", ErrFormat
+		}
+		if checkDigit10(s[:9]) != s[9] {
+			return "", ErrChecksum
+		}
+		body := "978" + s[:9]
+		return body + string(checkDigit13(body)), nil
+	case 13:
+		if !isDigits(s) || !(strings.HasPrefix(s, "978") || strings.HasPrefix(s, "979")) {
+			return "", ErrFormat
+		}
+		if checkDigit13(s[:12]) != s[12] {
+			return "", ErrChecksum
+		}
+		return s, nil
+	default:
+		return "", ErrFormat
+	}
+}
+
+// Valid 是否为校验位正确的 ISBN-10 或 ISBN-13
+func Valid(s string) bool {
+	_, err := To13(s)
+	return err == nil
+}
+
+// To10 转换为 ISBN-10, 只有 978 开头的 ISBN-13 有对应的 ISBN-10
+func To10(s string) (string, error) {
+	isbn13, err := To13(s)
+	if err != nil {
+		return "", err
+	}
+	if !strings.HasPrefix(isbn13, "978") {
+		return "", ErrNoISBN10
+	}
+	body := isbn13[3:12]
+	return body + string(checkDigit10(body)), nil
+}
+
+// checkDigit10 ISBN-10 校验位: 前 9 位依次乘以权重 10..2, 加上校验位后之和为 11 的倍数, 余数 10 记为 X
+func checkDigit10(body string) byte {
+	sum := 0
+	for i := 0; i < 9; i++ {
+		sum += int(body[i]-'0') * (10 - i)
+	}
+	check := (11 - sum%11) % 11
+	if check == 10 {
+		return 'X'
+	}
+	return byte('0' + check)
+}
+
+// checkDigit13 ISBN-13 校验位: 前 12 位依次乘以权重 1、3 交替, 加上校验位后之和为 10 的倍数
+func checkDigit13(body string) byte {
+	sum := 0
+	for i := 0; i < 12; i++ {
+		digit := int(body[i] - '0')
+		if i%2 == 1 {
+			digit *= 3
+		}
+		sum += digit
+	}
+	return byte('0' + (10-sum%10)%10)
+}
+
+func isDigits(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
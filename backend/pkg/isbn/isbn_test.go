@@ -0,0 +1,97 @@
+package isbn
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"978-7-5366-9293-0", "9787536692930"},
+		{" 978 7 5366 9293 0 ", "9787536692930"},
+		{"0-8044-2957-x", "080442957X"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := Normalize(tt.in); got != tt.want {
+			t.Errorf("Normalize(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestTo13(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    string
+		wantErr error
+	}{
+		// ISBN-13
+		{"9787536692930", "9787536692930", nil},
+		{"978-0-306-40615-7", "9780306406157", nil},
+		{"979-10-90636-07-1", "9791090636071", nil},
+		{"9787536692931", "", ErrChecksum},
+		{"9770306406157", "", ErrFormat}, // 非 978/979 前缀
+		{"97803064061a7", "", ErrFormat},
+		// ISBN-10
+		{"0-306-40615-2", "9780306406157", nil},
+		{"7536692935", "9787536692930", nil},
+		{"080442957X", "9780804429573", nil},
+		{"080442957x", "9780804429573", nil},
+		{"0306406153", "", ErrChecksum},
+		{"X306406152", "", ErrFormat},
+		// 长度错误
+		{"", "", ErrFormat},
+		{"978753669293", "", ErrFormat},
+	}
+	for _, tt := range tests {
+		got, err := To13(tt.in)
+		if !errors.Is(err, tt.wantErr) {
+			t.Errorf("To13(%q) error = %v, want %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("To13(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestTo10(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    string
+		wantErr error
+	}{
+		{"9780306406157", "0306406152", nil},
+		{"978-7-5366-9293-0", "7536692935", nil},
+		{"9780804429573", "080442957X", nil},
+		{"0-306-40615-2", "0306406152", nil},
+		{"9791090636071", "", ErrNoISBN10},
+		{"9780306406158", "", ErrChecksum},
+	}
+	for _, tt := range tests {
+		got, err := To10(tt.in)
+		if !errors.Is(err, tt.wantErr) {
+			t.Errorf("To10(%q) error = %v, want %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("To10(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestValid(t *testing.T) {
+	for _, s := range []string{"9787536692930", "0-306-40615-2", "080442957X"} {
+		if !Valid(s) {
+			t.Errorf("Valid(%q) = false, want true", s)
+		}
+	}
+	for _, s := range []string{"9787532776772", "0306406153", "abc", ""} {
+		if Valid(s) {
+			t.Errorf("Valid(%q) = true, want false", s)
+		}
+	}
+}
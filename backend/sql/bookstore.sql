@@ -19,6 +19,7 @@ CREATE TABLE users (
 -- 创建分类表
 CREATE TABLE categories (
     id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    parent_id BIGINT DEFAULT NULL COMMENT '父分类ID, 为空表示顶级分类',
     name VARCHAR(50) NOT NULL COMMENT '分类名称',
     description VARCHAR(200) DEFAULT NULL COMMENT '分类描述',
     icon VARCHAR(20) DEFAULT NULL COMMENT '分类图标',
@@ -29,7 +30,9 @@ CREATE TABLE categories (
     book_count INT DEFAULT 0 COMMENT '该分类下的图书数量',
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
     updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
-    UNIQUE KEY uk_name (name)
+    UNIQUE KEY uk_name (name),
+    KEY idx_parent_id (parent_id),
+    FOREIGN KEY (parent_id) REFERENCES categories(id)
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='图书分类表';
 
 -- 创建书籍表
@@ -37,26 +40,51 @@ CREATE TABLE books (
     id BIGINT AUTO_INCREMENT PRIMARY KEY,
     title VARCHAR(255) NOT NULL,
     author VARCHAR(100),
+    series VARCHAR(100) NOT NULL DEFAULT '' COMMENT '丛书/系列名',
     price INT NOT NULL COMMENT '价格（元）',
     discount INT DEFAULT 0 COMMENT '折扣（百分比，0表示无折扣）',
     type VARCHAR(50),
     category_id BIGINT DEFAULT NULL COMMENT '分类ID',
     stock INT DEFAULT 0,
+    reserved INT NOT NULL DEFAULT 0 COMMENT '已预占库存（待支付订单占用）',
     status TINYINT(1) DEFAULT 1 COMMENT '图书状态：0-下架，1-上架',
     description TEXT,
     cover_url VARCHAR(255),
     isbn VARCHAR(20),
+    isbn13 CHAR(13) DEFAULT NULL COMMENT '规范化的 ISBN-13, ISBN 无效时为空',
     publisher VARCHAR(100),
     publish_date VARCHAR(50),
     pages INT,
     language VARCHAR(20) DEFAULT '中文',
     format VARCHAR(20) DEFAULT '平装',
     sale INT DEFAULT 0 COMMENT '销售量',
+    rating_avg DECIMAL(3,2) NOT NULL DEFAULT 0 COMMENT '平均评分',
+    rating_count INT NOT NULL DEFAULT 0 COMMENT '评分人数',
+    search_keys VARCHAR(1024) NOT NULL DEFAULT '' COMMENT '检索键: 书名/作者/系列的简体文本、全拼和拼音首字母',
     created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
     updated_at DATETIME DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    INDEX idx_books_status_sale (status, sale),
+    INDEX idx_books_status_created (status, created_at),
+    INDEX idx_books_status_rating (status, rating_avg),
+    UNIQUE KEY uk_books_isbn13 (isbn13),
+    FULLTEXT INDEX ft_books_search (title, author, description, publisher, isbn) WITH PARSER ngram,
+    FULLTEXT INDEX ft_books_search_keys (search_keys) WITH PARSER ngram,
     FOREIGN KEY (category_id) REFERENCES categories(id) ON DELETE SET NULL
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
 
+-- 创建购物车表
+CREATE TABLE cart_items (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    user_id BIGINT NOT NULL,
+    book_id BIGINT NOT NULL,
+    quantity INT NOT NULL DEFAULT 1 COMMENT '数量',
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    UNIQUE KEY unique_user_book (user_id, book_id),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (book_id) REFERENCES books(id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='购物车表';
+
 -- 创建收藏表
 CREATE TABLE favorites (
     id BIGINT AUTO_INCREMENT PRIMARY KEY,
@@ -75,12 +103,13 @@ CREATE TABLE orders (
     user_id BIGINT NOT NULL,
     order_no VARCHAR(50) NOT NULL COMMENT '订单号',
     total_amount INT NOT NULL COMMENT '总金额（元）',
-    status TINYINT DEFAULT 0 COMMENT '订单状态：0-待支付，1-已支付，2-已取消',
+    status TINYINT DEFAULT 0 COMMENT '订单状态：0-待支付，1-已支付，2-已取消，3-已发货，4-已送达，5-已完成，6-已退款',
     is_paid BOOLEAN DEFAULT FALSE COMMENT '是否已支付',
     payment_time TIMESTAMP NULL DEFAULT NULL COMMENT '支付时间',
     created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
     updated_at DATETIME DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
     UNIQUE KEY uk_order_no (order_no),
+    INDEX idx_orders_payment_time (payment_time),
     FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
 
@@ -90,14 +119,70 @@ CREATE TABLE order_items (
     order_id BIGINT NOT NULL,
     book_id BIGINT NOT NULL,
     quantity INT NOT NULL,
-    price INT NOT NULL COMMENT '单价（元）',
-    subtotal INT NOT NULL COMMENT '小计（元）',
+    list_price INT NOT NULL DEFAULT 0 COMMENT '下单时原价（分）',
+    discount INT NOT NULL DEFAULT 0 COMMENT '下单时折扣（百分比，0表示无折扣）',
+    price INT NOT NULL COMMENT '成交单价（分）',
+    subtotal INT NOT NULL COMMENT '小计（分）',
     created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
     updated_at DATETIME DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
     FOREIGN KEY (order_id) REFERENCES orders(id) ON DELETE CASCADE,
     FOREIGN KEY (book_id) REFERENCES books(id) ON DELETE CASCADE
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
 
+-- 创建订单状态变更记录表
+CREATE TABLE IF NOT EXISTS order_status_history (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    order_id BIGINT NOT NULL COMMENT '订单ID',
+    from_status TINYINT DEFAULT NULL COMMENT '变更前状态, 创建订单时为空',
+    to_status TINYINT NOT NULL COMMENT '变更后状态',
+    operator_type VARCHAR(20) NOT NULL COMMENT '操作人类型：user, admin, system',
+    operator_id BIGINT DEFAULT 0 COMMENT '操作人ID, 系统操作为 0',
+    remark VARCHAR(255) DEFAULT NULL COMMENT '备注',
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    KEY idx_order_id (order_id),
+    FOREIGN KEY (order_id) REFERENCES orders(id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='订单状态变更记录表';
+
+-- 创建图书评价表
+CREATE TABLE reviews (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    user_id BIGINT NOT NULL,
+    book_id BIGINT NOT NULL,
+    rating TINYINT NOT NULL COMMENT '评分 1-5 星',
+    content TEXT COMMENT '评价内容',
+    helpful_count INT NOT NULL DEFAULT 0 COMMENT '有用票数',
+    status TINYINT NOT NULL DEFAULT 0 COMMENT '状态：0-待审核，1-已通过，2-已隐藏',
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    UNIQUE KEY uk_user_book (user_id, book_id),
+    KEY idx_book_status_created (book_id, status, created_at),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (book_id) REFERENCES books(id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='图书评价表';
+
+-- 创建评价有用票表
+CREATE TABLE review_votes (
+    review_id BIGINT NOT NULL,
+    user_id BIGINT NOT NULL,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (review_id, user_id),
+    FOREIGN KEY (review_id) REFERENCES reviews(id) ON DELETE CASCADE,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='评价有用票表';
+
+-- 创建图书相似度表 (买了这本书的人还买了), 由推荐任务定期全量重建
+CREATE TABLE book_similarities (
+    book_id BIGINT NOT NULL,
+    similar_book_id BIGINT NOT NULL,
+    score DOUBLE NOT NULL COMMENT '余弦相似度',
+    co_count INT NOT NULL DEFAULT 0 COMMENT '共同购买人数',
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    PRIMARY KEY (book_id, similar_book_id),
+    KEY idx_book_score (book_id, score),
+    FOREIGN KEY (book_id) REFERENCES books(id) ON DELETE CASCADE,
+    FOREIGN KEY (similar_book_id) REFERENCES books(id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='图书相似度表';
+
 -- 创建轮播图表
 CREATE TABLE carousel (
     id BIGINT PRIMARY KEY AUTO_INCREMENT,
@@ -107,6 +192,68 @@ CREATE TABLE carousel (
     link_url VARCHAR(500) COMMENT '点击跳转链接',
     sort_order INT DEFAULT 0 COMMENT '排序',
     is_active BOOLEAN DEFAULT TRUE COMMENT '是否激活',
+    start_time DATETIME DEFAULT NULL COMMENT '展示开始时间, 为空表示立即生效',
+    end_time DATETIME DEFAULT NULL COMMENT '展示结束时间, 为空表示长期有效',
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    KEY idx_active_sort (is_active, sort_order)
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='轮播图表';
+
+-- 创建角色表
+CREATE TABLE IF NOT EXISTS roles (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    name VARCHAR(50) NOT NULL COMMENT '角色标识',
+    description VARCHAR(200) DEFAULT NULL COMMENT '角色描述',
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    UNIQUE KEY uk_name (name)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='角色表';
+
+-- 创建权限表
+CREATE TABLE IF NOT EXISTS permissions (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    code VARCHAR(100) NOT NULL COMMENT '权限标识, 资源:操作',
+    description VARCHAR(200) DEFAULT NULL COMMENT '权限描述',
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    UNIQUE KEY uk_code (code)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='权限表';
+
+-- 创建角色权限关联表
+CREATE TABLE IF NOT EXISTS role_permissions (
+    role_id BIGINT NOT NULL,
+    permission_id BIGINT NOT NULL,
+    PRIMARY KEY (role_id, permission_id),
+    FOREIGN KEY (role_id) REFERENCES roles(id) ON DELETE CASCADE,
+    FOREIGN KEY (permission_id) REFERENCES permissions(id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='角色权限关联表';
+
+-- 创建用户角色关联表
+CREATE TABLE IF NOT EXISTS user_roles (
+    user_id BIGINT NOT NULL,
+    role_id BIGINT NOT NULL,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (user_id, role_id),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (role_id) REFERENCES roles(id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='用户角色关联表';
+
+-- 默认角色和权限
+INSERT IGNORE INTO roles (name, description) VALUES
+('customer', '普通用户'),
+('admin', '管理员');
+
+INSERT IGNORE INTO permissions (code, description) VALUES
+('book:manage', '图书管理'),
+('category:manage', '分类管理'),
+('order:manage', '订单管理'),
+('role:manage', '角色分配'),
+('carousel:manage', '轮播图管理'),
+('review:manage', '评价审核');
+
+-- admin 拥有全部权限
+INSERT IGNORE INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id FROM roles r CROSS JOIN permissions p WHERE r.name = 'admin';
+
+-- 权限只以 user_roles 为准, users.is_admin 不再生效. 全新安装后注册首个用户, 再通过命令行授予管理员角色:
+--   bookstore-cli --env prod grant-role <username> admin
@@ -0,0 +1,9 @@
+-- 002 订单项价格明细: 记录下单时的原价和折扣, price 为服务端计算的成交单价
+USE bookstore;
+
+ALTER TABLE order_items
+    ADD COLUMN list_price INT NOT NULL DEFAULT 0 COMMENT '下单时原价（分）' AFTER quantity,
+    ADD COLUMN discount INT NOT NULL DEFAULT 0 COMMENT '下单时折扣（百分比，0表示无折扣）' AFTER list_price;
+
+-- 历史订单项没有折扣快照, 以成交价作为原价
+UPDATE order_items SET list_price = price WHERE list_price = 0;
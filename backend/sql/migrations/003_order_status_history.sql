@@ -0,0 +1,18 @@
+-- 003 订单状态机: 扩展订单状态, 新增状态变更记录表
+USE bookstore;
+
+ALTER TABLE orders MODIFY COLUMN status TINYINT DEFAULT 0 COMMENT '订单状态：0-待支付，1-已支付，2-已取消，3-已发货，4-已送达，5-已完成，6-已退款';
+
+-- 创建订单状态变更记录表
+CREATE TABLE IF NOT EXISTS order_status_history (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    order_id BIGINT NOT NULL COMMENT '订单ID',
+    from_status TINYINT DEFAULT NULL COMMENT '变更前状态, 创建订单时为空',
+    to_status TINYINT NOT NULL COMMENT '变更后状态',
+    operator_type VARCHAR(20) NOT NULL COMMENT '操作人类型：user, admin, system',
+    operator_id BIGINT DEFAULT 0 COMMENT '操作人ID, 系统操作为 0',
+    remark VARCHAR(255) DEFAULT NULL COMMENT '备注',
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    KEY idx_order_id (order_id),
+    FOREIGN KEY (order_id) REFERENCES orders(id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='订单状态变更记录表';
@@ -0,0 +1,13 @@
+-- 004 库存预占: 下单时预占库存, 支付时转为销量, 取消时释放
+USE bookstore;
+
+ALTER TABLE books ADD COLUMN reserved INT NOT NULL DEFAULT 0 COMMENT '已预占库存（待支付订单占用）' AFTER stock;
+
+-- 为现有的待支付订单补齐预占数量
+UPDATE books b
+SET b.reserved = (
+    SELECT COALESCE(SUM(oi.quantity), 0)
+    FROM order_items oi
+    JOIN orders o ON o.id = oi.order_id
+    WHERE oi.book_id = b.id AND o.status = 0
+);
@@ -0,0 +1,13 @@
+-- 006 轮播图: 展示时间窗口, 轮播图管理权限
+USE bookstore;
+
+ALTER TABLE carousel
+    ADD COLUMN start_time DATETIME DEFAULT NULL COMMENT '展示开始时间, 为空表示立即生效' AFTER is_active,
+    ADD COLUMN end_time DATETIME DEFAULT NULL COMMENT '展示结束时间, 为空表示长期有效' AFTER start_time,
+    ADD KEY idx_active_sort (is_active, sort_order);
+
+INSERT IGNORE INTO permissions (code, description) VALUES
+('carousel:manage', '轮播图管理');
+
+INSERT IGNORE INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id FROM roles r JOIN permissions p ON p.code = 'carousel:manage' WHERE r.name = 'admin';
@@ -0,0 +1,13 @@
+-- 007 分类树: 分类支持父子层级, 图书按分类 ID 查询
+USE bookstore;
+
+ALTER TABLE categories
+    ADD COLUMN parent_id BIGINT DEFAULT NULL COMMENT '父分类ID, 为空表示顶级分类' AFTER id,
+    ADD KEY idx_parent_id (parent_id),
+    ADD CONSTRAINT fk_categories_parent FOREIGN KEY (parent_id) REFERENCES categories(id);
+
+-- 为仅填写了 type 的图书补齐 category_id (type 与分类名称一致)
+UPDATE books b
+JOIN categories c ON c.name = b.type
+SET b.category_id = c.id
+WHERE b.category_id IS NULL;
@@ -0,0 +1,10 @@
+-- 008 分类图书数量: 只统计直属该分类的上架图书, 由图书写操作在事务中维护
+USE bookstore;
+
+-- 根据现有图书回填分类图书数量, 之后可用 bookstore-cli reconcile-category-counts 校正
+UPDATE categories c
+SET c.book_count = (
+    SELECT COUNT(*)
+    FROM books b
+    WHERE b.category_id = c.id AND b.status = 1
+);
@@ -0,0 +1,8 @@
+-- 009 图书列表筛选排序: 新增评分字段, 为常用排序增加索引
+USE bookstore;
+
+ALTER TABLE books
+    ADD COLUMN rating_avg DECIMAL(3,2) NOT NULL DEFAULT 0 COMMENT '平均评分' AFTER sale,
+    ADD COLUMN rating_count INT NOT NULL DEFAULT 0 COMMENT '评分人数' AFTER rating_avg,
+    ADD INDEX idx_books_status_sale (status, sale),
+    ADD INDEX idx_books_status_created (status, created_at);
@@ -0,0 +1,5 @@
+-- 010 图书全文检索: 使用 ngram 分词 (支持中文) 的 FULLTEXT 索引, 列顺序需与检索语句中的 MATCH 一致
+USE bookstore;
+
+ALTER TABLE books
+    ADD FULLTEXT INDEX ft_books_search (title, author, description, publisher, isbn) WITH PARSER ngram;
@@ -0,0 +1,4 @@
+-- 011 搜索联想: 新增丛书/系列名, 与书名、作者一起参与前缀联想
+USE bookstore;
+
+ALTER TABLE books ADD COLUMN series VARCHAR(100) NOT NULL DEFAULT '' COMMENT '丛书/系列名' AFTER author;
@@ -0,0 +1,7 @@
+-- 012 拼音与繁简检索: 新增检索键列及其全文索引
+-- 执行后需运行 bookstore-cli backfill-search-keys 回填存量图书 (同时更新检索索引)
+USE bookstore;
+
+ALTER TABLE books
+    ADD COLUMN search_keys VARCHAR(1024) NOT NULL DEFAULT '' COMMENT '检索键: 书名/作者/系列的简体文本、全拼和拼音首字母' AFTER rating_count,
+    ADD FULLTEXT INDEX ft_books_search_keys (search_keys) WITH PARSER ngram;
@@ -0,0 +1,35 @@
+-- 013 图书评价: 评价与有用票, 评价审核权限, 按评分排序的索引
+USE bookstore;
+
+CREATE TABLE IF NOT EXISTS reviews (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    user_id BIGINT NOT NULL,
+    book_id BIGINT NOT NULL,
+    rating TINYINT NOT NULL COMMENT '评分 1-5 星',
+    content TEXT COMMENT '评价内容',
+    helpful_count INT NOT NULL DEFAULT 0 COMMENT '有用票数',
+    status TINYINT NOT NULL DEFAULT 0 COMMENT '状态：0-待审核，1-已通过，2-已隐藏',
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    UNIQUE KEY uk_user_book (user_id, book_id),
+    KEY idx_book_status_created (book_id, status, created_at),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (book_id) REFERENCES books(id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='图书评价表';
+
+CREATE TABLE IF NOT EXISTS review_votes (
+    review_id BIGINT NOT NULL,
+    user_id BIGINT NOT NULL,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (review_id, user_id),
+    FOREIGN KEY (review_id) REFERENCES reviews(id) ON DELETE CASCADE,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='评价有用票表';
+
+ALTER TABLE books ADD INDEX idx_books_status_rating (status, rating_avg);
+
+INSERT IGNORE INTO permissions (code, description) VALUES
+('review:manage', '评价审核');
+
+INSERT IGNORE INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id FROM roles r JOIN permissions p ON p.code = 'review:manage' WHERE r.name = 'admin';
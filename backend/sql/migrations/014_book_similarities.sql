@@ -0,0 +1,14 @@
+-- 014 图书推荐: 共同购买相似度表, 由推荐任务定期全量重建 (也可运行 bookstore-cli rebuild-similarities)
+USE bookstore;
+
+CREATE TABLE IF NOT EXISTS book_similarities (
+    book_id BIGINT NOT NULL,
+    similar_book_id BIGINT NOT NULL,
+    score DOUBLE NOT NULL COMMENT '余弦相似度',
+    co_count INT NOT NULL DEFAULT 0 COMMENT '共同购买人数',
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    PRIMARY KEY (book_id, similar_book_id),
+    KEY idx_book_score (book_id, score),
+    FOREIGN KEY (book_id) REFERENCES books(id) ON DELETE CASCADE,
+    FOREIGN KEY (similar_book_id) REFERENCES books(id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='图书相似度表';
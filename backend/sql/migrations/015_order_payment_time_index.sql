@@ -0,0 +1,5 @@
+-- 015 畅销榜: 按支付时间统计近期销量, 为订单支付时间增加索引 (日榜保存在 Redis, 可运行 bookstore-cli rebuild-rankings 重建)
+USE bookstore;
+
+ALTER TABLE orders
+    ADD INDEX idx_orders_payment_time (payment_time);
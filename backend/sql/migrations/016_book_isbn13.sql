@@ -0,0 +1,7 @@
+-- 016 ISBN 校验与规范化: 新增规范化的 ISBN-13 列及唯一索引
+-- 执行后需运行 bookstore-cli check-isbns 规范化存量 ISBN 并回填 ISBN-13, 同时列出无效和重复的 ISBN
+USE bookstore;
+
+ALTER TABLE books
+    ADD COLUMN isbn13 CHAR(13) DEFAULT NULL COMMENT '规范化的 ISBN-13, ISBN 无效时为空' AFTER isbn,
+    ADD UNIQUE KEY uk_books_isbn13 (isbn13);